@@ -10,7 +10,7 @@ import (
 func main() {
 	ws := &common.FS{}
 	t := time.Now()
-	err := ws.WalkDir("/Users/facundofumaneri/personal", func(path string, size int64) {
+	err := ws.WalkDir("/Users/facundofumaneri/personal", nil, func(path string, size int64) {
 		fmt.Printf("%s %d\n", path, size)
 	})
 	if err != nil {
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
 )
 
@@ -12,8 +11,8 @@ var aiCmd = &cobra.Command{
 	Long: `AI requests management. For example:
 git-cli ai generate -p "Generate a README template"
 git-cli ai summarize -f myfile.go`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Error: must specify an AI action")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify an AI action")
 	},
 }
 
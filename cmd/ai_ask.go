@@ -14,17 +14,29 @@ For example:
 
   ask "What is the capital of France?"
 
-The AI will respond with the appropriate answer.`,
-	Run: AskLlm,
+The AI will respond with the appropriate answer.
+
+With a context loaded by ai load, the answer is based on the chunks of code most
+similar to the question and lists where they come from. --filter limits them to
+the chunks having a metadata value: path (relative to the loaded directory),
+language, symbol (e.g. Store.Get), commit, start_line or end_line.
+
+  ask -n tp1 -q "How are users invited?" --filter language=go --filter path=cmd/calls.go`,
+	RunE: AskLlm,
 }
 
 func init() {
 	askCmd.Flags().StringP("name", "n", "", "Context name")
+	err := askCmd.RegisterFlagCompletionFunc("name", completeContexts)
+	if err != nil {
+		panic(err)
+	}
 
 	askCmd.Flags().StringP("question", "q", "", "Question (required)")
-	err := askCmd.MarkFlagRequired("question")
+	err = askCmd.MarkFlagRequired("question")
 	if err != nil {
 		panic(err)
 	}
+	askCmd.Flags().StringArray("filter", nil, "Use only the chunks with this metadata value, as key=value")
 	aiCmd.AddCommand(askCmd)
 }
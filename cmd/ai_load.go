@@ -10,8 +10,17 @@ For example:
 
   load /path/to/sourcefile.go
 
-The AI will analyze the provided source file and process it as needed.`,
-	Run: LoadSourceCode,
+The AI will analyze the provided source file and process it as needed.
+Loading the same name again only processes the files that are new or changed,
+and forgets the removed ones. Use --full to load every file again, e.g. after
+changing chunk_sizes.
+
+Binary files, files larger than --max-file-size and the ones ignored by
+.gitignore or .github-cli-ignore files are skipped. --include and --exclude
+take globs, matching the file name when they have no slash, e.g.
+
+  load -n tp1 -p ./tp1 --include '*.go' --exclude 'vendor/**'`,
+	RunE: LoadSourceCode,
 }
 
 func init() {
@@ -21,6 +30,10 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	err = loadCmd.RegisterFlagCompletionFunc("name", completeContexts)
+	if err != nil {
+		panic(err)
+	}
 
 	loadCmd.Flags().StringP("path", "p", "", "Path of loading code (required)")
 	err = loadCmd.MarkFlagRequired("path")
@@ -28,5 +41,10 @@ func init() {
 		panic(err)
 	}
 
+	loadCmd.Flags().Bool("full", false, "Drop what was loaded before and load every file again")
+	loadCmd.Flags().StringSlice("include", nil, "Load only the files matching these globs")
+	loadCmd.Flags().StringSlice("exclude", nil, "Skip the files and directories matching these globs")
+	loadCmd.Flags().String("max-file-size", "1MB", "Skip the files larger than this, 0 for no limit")
+
 	aiCmd.AddCommand(loadCmd)
 }
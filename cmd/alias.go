@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// aliasCmd represents the alias command
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Alias management.",
+	Long: `Alias management.
+
+An alias is a name standing for a longer command line, stored in the config file so
+every machine using it gets the same aliases. $1, $2... are replaced by the
+arguments given to the alias, the others are appended. Built-in commands cannot be
+replaced. For example:
+git-cli alias set invite-tp 'repository invite -r tp-$1 -c $2'
+git-cli invite-tp 3 alice`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify an alias action")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// aliasDeleteCmd represents the alias delete command
+var aliasDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete an alias.",
+	Long: `Delete an alias from the config file. For example:
+git-cli alias delete invite-tp
+`,
+	RunE:              DeleteAlias,
+	ValidArgsFunction: completeAliases,
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasDeleteCmd)
+}
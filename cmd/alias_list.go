@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// aliasListCmd represents the alias list command
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the aliases.",
+	Long: `List the aliases with what they expand to, shell aliases start with !. For example:
+git-cli alias list
+`,
+	Args: cobra.NoArgs,
+	RunE: ListAliases,
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasListCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// aliasSetCmd represents the alias set command
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <expansion>",
+	Short: "Create or replace an alias.",
+	Long: `Create or replace an alias. With --shell the expansion is run by sh, getting the
+arguments as $1, $2..., so it can use pipes and other commands. For example:
+git-cli alias set invite-tp 'repository invite -r tp-$1 -c $2'
+git-cli alias set --shell tp-repos 'github-cli repository list | grep tp-'
+`,
+	RunE: SetAlias,
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasSetCmd.Flags().Bool("shell", false, "run the expansion with sh instead of github-cli")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit log of the changes made on GitHub.",
+	Long: `Audit log of the changes made on GitHub.
+
+Every request changing something, like an invitation or a removed collaborator, is
+recorded with its profile, command line, repository, user, HTTP status and error in
+audit.jsonl under the user data directory. Dry runs are not recorded. For example:
+git-cli audit show --since 7d --repo tp1`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify an audit action")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/ffumaneri/github-cli/services"
+	"github.com/spf13/cobra"
+)
+
+// auditShowCmd represents the audit show command
+var auditShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the recorded changes.",
+	Long: `Show the recorded changes, oldest first. For example:
+git-cli audit show
+git-cli audit show --since 2024-03-01 --repo tp1 --action invite
+git-cli audit show --since 24h --output json
+`,
+	Args: cobra.NoArgs,
+	RunE: ShowAudit,
+}
+
+func init() {
+	auditCmd.AddCommand(auditShowCmd)
+	auditShowCmd.Flags().String("since", "", "only changes after a duration ago like 24h or 7d, or after a date like 2024-03-01")
+	auditShowCmd.Flags().StringP("repo", "r", "", "only changes to the repository, with or without the owner")
+	auditShowCmd.Flags().String("action", "", "only changes of the action, e.g. invite, remove-collaborator or cancel-invitation")
+	auditShowCmd.Flags().StringP("output", "o", services.AuditFormatText, "output format: text or json")
+	err := auditShowCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	if err != nil {
+		panic(err)
+	}
+	err = auditShowCmd.RegisterFlagCompletionFunc("action", cobra.FixedCompletions([]string{
+		"invite", "remove-collaborator", "update-invitation", "cancel-invitation", "archive", "unarchive", "update-repo", "delete-repo",
+		"create-repo", "push-file", "label",
+	}, cobra.ShellCompDirectiveNoFileComp))
+	if err != nil {
+		panic(err)
+	}
+	err = auditShowCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{
+		services.AuditFormatText, services.AuditFormatJson,
+	}, cobra.ShellCompDirectiveNoFileComp))
+	if err != nil {
+		panic(err)
+	}
+}
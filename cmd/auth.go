@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Authentication management.",
+	Long: `Authenticate against GitHub using the OAuth device flow. For example:
+git-cli auth login --client-id my-oauth-app-client-id
+git-cli auth status`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify an auth action")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// authLoginCmd represents the auth login command
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to GitHub using the OAuth device flow.",
+	Long: `Log in to GitHub using the OAuth device flow. For example:
+git-cli auth login --client-id my-oauth-app-client-id
+
+The token is stored in a credentials file under the user config directory,
+only readable by the current user. The endpoints can be changed to point to
+a local stand-in of github.com.`,
+	RunE: AuthLogin,
+}
+
+func init() {
+	authCmd.AddCommand(authLoginCmd)
+	authLoginCmd.Flags().String("client-id", os.Getenv("GITHUB_CLI_OAUTH_CLIENT_ID"), "OAuth app client id (defaults to $GITHUB_CLI_OAUTH_CLIENT_ID)")
+	authLoginCmd.Flags().String("device-code-url", "https://github.com/login/device/code", "device code endpoint")
+	authLoginCmd.Flags().String("token-url", "https://github.com/login/oauth/access_token", "access token endpoint")
+	authLoginCmd.Flags().StringSlice("scopes", []string{"repo", "read:org"}, "scopes to request")
+}
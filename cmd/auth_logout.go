@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// authLogoutCmd represents the auth logout command
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored credentials.",
+	Long:  `Remove the credentials stored by auth login.`,
+	RunE:  AuthLogout,
+}
+
+func init() {
+	authCmd.AddCommand(authLogoutCmd)
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// authStatusCmd represents the auth status command
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the logged in user and the token scopes.",
+	Long:  `Show the logged in user and the scopes granted to the stored token.`,
+	RunE:  AuthStatus,
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+}
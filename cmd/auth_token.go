@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// authTokenCmd represents the auth token command
+var authTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print the stored token.",
+	Long: `Print the token stored by auth login. For example:
+GITHUB_TOKEN=$(git-cli auth token) ./my-script.sh`,
+	RunE: AuthToken,
+}
+
+func init() {
+	authCmd.AddCommand(authTokenCmd)
+}
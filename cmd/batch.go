@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Run the operations listed in a batch file.",
+	Long: `Run the operations listed in a YAML batch file, in order, and report how each one
+ended. Steps are invite, remove, create-repo, push-file and label, and create-repo,
+push-file and label do nothing when the change is already there, so a batch can be run
+again after fixing what failed. For example:
+git-cli batch -f semester.yaml
+git-cli batch -f semester.yaml --var course=k3002 --concurrency 4 --dry-run
+
+A batch file looks like:
+
+concurrency: 4            # steps running at the same time, 1 by default
+vars:
+  course: k3001           # used as ${course}, --var overrides it
+steps:
+  - op: create-repo
+    repo: tp1-${course}
+    description: Trabajo practico 1
+    private: true
+  - op: push-file
+    repo: tp1-${course}
+    path: README.md
+    source: templates/README.md   # or content, relative to the batch file
+    message: Add the statement
+    wait: true                    # start once every previous step finished
+  - op: label
+    repo: tp1-${course}
+    label: delivered
+    color: 0e8a16
+  - op: invite
+    repo: tp1-${course}
+    user: alice
+    continue_on_error: true       # a failure does not stop the batch
+  - op: remove
+    repo: tp1-${course}
+    user: bob
+
+A step failing without continue_on_error stops the batch, the steps not started yet are
+skipped.
+`,
+	Args: cobra.NoArgs,
+	RunE: RunBatch,
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringP("file", "f", "", "batch file with the operations to run")
+	batchCmd.Flags().StringArray("var", nil, "variable of the batch file as name=value, can be repeated")
+	batchCmd.Flags().Int("concurrency", 0, "steps running at the same time, overrides the batch file")
+	err := batchCmd.MarkFlagRequired("file")
+	if err != nil {
+		panic(err)
+	}
+	err = batchCmd.MarkFlagFilename("file", "yaml", "yml")
+	if err != nil {
+		panic(err)
+	}
+}
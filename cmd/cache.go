@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Cache management.",
+	Long: `Cache management.
+
+GitHub responses are kept in the user cache directory and revalidated with
+conditional requests, unchanged data answered with 304 Not Modified does not count
+against the rate limit. Use --no-cache to skip the cache for one command, or
+--offline to answer reads only from it, with the date each response was last
+confirmed by GitHub. For example:
+git-cli cache clear`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify a cache action")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove the cached GitHub responses and completion values.",
+	Long: `Remove the cached GitHub responses and completion values. For example:
+git-cli cache clear
+`,
+	Args: cobra.NoArgs,
+	RunE: ClearCache,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
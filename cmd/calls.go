@@ -2,100 +2,466 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/ffumaneri/github-cli/lang_chain"
+	"github.com/ffumaneri/github-cli/services"
+	"github.com/ffumaneri/github-cli/tui"
 	"github.com/spf13/cobra"
-	"log"
+	"os"
+	"strings"
+	"time"
 )
 
-func reportError(format string, args ...any) {
-	fmt.Println("FAIL")
-	log.Fatalf(format, args)
+// usageError reports arguments or flags a command cannot run with.
+func usageError(message string) error {
+	return common.NewError(common.KindValidation, message)
 }
 
-func AskLlm(cmd *cobra.Command, args []string) {
+func AskLlm(cmd *cobra.Command, args []string) error {
 	if len(args) > 2 {
-		reportError("Too many arguments. You can only have one which is the repo name")
+		return usageError("Too many arguments. You can only have one which is the repo name")
 	}
 	contextName, err := cmd.Flags().GetString("name")
 
 	question, err := cmd.Flags().GetString("question")
 	if err != nil || question == "" {
-		reportError("Question argument is required")
+		return usageError("Question argument is required")
 	}
-	ollamaService := appContainer.NewOllamaService()
-	if contextName == "" {
-		err := ollamaService.AskLlm(question)
-		if err != nil {
-			reportError("Error while trying to interact with AI: %s\n", err)
+	values, _ := cmd.Flags().GetStringArray("filter")
+	filters := make(map[string]string, len(values))
+	for _, filter := range values {
+		key, value, found := strings.Cut(filter, "=")
+		if !found || key == "" {
+			return usageError(fmt.Sprintf("invalid filter %q, use key=value", filter))
 		}
+		filters[key] = value
+	}
+	if len(filters) > 0 && contextName == "" {
+		return usageError("Filters need a context, set it with --name")
+	}
+	ollamaService, err := appContainer.NewOllamaService()
+	if err != nil {
+		return err
+	}
+	if contextName == "" {
+		err = ollamaService.AskLlm(question)
 	} else {
-		err := ollamaService.AskLlmWithContext(contextName, question)
-		if err != nil {
-			reportError("Error while trying to interact with AI: %s\n", err)
-		}
+		err = ollamaService.AskLlmWithContext(contextName, question, filters)
 	}
-
+	if err != nil {
+		return fmt.Errorf("Error while trying to interact with AI: %w", err)
+	}
+	return nil
 }
 
-func ListCollaborators(cmd *cobra.Command, args []string) {
+func ListCollaborators(cmd *cobra.Command, args []string) error {
 	if len(args) > 1 {
-		reportError("Too many arguments. You can only have one which is the repo name")
-	} else {
-		repo, err := cmd.Flags().GetString("repo")
-		if err != nil || repo == "" {
-			reportError("repo argument %s\n", err)
-		}
-		ghService := appContainer.NewGithubService()
-		err = ghService.ListCollaboratorsByRepo(repo)
-		if err != nil {
-			reportError("Error while trying to list collaborators: %s\n", err)
-		}
+		return usageError("Too many arguments. You can only have one which is the repo name")
+	}
+	repo, err := cmd.Flags().GetString("repo")
+	if err != nil || repo == "" {
+		return usageError("Repo argument is required")
 	}
+	ghService, err := appContainer.NewGithubService()
+	if err != nil {
+		return err
+	}
+	err = ghService.ListCollaboratorsByRepo(repo)
+	if err != nil {
+		return fmt.Errorf("Error while trying to list collaborators: %w", err)
+	}
+	return nil
 }
 
-func InviteCollaborator(cmd *cobra.Command, args []string) {
+func InviteCollaborator(cmd *cobra.Command, args []string) error {
 	if len(args) > 2 {
-		reportError("Too many arguments.")
-	} else {
-		repo, err := cmd.Flags().GetString("repo")
-		if err != nil || repo == "" {
-			reportError("Repo argument is required")
-		}
-		user, err := cmd.Flags().GetString("collaborator")
-		if err != nil || user == "" {
-			reportError("Collaborator argument is required")
-		}
-		ghService := appContainer.NewGithubService()
-		err = ghService.InviteCollaboratorToRepo(repo, user)
-		if err != nil {
-			reportError("Error while trying to invite collaborator: %s\n", err)
-		}
+		return usageError("Too many arguments.")
+	}
+	repo, err := cmd.Flags().GetString("repo")
+	if err != nil || repo == "" {
+		return usageError("Repo argument is required")
+	}
+	user, err := cmd.Flags().GetString("collaborator")
+	if err != nil || user == "" {
+		return usageError("Collaborator argument is required")
 	}
+	ghService, err := appContainer.NewGithubService()
+	if err != nil {
+		return err
+	}
+	err = ghService.InviteCollaboratorToRepo(repo, user)
+	if err != nil {
+		return fmt.Errorf("Error while trying to invite collaborator: %w", err)
+	}
+	return nil
 }
 
-func ListRepositories(_ *cobra.Command, _ []string) {
-	ghService := appContainer.NewGithubService()
-	err := ghService.ListRepos()
+func ListRepositories(_ *cobra.Command, _ []string) error {
+	ghService, err := appContainer.NewGithubService()
 	if err != nil {
-		reportError("Error while trying to list repositories: %s\n", err)
+		return err
 	}
+	err = ghService.ListRepos()
+	if err != nil {
+		return fmt.Errorf("Error while trying to list repositories: %w", err)
+	}
+	return nil
+}
+
+func RunTui(_ *cobra.Command, _ []string) error {
+	messages := tui.NewMessages()
+	ghService, err := appContainer.NewGithubServiceWithConsumer(messages.Add)
+	if err != nil {
+		return err
+	}
+	err = tui.Run(ghService, messages)
+	if err != nil {
+		return fmt.Errorf("Error while trying to run the terminal UI: %w", err)
+	}
+	return nil
 }
 
-func LoadSourceCode(cmd *cobra.Command, args []string) {
+func LoadSourceCode(cmd *cobra.Command, args []string) error {
 	if len(args) > 2 {
-		reportError("Too many arguments.")
-	} else {
-		name, err := cmd.Flags().GetString("name")
-		if err != nil || name == "" {
-			reportError("Repo argument is required")
+		return usageError("Too many arguments.")
+	}
+	name, err := cmd.Flags().GetString("name")
+	if err != nil || name == "" {
+		return usageError("Repo argument is required")
+	}
+	path, err := cmd.Flags().GetString("path")
+	if err != nil || path == "" {
+		return usageError("Path argument is required")
+	}
+	options := lang_chain.LoadOptions{}
+	if options.Full, err = cmd.Flags().GetBool("full"); err != nil {
+		return err
+	}
+	if options.Include, err = cmd.Flags().GetStringSlice("include"); err != nil {
+		return err
+	}
+	if options.Exclude, err = cmd.Flags().GetStringSlice("exclude"); err != nil {
+		return err
+	}
+	maxFileSize, err := cmd.Flags().GetString("max-file-size")
+	if err != nil {
+		return err
+	}
+	if options.MaxFileSize, err = common.ParseFileSize(maxFileSize); err != nil {
+		return err
+	}
+	oService, err := appContainer.NewOllamaService()
+	if err != nil {
+		return err
+	}
+	err = oService.LoadSourceCode(name, path, options)
+	if err != nil {
+		return fmt.Errorf("Error while trying to load documents: %w", err)
+	}
+	return nil
+}
+
+func AuthLogin(cmd *cobra.Command, _ []string) error {
+	clientId, err := cmd.Flags().GetString("client-id")
+	if err != nil || clientId == "" {
+		return usageError("Client id argument is required")
+	}
+	deviceCodeUrl, _ := cmd.Flags().GetString("device-code-url")
+	tokenUrl, _ := cmd.Flags().GetString("token-url")
+	scopes, _ := cmd.Flags().GetStringSlice("scopes")
+	authService, err := appContainer.NewAuthService()
+	if err != nil {
+		return err
+	}
+	err = authService.Login(services.LoginOptions{
+		ClientId:      clientId,
+		DeviceCodeUrl: deviceCodeUrl,
+		TokenUrl:      tokenUrl,
+		Scopes:        scopes,
+	})
+	if err != nil {
+		return fmt.Errorf("Error while trying to log in: %w", err)
+	}
+	return nil
+}
+
+func AuthStatus(_ *cobra.Command, _ []string) error {
+	authService, err := appContainer.NewAuthService()
+	if err != nil {
+		return err
+	}
+	err = authService.Status()
+	if err != nil {
+		return fmt.Errorf("Error while trying to get auth status: %w", err)
+	}
+	return nil
+}
+
+func AuthLogout(_ *cobra.Command, _ []string) error {
+	authService, err := appContainer.NewAuthService()
+	if err != nil {
+		return err
+	}
+	err = authService.Logout()
+	if err != nil {
+		return fmt.Errorf("Error while trying to log out: %w", err)
+	}
+	return nil
+}
+
+func AuthToken(_ *cobra.Command, _ []string) error {
+	authService, err := appContainer.NewAuthService()
+	if err != nil {
+		return err
+	}
+	err = authService.Token()
+	if err != nil {
+		return fmt.Errorf("Error while trying to get token: %w", err)
+	}
+	return nil
+}
+
+func ListProfiles(_ *cobra.Command, _ []string) error {
+	configService := appContainer.NewConfigService()
+	err := configService.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("Error while trying to list profiles: %w", err)
+	}
+	return nil
+}
+
+func UseProfile(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("Profile name is required")
+	}
+	configService := appContainer.NewConfigService()
+	err := configService.UseProfile(args[0])
+	if err != nil {
+		return fmt.Errorf("Error while trying to switch profile: %w", err)
+	}
+	return nil
+}
+
+func InitConfig(_ *cobra.Command, _ []string) error {
+	configService := appContainer.NewConfigService()
+	err := configService.Init()
+	if err != nil {
+		return fmt.Errorf("Error while trying to initialize the configuration: %w", err)
+	}
+	return nil
+}
+
+func GetConfigValue(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("Key argument is required")
+	}
+	configService := appContainer.NewConfigService()
+	err := configService.Get(args[0])
+	if err != nil {
+		return fmt.Errorf("Error while trying to get configuration value: %w", err)
+	}
+	return nil
+}
+
+func SetConfigValue(_ *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return usageError("Key and value arguments are required")
+	}
+	configService := appContainer.NewConfigService()
+	err := configService.Set(args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("Error while trying to set configuration value: %w", err)
+	}
+	return nil
+}
+
+func UnsetConfigValue(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("Key argument is required")
+	}
+	configService := appContainer.NewConfigService()
+	err := configService.Unset(args[0])
+	if err != nil {
+		return fmt.Errorf("Error while trying to unset configuration value: %w", err)
+	}
+	return nil
+}
+
+func ListConfigValues(_ *cobra.Command, _ []string) error {
+	configService := appContainer.NewConfigService()
+	err := configService.List()
+	if err != nil {
+		return fmt.Errorf("Error while trying to list configuration values: %w", err)
+	}
+	return nil
+}
+
+func ValidateConfig(_ *cobra.Command, _ []string) error {
+	configService := appContainer.NewConfigService()
+	err := configService.Validate()
+	if err != nil {
+		return fmt.Errorf("Error while trying to validate the configuration: %w", err)
+	}
+	return nil
+}
+
+func GenerateCompletion(cmd *cobra.Command, args []string) error {
+	var err error
+	switch args[0] {
+	case "bash":
+		err = cmd.Root().GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		err = cmd.Root().GenZshCompletion(os.Stdout)
+	case "fish":
+		err = cmd.Root().GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		err = cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	if err != nil {
+		return fmt.Errorf("Error while trying to generate the completion script: %w", err)
+	}
+	return nil
+}
+
+func ListPlugins(_ *cobra.Command, _ []string) error {
+	pluginService := appContainer.NewPluginService()
+	err := pluginService.List()
+	if err != nil {
+		return fmt.Errorf("Error while trying to list plugins: %w", err)
+	}
+	return nil
+}
+
+func ShowAudit(cmd *cobra.Command, _ []string) error {
+	var filter common.AuditFilter
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		var err error
+		filter.Since, err = common.ParseSince(since, time.Now())
+		if err != nil {
+			return err
 		}
-		path, err := cmd.Flags().GetString("path")
-		if err != nil || path == "" {
-			reportError("Path argument is required")
+	}
+	filter.Repo, _ = cmd.Flags().GetString("repo")
+	filter.Action, _ = cmd.Flags().GetString("action")
+	output, _ := cmd.Flags().GetString("output")
+	auditService, err := appContainer.NewAuditService()
+	if err != nil {
+		return err
+	}
+	err = auditService.Show(filter, output)
+	if err != nil {
+		return fmt.Errorf("Error while trying to show the audit log: %w", err)
+	}
+	return nil
+}
+
+func ClearCache(_ *cobra.Command, _ []string) error {
+	cacheService, err := appContainer.NewCacheService()
+	if err != nil {
+		return err
+	}
+	err = cacheService.Clear()
+	if err != nil {
+		return fmt.Errorf("Error while trying to clear the cache: %w", err)
+	}
+	return nil
+}
+
+func RunBatch(cmd *cobra.Command, _ []string) error {
+	path, err := cmd.Flags().GetString("file")
+	if err != nil || path == "" {
+		return usageError("Batch file argument is required")
+	}
+	values, _ := cmd.Flags().GetStringArray("var")
+	vars := make(map[string]string, len(values))
+	for _, variable := range values {
+		name, value, found := strings.Cut(variable, "=")
+		if !found || name == "" {
+			return usageError(fmt.Sprintf("invalid variable %q, use name=value", variable))
 		}
-		oService := appContainer.NewOllamaService()
-		err = oService.LoadSourceCode(name, path)
-		if err != nil {
-			reportError("Error while trying to load documents: %s\n", err)
+		vars[name] = value
+	}
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	batchService, err := appContainer.NewBatchService()
+	if err != nil {
+		return err
+	}
+	err = batchService.Run(path, vars, concurrency)
+	if err != nil {
+		return fmt.Errorf("Error while trying to run the batch: %w", err)
+	}
+	return nil
+}
+
+func ListAliases(_ *cobra.Command, _ []string) error {
+	aliasService := appContainer.NewAliasService()
+	err := aliasService.List()
+	if err != nil {
+		return fmt.Errorf("Error while trying to list aliases: %w", err)
+	}
+	return nil
+}
+
+func SetAlias(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return usageError("Name and expansion arguments are required")
+	}
+	if isBuiltinCommand(args[0]) {
+		return usageError(fmt.Sprintf("%s is a built-in command, choose another alias name", args[0]))
+	}
+	shell, _ := cmd.Flags().GetBool("shell")
+	aliasService := appContainer.NewAliasService()
+	err := aliasService.Set(args[0], args[1], shell)
+	if err != nil {
+		return fmt.Errorf("Error while trying to set alias: %w", err)
+	}
+	return nil
+}
+
+func DeleteAlias(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("Name argument is required")
+	}
+	aliasService := appContainer.NewAliasService()
+	err := aliasService.Delete(args[0])
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete alias: %w", err)
+	}
+	return nil
+}
+
+// ExpandAlias replaces the alias named by args, after the global flags, with its
+// expansion. Shell aliases are run instead, done reports the command line was handled.
+func ExpandAlias(args []string) (expanded []string, done bool, err error) {
+	i, ok := externalCommand(args)
+	if !ok {
+		return args, false, nil
+	}
+	aliasService := appContainer.NewAliasService()
+	expansion, err := aliasService.Expand(args[i], args[i+1:])
+	if err != nil {
+		return nil, true, fmt.Errorf("Error while trying to expand alias %s: %w", args[i], err)
+	}
+	if expansion == nil {
+		return args, false, nil
+	}
+	if expansion.Shell {
+		err = aliasService.RunShell(expansion)
+		if err != nil && !isPluginExit(err) {
+			return nil, true, fmt.Errorf("Error while trying to run alias %s: %w", args[i], err)
 		}
+		return nil, true, err
+	}
+	return append(args[:i:i], expansion.Args...), false, nil
+}
+
+// RunPlugin runs the plugin name. When the plugin fails its *exec.ExitError is returned
+// as is, so the CLI exits with the plugin exit code.
+func RunPlugin(name string, args []string) error {
+	pluginService := appContainer.NewPluginService()
+	err := pluginService.Run(name, args)
+	if err != nil && !isPluginExit(err) {
+		return fmt.Errorf("Error while trying to run plugin %s: %w", name, err)
 	}
+	return err
 }
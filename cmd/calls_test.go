@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	github2 "github.com/ffumaneri/github-cli/github"
+	"github.com/ffumaneri/github-cli/lang_chain"
 	"github.com/ffumaneri/github-cli/services"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // MockOllamaService is a mock implementation of the LangChainService
@@ -25,14 +31,18 @@ func (m *MockOllamaService) AskLlm(prompt string) error {
 }
 
 // AskLlm mocks the AskLlm method of the LangChainService
-func (m *MockOllamaService) AskLlmWithContext(contextName, prompt string) error {
-	args := m.Called(contextName, prompt)
+func (m *MockOllamaService) AskLlmWithContext(contextName, prompt string, filters map[string]string) error {
+	args := m.Called(contextName, prompt, filters)
 	return args.Error(0)
 }
-func (m *MockOllamaService) LoadSourceCode(name, directory string) error {
-	args := m.Called(name, directory)
+func (m *MockOllamaService) LoadSourceCode(name, directory string, options lang_chain.LoadOptions) error {
+	args := m.Called(name, directory, options)
 	return args.Error(0)
 }
+func (m *MockOllamaService) ContextNames() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
 
 // MockGithubService is a mock implementation of the GithubServiceInterface
 type MockGithubService struct {
@@ -58,20 +68,278 @@ func (m *MockGithubService) InviteCollaboratorToRepo(repo, user string) error {
 	return args.Error(0)
 }
 
+// RemoveCollaboratorFromRepo mocks the `RemoveCollaboratorFromRepo` method
+func (m *MockGithubService) RemoveCollaboratorFromRepo(repo, user string) error {
+	args := m.Called(repo, user)
+	return args.Error(0)
+}
+
+// InvitationsByRepo mocks the `InvitationsByRepo` method
+func (m *MockGithubService) InvitationsByRepo(repo string) ([]github2.Invitation, error) {
+	args := m.Called(repo)
+	return args.Get(0).([]github2.Invitation), args.Error(1)
+}
+
+// CancelInvitationToRepo mocks the `CancelInvitationToRepo` method
+func (m *MockGithubService) CancelInvitationToRepo(repo string, invitation github2.Invitation) error {
+	args := m.Called(repo, invitation)
+	return args.Error(0)
+}
+
+// RepoNames mocks the `RepoNames` method
+func (m *MockGithubService) RepoNames() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// CollaboratorNames mocks the `CollaboratorNames` method
+func (m *MockGithubService) CollaboratorNames(repo string) ([]string, error) {
+	args := m.Called(repo)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGithubService) CreateRepo(repo, description string, private bool) error {
+	args := m.Called(repo, description, private)
+	return args.Error(0)
+}
+
+func (m *MockGithubService) PushFile(repo, path string, file github2.FileChange) error {
+	args := m.Called(repo, path, file)
+	return args.Error(0)
+}
+
+func (m *MockGithubService) SaveLabel(repo string, label github2.Label) error {
+	args := m.Called(repo, label)
+	return args.Error(0)
+}
+
+// MockAuthService is a mock implementation of the AuthService
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) Login(options services.LoginOptions) error {
+	args := m.Called(options)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) Status() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockAuthService) Logout() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockAuthService) Token() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// MockConfigService is a mock implementation of the ConfigService
+type MockConfigService struct {
+	mock.Mock
+}
+
+func (m *MockConfigService) ListProfiles() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockConfigService) ActiveProfile() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockConfigService) UseProfile(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) Init() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockConfigService) Get(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) Set(key, value string) error {
+	args := m.Called(key, value)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) Unset(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) List() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockConfigService) Validate() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockPluginService struct {
+	mock.Mock
+}
+
+func (m *MockPluginService) List() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockPluginService) Run(name string, pluginArgs []string) error {
+	args := m.Called(name, pluginArgs)
+	return args.Error(0)
+}
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) Show(filter common.AuditFilter, format string) error {
+	args := m.Called(filter, format)
+	return args.Error(0)
+}
+
+type MockCacheService struct {
+	mock.Mock
+}
+
+func (m *MockCacheService) Clear() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockBatchService struct {
+	mock.Mock
+}
+
+func (m *MockBatchService) Run(path string, vars map[string]string, concurrency int) error {
+	args := m.Called(path, vars, concurrency)
+	return args.Error(0)
+}
+
+type MockAliasService struct {
+	mock.Mock
+}
+
+func (m *MockAliasService) List() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockAliasService) Set(name, expansion string, shell bool) error {
+	args := m.Called(name, expansion, shell)
+	return args.Error(0)
+}
+
+func (m *MockAliasService) Delete(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockAliasService) Names() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAliasService) Expand(name string, aliasArgs []string) (*services.AliasExpansion, error) {
+	args := m.Called(name, aliasArgs)
+	return args.Get(0).(*services.AliasExpansion), args.Error(1)
+}
+
+func (m *MockAliasService) RunShell(expansion *services.AliasExpansion) error {
+	args := m.Called(expansion)
+	return args.Error(0)
+}
+
 type MockContainer struct {
 	mock.Mock
 	mockGitHubService services.IGithubService
 	mockOllamaServie  services.ILangChainService
+	mockAuthService   services.IAuthService
+	mockConfigService services.IConfigService
+	mockPluginService services.IPluginService
+	mockAuditService  services.IAuditService
+	mockCacheService  services.ICacheService
+	mockAliasService  services.IAliasService
+	mockBatchService  services.IBatchService
+	contextNames      []string
 }
 
 // NewGithubService returns a mocked GithubService.
-func (m *MockContainer) NewGithubService() services.IGithubService {
-	return m.mockGitHubService
+func (m *MockContainer) NewGithubService() (services.IGithubService, error) {
+	if m.mockGitHubService == nil {
+		return nil, &common.MissingKeyError{Key: "owner", Section: "GitHub commands"}
+	}
+	return m.mockGitHubService, nil
+}
+
+// NewGithubServiceWithConsumer returns the mocked GithubService, its messages are not consumed.
+func (m *MockContainer) NewGithubServiceWithConsumer(_ func(data string)) (services.IGithubService, error) {
+	return m.NewGithubService()
 }
 
 // NewOllamaService returns a mocked LangChainService.
-func (m *MockContainer) NewOllamaService() services.ILangChainService {
-	return m.mockOllamaServie
+func (m *MockContainer) NewOllamaService() (services.ILangChainService, error) {
+	if m.mockOllamaServie == nil {
+		return nil, &common.MissingKeyError{Key: "ollama_model", Section: "AI commands"}
+	}
+	return m.mockOllamaServie, nil
+}
+
+// NewAuthService returns a mocked AuthService.
+func (m *MockContainer) NewAuthService() (services.IAuthService, error) {
+	return m.mockAuthService, nil
+}
+
+// NewConfigService returns a mocked ConfigService.
+func (m *MockContainer) NewConfigService() services.IConfigService {
+	return m.mockConfigService
+}
+
+// NewPluginService returns a mocked PluginService.
+func (m *MockContainer) NewPluginService() services.IPluginService {
+	return m.mockPluginService
+}
+
+// NewAuditService returns a mocked AuditService.
+func (m *MockContainer) NewAuditService() (services.IAuditService, error) {
+	return m.mockAuditService, nil
+}
+
+// NewCacheService returns a mocked CacheService.
+func (m *MockContainer) NewCacheService() (services.ICacheService, error) {
+	return m.mockCacheService, nil
+}
+
+// NewAliasService returns a mocked AliasService.
+func (m *MockContainer) NewAliasService() services.IAliasService {
+	return m.mockAliasService
+}
+
+// ContextNames returns the mocked contexts, there are none when not set.
+func (m *MockContainer) ContextNames() ([]string, error) {
+	return m.contextNames, nil
+}
+
+// NewBatchService returns a mocked BatchService.
+func (m *MockContainer) NewBatchService() (services.IBatchService, error) {
+	return m.mockBatchService, nil
+}
+
+func (m *MockContainer) Close() error {
+	return nil
 }
 
 func RunForkTest(_ *testing.T, testName string) (string, string, error) {
@@ -116,7 +384,7 @@ func TestAskLlm_SuccessWithContext(t *testing.T) {
 	args := []string{}
 
 	mockOllamaService := new(MockOllamaService)
-	mockOllamaService.On("AskLlmWithContext", "test-context", "test question").Return(nil)
+	mockOllamaService.On("AskLlmWithContext", "test-context", "test question", map[string]string{}).Return(nil)
 
 	// Inject the mock service into the app container
 	appContainer = &MockContainer{mockOllamaServie: mockOllamaService}
@@ -128,41 +396,61 @@ func TestAskLlm_SuccessWithContext(t *testing.T) {
 
 	// Since the function doesn't print anything on success, output should be empty
 	assert.Empty(t, output, "Expected no output on success")
-	mockOllamaService.AssertCalled(t, "AskLlmWithContext", "test-context", "test question")
+	mockOllamaService.AssertCalled(t, "AskLlmWithContext", "test-context", "test question", map[string]string{})
 }
 
-func TestAskLlm_TooManyArguments(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
+func TestAskLlm_Filters(t *testing.T) {
+	newCmd := func(name string, filters ...string) *cobra.Command {
 		cmd := &cobra.Command{}
-		cmd.Flags().String("name", "", "Context name")
+		cmd.Flags().String("name", name, "Context name")
 		cmd.Flags().String("question", "test question", "Question")
-		args := []string{"arg1", "arg2"} // Too many arguments
-		AskLlm(cmd, args)
+		cmd.Flags().StringArray("filter", nil, "Filter")
+		for _, filter := range filters {
+			assert.NoError(t, cmd.Flags().Set("filter", filter))
+		}
+		return cmd
 	}
+	mockOllamaService := new(MockOllamaService)
+	filters := map[string]string{"language": "go", "path": "cmd/calls.go"}
+	mockOllamaService.On("AskLlmWithContext", "test-context", "test question", filters).Return(nil)
+	appContainer = &MockContainer{mockOllamaServie: mockOllamaService}
 
-	stdout, stderr, err := RunForkTest(t, "TestAskLlm_TooManyArguments")
+	assert.NoError(t, AskLlm(newCmd("test-context", "language=go", "path=cmd/calls.go"), nil))
+	mockOllamaService.AssertCalled(t, "AskLlmWithContext", "test-context", "test question", filters)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 2")
-	assert.Contains(t, stderr, "panic")
-	assert.Contains(t, stdout, "FAIL")
+	err := AskLlm(newCmd("test-context", "language"), nil)
+	assert.ErrorContains(t, err, `invalid filter "language", use key=value`)
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+
+	err = AskLlm(newCmd("test-context", "=go"), nil)
+	assert.ErrorContains(t, err, `invalid filter "=go", use key=value`)
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+
+	err = AskLlm(newCmd("", "language=go"), nil)
+	assert.ErrorContains(t, err, "Filters need a context")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 }
 
-func TestAskLlm_MissingQuestionFlag(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("name", "", "Context name")
-		cmd.Flags().String("question", "", "Question") // Empty question
-		args := []string{}
-		AskLlm(cmd, args)
-	}
+func TestAskLlm_TooManyArguments(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("name", "", "Context name")
+	cmd.Flags().String("question", "test question", "Question")
+	args := []string{"arg1", "arg2", "arg3"} // Too many arguments
+	err := AskLlm(cmd, args)
 
-	stdout, stderr, err := RunForkTest(t, "TestAskLlm_MissingQuestionFlag")
+	assert.ErrorContains(t, err, "Too many arguments")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+}
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Question argument is required")
-	assert.Contains(t, stdout, "FAIL")
+func TestAskLlm_MissingQuestionFlag(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("name", "", "Context name")
+	cmd.Flags().String("question", "", "Question") // Empty question
+	args := []string{}
+	err := AskLlm(cmd, args)
+
+	assert.ErrorContains(t, err, "Question argument is required")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 }
 
 func TestAskLlm_MissingContextFlag(t *testing.T) {
@@ -188,47 +476,35 @@ func TestAskLlm_MissingContextFlag(t *testing.T) {
 }
 
 func TestAskLlm_WithErrorFromServiceWithoutContext(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("name", "", "Context name") // No context
-		cmd.Flags().String("question", "test question", "Question")
-		args := []string{}
-
-		mockOllamaService := new(MockOllamaService)
-		mockOllamaService.On("AskLlm", "test question").Return(errors.New("mock error"))
-		appContainer = &MockContainer{mockOllamaServie: mockOllamaService}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("name", "", "Context name") // No context
+	cmd.Flags().String("question", "test question", "Question")
+	args := []string{}
 
-		AskLlm(cmd, args)
-	}
+	mockOllamaService := new(MockOllamaService)
+	mockOllamaService.On("AskLlm", "test question").Return(errors.New("mock error"))
+	appContainer = &MockContainer{mockOllamaServie: mockOllamaService}
 
-	stdout, stderr, err := RunForkTest(t, "TestAskLlm_WithErrorFromServiceWithoutContext")
+	err := AskLlm(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Error while trying to interact with AI")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Error while trying to interact with AI")
+	assert.Equal(t, common.ExitError, common.ExitCode(err))
 }
 
 func TestAskLlm_WithErrorFromServiceWithContext(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("name", "test-context", "Context name")
-		cmd.Flags().String("question", "test question", "Question")
-		args := []string{}
-
-		mockOllamaService := new(MockOllamaService)
-		mockOllamaService.On("AskLlmWithContext", "test-context", "test question").Return(errors.New("mock error"))
-		appContainer = &MockContainer{mockOllamaServie: mockOllamaService}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("name", "test-context", "Context name")
+	cmd.Flags().String("question", "test question", "Question")
+	args := []string{}
 
-		AskLlm(cmd, args)
-	}
+	mockOllamaService := new(MockOllamaService)
+	mockOllamaService.On("AskLlmWithContext", "test-context", "test question", map[string]string{}).Return(errors.New("mock error"))
+	appContainer = &MockContainer{mockOllamaServie: mockOllamaService}
 
-	stdout, stderr, err := RunForkTest(t, "TestAskLlm_WithErrorFromServiceWithContext")
+	err := AskLlm(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Error while trying to interact with AI")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Error while trying to interact with AI")
+	assert.Equal(t, common.ExitError, common.ExitCode(err))
 }
 func TestListCollaborators_Success(t *testing.T) {
 	cmd := &cobra.Command{}
@@ -252,56 +528,41 @@ func TestListCollaborators_Success(t *testing.T) {
 }
 
 func TestListCollaborators_TooManyArguments(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		args := []string{"arg1", "arg2"} // Too many arguments
-		ListCollaborators(cmd, args)
-	}
-	stdout, stderr, err := RunForkTest(t, "TestListCollaborators_TooManyArguments")
+	cmd := &cobra.Command{}
+	args := []string{"arg1", "arg2"} // Too many arguments
+	err := ListCollaborators(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Too many arguments. You can only have one which is the repo name")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Too many arguments. You can only have one which is the repo name")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 }
 
 func TestListCollaborators_RepoFlagEmpty(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("repo", "", "Name of the repository") // Empty repo flag
-		args := []string{}                                       // No additional arguments passed
+	cmd := &cobra.Command{}
+	cmd.Flags().String("repo", "", "Name of the repository") // Empty repo flag
+	args := []string{}                                       // No additional arguments passed
 
-		// Mock service never gets invoked
-		mockGithubService := new(MockGithubService)
-		appContainer = &MockContainer{mockGitHubService: mockGithubService}
-		ListCollaborators(cmd, args)
-	}
-	stdout, stderr, err := RunForkTest(t, "TestListCollaborators_RepoFlagEmpty")
+	// Mock service never gets invoked
+	mockGithubService := new(MockGithubService)
+	appContainer = &MockContainer{mockGitHubService: mockGithubService}
+	err := ListCollaborators(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "repo argument")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Repo argument is required")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 }
 
 func TestListCollaborators_WithError(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("repo", "test-repo", "Name of the repository") // Set the repo flag
-		args := []string{}                                                // No additional arguments passed
+	cmd := &cobra.Command{}
+	cmd.Flags().String("repo", "test-repo", "Name of the repository") // Set the repo flag
+	args := []string{}                                                // No additional arguments passed
 
-		// Mock service never gets invoked
-		mockGithubService := new(MockGithubService)
-		mockGithubService.On("ListCollaboratorsByRepo", "test-repo").Return(errors.New("error while listing collaborators"))
-		appContainer = &MockContainer{mockGitHubService: mockGithubService}
-		ListCollaborators(cmd, args)
-	}
-	stdout, stderr, err := RunForkTest(t, "TestListCollaborators_WithError")
+	// Mock service never gets invoked
+	mockGithubService := new(MockGithubService)
+	mockGithubService.On("ListCollaboratorsByRepo", "test-repo").Return(errors.New("error while listing collaborators"))
+	appContainer = &MockContainer{mockGitHubService: mockGithubService}
+	err := ListCollaborators(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Error while trying to list collaborators")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Error while trying to list collaborators")
+	assert.Equal(t, common.ExitError, common.ExitCode(err))
 }
 func TestInviteCollaborator_Success(t *testing.T) {
 	cmd := &cobra.Command{}
@@ -326,80 +587,55 @@ func TestInviteCollaborator_Success(t *testing.T) {
 }
 
 func TestInviteCollaborator_WithError(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("repo", "test-repo", "Repository name")
-		cmd.Flags().String("collaborator", "test-user", "Collaborator username")
-		args := []string{} // No additional arguments passed
-
-		mockGithubService := new(MockGithubService)
-		mockGithubService.On("InviteCollaboratorToRepo", "test-repo", "test-user").Return(fmt.Errorf("error"))
+	cmd := &cobra.Command{}
+	cmd.Flags().String("repo", "test-repo", "Repository name")
+	cmd.Flags().String("collaborator", "test-user", "Collaborator username")
+	args := []string{} // No additional arguments passed
 
-		// Inject the mock service into the app container
-		appContainer = &MockContainer{mockGitHubService: mockGithubService}
+	mockGithubService := new(MockGithubService)
+	mockGithubService.On("InviteCollaboratorToRepo", "test-repo", "test-user").Return(fmt.Errorf("error"))
 
-		InviteCollaborator(cmd, args)
-	}
+	// Inject the mock service into the app container
+	appContainer = &MockContainer{mockGitHubService: mockGithubService}
 
-	stdout, stderr, err := RunForkTest(t, "TestInviteCollaborator_WithError")
+	err := InviteCollaborator(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Error while trying to invite collaborator")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Error while trying to invite collaborator")
+	assert.Equal(t, common.ExitError, common.ExitCode(err))
 }
 func TestInviteCollaborator_TooManyArguments(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		args := []string{"arg1", "arg2", "arg3"} // Too many arguments
-
-		// Capture the output
-		InviteCollaborator(cmd, args)
-	}
-
-	stdout, stderr, err := RunForkTest(t, "TestInviteCollaborator_TooManyArguments")
+	cmd := &cobra.Command{}
+	args := []string{"arg1", "arg2", "arg3"} // Too many arguments
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Too many arguments")
-	assert.Contains(t, stdout, "FAIL")
+	// Capture the output
+	err := InviteCollaborator(cmd, args)
 
+	assert.ErrorContains(t, err, "Too many arguments")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 }
 
 func TestInviteCollaborator_MissingRepoFlag(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("repo", "", "Repository name") // Empty repo flag
-		cmd.Flags().String("collaborator", "test-user", "Collaborator username")
-		args := []string{} // No additional arguments
-
-		InviteCollaborator(cmd, args)
-	}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("repo", "", "Repository name") // Empty repo flag
+	cmd.Flags().String("collaborator", "test-user", "Collaborator username")
+	args := []string{} // No additional arguments
 
-	stdout, stderr, err := RunForkTest(t, "TestInviteCollaborator_MissingRepoFlag")
+	err := InviteCollaborator(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Repo argument is required")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Repo argument is required")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 }
 
 func TestInviteCollaborator_MissingCollaboratorFlag(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
-		cmd := &cobra.Command{}
-		cmd.Flags().String("repo", "test-repo", "Repository name")
-		cmd.Flags().String("collaborator", "", "Collaborator username") // Empty collaborator flag
-		args := []string{}                                              // No additional arguments
-
-		InviteCollaborator(cmd, args)
-	}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("repo", "test-repo", "Repository name")
+	cmd.Flags().String("collaborator", "", "Collaborator username") // Empty collaborator flag
+	args := []string{}                                              // No additional arguments
 
-	stdout, stderr, err := RunForkTest(t, "TestInviteCollaborator_MissingCollaboratorFlag")
+	err := InviteCollaborator(cmd, args)
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Collaborator argument is required")
-	assert.Contains(t, stdout, "FAIL")
+	assert.ErrorContains(t, err, "Collaborator argument is required")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 }
 
 func TestListRepositories_Success(t *testing.T) {
@@ -423,27 +659,447 @@ func TestListRepositories_Success(t *testing.T) {
 }
 
 func TestListRepositories_WithError(t *testing.T) {
-	if os.Getenv("FORK") == "1" {
+	cmd := &cobra.Command{}
+	args := []string{} // No arguments expected
+
+	mockGithubService := new(MockGithubService)
+	mockGithubService.On("ListRepos").Return(fmt.Errorf("error"))
+
+	// Inject the mock service into the app container
+	appContainer = &MockContainer{mockGitHubService: mockGithubService}
+
+	err := ListRepositories(cmd, args)
+
+	assert.ErrorContains(t, err, "Error while trying to list repositories")
+	assert.Equal(t, common.ExitError, common.ExitCode(err))
+}
+
+func TestListRepositories_MissingConfig(t *testing.T) {
+	// The container cannot create the service without configuration
+	appContainer = &MockContainer{}
+
+	err := ListRepositories(&cobra.Command{}, []string{})
+
+	assert.ErrorContains(t, err, "missing required configuration value owner")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+}
+
+func TestAuthLogin_Success(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("client-id", "client-id", "")
+	cmd.Flags().String("device-code-url", "http://localhost/device/code", "")
+	cmd.Flags().String("token-url", "http://localhost/oauth/access_token", "")
+	cmd.Flags().StringSlice("scopes", []string{"repo"}, "")
+
+	mockAuthService := new(MockAuthService)
+	mockAuthService.On("Login", services.LoginOptions{
+		ClientId:      "client-id",
+		DeviceCodeUrl: "http://localhost/device/code",
+		TokenUrl:      "http://localhost/oauth/access_token",
+		Scopes:        []string{"repo"},
+	}).Return(nil)
+	appContainer = &MockContainer{mockAuthService: mockAuthService}
+
+	output := captureOutput(func() {
+		AuthLogin(cmd, []string{})
+	})
+
+	assert.Empty(t, output, "Expected no output on success")
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestAuthLogin_MissingClientId(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("client-id", "", "")
+	err := AuthLogin(cmd, []string{})
+
+	assert.ErrorContains(t, err, "Client id argument is required")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+}
+
+func TestAuthStatus_NotLoggedIn(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	mockAuthService.On("Status").Return(services.ErrNotLoggedIn)
+	appContainer = &MockContainer{mockAuthService: mockAuthService}
+	err := AuthStatus(&cobra.Command{}, []string{})
+
+	assert.ErrorContains(t, err, "Error while trying to get auth status")
+	assert.Equal(t, common.ExitAuth, common.ExitCode(err))
+}
+
+func TestAuthLogoutAndToken_Success(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	mockAuthService.On("Logout").Return(nil)
+	mockAuthService.On("Token").Return(nil)
+	appContainer = &MockContainer{mockAuthService: mockAuthService}
+
+	output := captureOutput(func() {
+		AuthToken(&cobra.Command{}, []string{})
+		AuthLogout(&cobra.Command{}, []string{})
+	})
+
+	assert.Empty(t, output, "Expected no output on success")
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestUseProfile_Success(t *testing.T) {
+	mockConfigService := new(MockConfigService)
+	mockConfigService.On("UseProfile", "work").Return(nil)
+	appContainer = &MockContainer{mockConfigService: mockConfigService}
+
+	output := captureOutput(func() {
+		UseProfile(&cobra.Command{}, []string{"work"})
+	})
+
+	assert.Empty(t, output, "Expected no output on success")
+	mockConfigService.AssertCalled(t, "UseProfile", "work")
+}
+
+func TestUseProfile_WithError(t *testing.T) {
+	mockConfigService := new(MockConfigService)
+	mockConfigService.On("UseProfile", "personal").Return(errors.New("profile \"personal\" is not defined"))
+	appContainer = &MockContainer{mockConfigService: mockConfigService}
+	err := UseProfile(&cobra.Command{}, []string{"personal"})
+
+	assert.ErrorContains(t, err, "Error while trying to switch profile")
+	assert.Equal(t, common.ExitError, common.ExitCode(err))
+}
+
+func TestListProfiles_Success(t *testing.T) {
+	mockConfigService := new(MockConfigService)
+	mockConfigService.On("ListProfiles").Return(nil)
+	appContainer = &MockContainer{mockConfigService: mockConfigService}
+
+	output := captureOutput(func() {
+		ListProfiles(&cobra.Command{}, []string{})
+	})
+
+	assert.Empty(t, output, "Expected no output on success")
+	mockConfigService.AssertCalled(t, "ListProfiles")
+}
+
+func TestConfigValues_Success(t *testing.T) {
+	mockConfigService := new(MockConfigService)
+	mockConfigService.On("Init").Return(nil)
+	mockConfigService.On("Get", "owner").Return(nil)
+	mockConfigService.On("Set", "owner", "acme").Return(nil)
+	mockConfigService.On("Unset", "owner").Return(nil)
+	mockConfigService.On("List").Return(nil)
+	mockConfigService.On("Validate").Return(nil)
+	appContainer = &MockContainer{mockConfigService: mockConfigService}
+
+	output := captureOutput(func() {
+		InitConfig(&cobra.Command{}, []string{})
+		GetConfigValue(&cobra.Command{}, []string{"owner"})
+		SetConfigValue(&cobra.Command{}, []string{"owner", "acme"})
+		UnsetConfigValue(&cobra.Command{}, []string{"owner"})
+		ListConfigValues(&cobra.Command{}, []string{})
+		ValidateConfig(&cobra.Command{}, []string{})
+	})
+
+	assert.Empty(t, output, "Expected no output on success")
+	mockConfigService.AssertExpectations(t)
+}
+
+func TestValidateConfig_Invalid(t *testing.T) {
+	mockConfigService := new(MockConfigService)
+	mockConfigService.On("Validate").Return(services.ErrInvalidConfig)
+	appContainer = &MockContainer{mockConfigService: mockConfigService}
+	err := ValidateConfig(&cobra.Command{}, []string{})
+
+	assert.ErrorContains(t, err, "configuration is not valid")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+}
+
+func TestListPlugins_Success(t *testing.T) {
+	mockPluginService := new(MockPluginService)
+	mockPluginService.On("List").Return(nil)
+	appContainer = &MockContainer{mockPluginService: mockPluginService}
+
+	ListPlugins(&cobra.Command{}, []string{})
+
+	mockPluginService.AssertExpectations(t)
+}
+
+func TestRunPlugin_WithError(t *testing.T) {
+	mockPluginService := new(MockPluginService)
+	mockPluginService.On("Run", "grades", []string{"--course", "k3001"}).Return(errors.New("no github-cli-grades plugin on PATH"))
+	appContainer = &MockContainer{mockPluginService: mockPluginService}
+	err := RunPlugin("grades", []string{"--course", "k3001"})
+
+	assert.ErrorContains(t, err, "Error while trying to run plugin")
+	assert.Equal(t, common.ExitError, common.ExitCode(err))
+}
+
+func TestShowAudit(t *testing.T) {
+	tests := []struct {
+		name   string
+		flags  map[string]string
+		filter common.AuditFilter
+		format string
+	}{
+		{"No filters", map[string]string{}, common.AuditFilter{}, "text"},
+		{"Repo and action", map[string]string{"repo": "tp1", "action": "invite"}, common.AuditFilter{Repo: "tp1", Action: "invite"}, "json"},
+		{"Since date", map[string]string{"since": "2024-03-01"}, common.AuditFilter{Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)}, "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuditService := new(MockAuditService)
+			mockAuditService.On("Show", tt.filter, tt.format).Return(nil)
+			appContainer = &MockContainer{mockAuditService: mockAuditService}
+			cmd := &cobra.Command{}
+			cmd.Flags().String("since", tt.flags["since"], "Since")
+			cmd.Flags().String("repo", tt.flags["repo"], "Repository")
+			cmd.Flags().String("action", tt.flags["action"], "Action")
+			cmd.Flags().String("output", tt.format, "Output")
+
+			assert.NoError(t, ShowAudit(cmd, []string{}))
+			mockAuditService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShowAudit_InvalidSince(t *testing.T) {
+	appContainer = &MockContainer{mockAuditService: new(MockAuditService)}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("since", "yesterday", "")
+
+	err := ShowAudit(cmd, []string{})
+
+	assert.ErrorContains(t, err, `invalid since "yesterday"`)
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+}
+
+func TestClearCache(t *testing.T) {
+	mockCacheService := new(MockCacheService)
+	mockCacheService.On("Clear").Return(errors.New("permission denied"))
+	appContainer = &MockContainer{mockCacheService: mockCacheService}
+
+	err := ClearCache(&cobra.Command{}, []string{})
+
+	assert.ErrorContains(t, err, "Error while trying to clear the cache: permission denied")
+	mockCacheService.AssertExpectations(t)
+}
+
+func TestRunBatch(t *testing.T) {
+	mockBatchService := new(MockBatchService)
+	mockBatchService.On("Run", "ops.yaml", map[string]string{"course": "k3001", "tp": "a=b"}, 4).Return(errors.New("1 of 200 steps failed"))
+	appContainer = &MockContainer{mockBatchService: mockBatchService}
+	newCmd := func(args ...string) *cobra.Command {
 		cmd := &cobra.Command{}
-		args := []string{} // No arguments expected
+		cmd.Flags().StringP("file", "f", "", "File")
+		cmd.Flags().StringArray("var", nil, "Variables")
+		cmd.Flags().Int("concurrency", 0, "Concurrency")
+		assert.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
 
-		mockGithubService := new(MockGithubService)
-		mockGithubService.On("ListRepos").Return(fmt.Errorf("error"))
+	err := RunBatch(newCmd(), []string{})
+	assert.ErrorContains(t, err, "Batch file argument is required")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
 
-		// Inject the mock service into the app container
-		appContainer = &MockContainer{mockGitHubService: mockGithubService}
+	err = RunBatch(newCmd("-f", "ops.yaml", "--var", "course"), []string{})
+	assert.ErrorContains(t, err, `invalid variable "course", use name=value`)
 
-		ListRepositories(cmd, args)
+	err = RunBatch(newCmd("-f", "ops.yaml", "--var", "=k3001"), []string{})
+	assert.ErrorContains(t, err, `invalid variable "=k3001", use name=value`)
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+
+	err = RunBatch(newCmd("-f", "ops.yaml", "--var", "course=k3001", "--var", "tp=a=b", "--concurrency", "4"), []string{})
+	assert.ErrorContains(t, err, "Error while trying to run the batch: 1 of 200 steps failed")
+	mockBatchService.AssertExpectations(t)
+}
+
+func TestOfflineDryRun(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	defer rootCmd.SetOut(nil)
+	defer rootCmd.SetErr(nil)
+	defer func() {
+		common.Offline, common.DryRun = false, false
+		rootCmd.PersistentFlags().Lookup("offline").Changed = false
+		rootCmd.PersistentFlags().Lookup("dry-run").Changed = false
+	}()
+	appContainer = &MockContainer{}
+
+	err := runArgs([]string{"repository", "list", "--offline", "--dry-run"})
+	assert.ErrorContains(t, err, "none of the others can be")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+}
+
+func TestGroupCommands_WithoutAction(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	defer rootCmd.SetOut(nil)
+	defer rootCmd.SetErr(nil)
+	for _, group := range []string{"ai", "alias", "audit", "auth", "cache", "collaborator", "config", "plugin", "repository"} {
+		err := runArgs([]string{group})
+		assert.ErrorContains(t, err, "must specify", group)
+		assert.Equal(t, common.ExitValidation, common.ExitCode(err), group)
 	}
+}
+
+func TestSetAlias(t *testing.T) {
+	mockAliasService := new(MockAliasService)
+	mockAliasService.On("Set", "tps", "repository list | grep tp-", true).Return(nil)
+	appContainer = &MockContainer{mockAliasService: mockAliasService}
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("shell", true, "Shell")
+
+	assert.NoError(t, SetAlias(cmd, []string{"tps", "repository list | grep tp-"}))
+	mockAliasService.AssertExpectations(t)
 
-	stdout, stderr, err := RunForkTest(t, "TestListRepositories_WithError")
+	err := SetAlias(cmd, []string{"repository", "collaborator list"})
+	assert.ErrorContains(t, err, "repository is a built-in command")
+	assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+}
+
+func TestExpandAlias(t *testing.T) {
+	defer func() { common.SelectedProfile = "" }()
+	shell := &services.AliasExpansion{Args: []string{"3"}, Shell: true, Script: "grades.sh $1", Name: "grades"}
+	mockAliasService := new(MockAliasService)
+	mockAliasService.On("Expand", "invite-tp", []string{"3", "alice"}).Return(&services.AliasExpansion{
+		Args: []string{"repository", "invite", "-r", "tp-3", "-c", "alice"}, Name: "invite-tp"}, nil)
+	mockAliasService.On("Expand", "grades", []string{"3"}).Return(shell, nil)
+	mockAliasService.On("Expand", "nosuch", []string{}).Return((*services.AliasExpansion)(nil), nil)
+	mockAliasService.On("RunShell", shell).Return(nil)
+	appContainer = &MockContainer{mockAliasService: mockAliasService}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		done     bool
+	}{
+		{"Built-in command", []string{"repository", "list"}, []string{"repository", "list"}, false},
+		{"Alias after global flags", []string{"--profile", "utn", "invite-tp", "3", "alice"}, []string{"--profile", "utn", "repository", "invite", "-r", "tp-3", "-c", "alice"}, false},
+		{"Shell alias", []string{"grades", "3"}, nil, true},
+		{"Unknown command", []string{"nosuch"}, []string{"nosuch"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, done, err := ExpandAlias(tt.args)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.done, done)
+			assert.Equal(t, tt.expected, expanded)
+		})
+	}
+	// The global flags before the alias choose the config it is read from
+	assert.Equal(t, "utn", common.SelectedProfile)
+	mockAliasService.AssertExpectations(t)
+}
 
-	assert.NotNil(t, err, "Expected error not found.")
-	assert.Equal(t, err.Error(), "exit status 1")
-	assert.Contains(t, stderr, "Error while trying to list repositories")
+func TestExecute_NotFound(t *testing.T) {
+	if os.Getenv("FORK") == "1" {
+		mockGithubService := new(MockGithubService)
+		cause := errors.New("GET https://api.github.com/repos/utn/tp9/collaborators: 404 Not Found []")
+		mockGithubService.On("ListCollaboratorsByRepo", "tp9").Return(common.WrapError(common.KindNotFound, cause, "repository utn/tp9 not found"))
+		os.Args = []string{"github-cli", "collaborator", "list", "-r", "tp9", "--verbose"}
+		Execute(&MockContainer{mockGitHubService: mockGithubService})
+	}
+
+	stdout, stderr, err := RunForkTest(t, "TestExecute_NotFound")
+
+	assert.EqualError(t, err, "exit status 4")
+	assert.Contains(t, stderr, "Error: Error while trying to list collaborators: repository utn/tp9 not found\n"+
+		"  caused by: GET https://api.github.com/repos/utn/tp9/collaborators: 404 Not Found []\n")
 	assert.Contains(t, stdout, "FAIL")
 }
 
+func TestExecute_UsageError(t *testing.T) {
+	if os.Getenv("FORK") == "1" {
+		os.Args = []string{"github-cli", "collaborator", "list"}
+		Execute(&MockContainer{})
+	}
+
+	_, stderr, err := RunForkTest(t, "TestExecute_UsageError")
+
+	assert.EqualError(t, err, "exit status 2")
+	assert.Contains(t, stderr, `Error: required flag(s) "repo" not set`)
+	assert.Contains(t, stderr, "Usage:")
+}
+
+func TestPluginCommand(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "github-cli-grades"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", dir)
+	defer func() { common.SelectedProfile = "" }()
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedName string
+		expectedArgs []string
+		expectedOk   bool
+	}{
+		{"Plugin", []string{"grades", "--course", "k3001"}, "grades", []string{"--course", "k3001"}, true},
+		{"Global flags first", []string{"--profile", "utn", "grades"}, "grades", []string{}, true},
+		{"Built-in command", []string{"repository", "list"}, "", nil, false},
+		{"Unknown command", []string{"notes"}, "", nil, false},
+		{"No command", []string{}, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args, ok := pluginCommand(tt.args)
+
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedArgs, args)
+			assert.Equal(t, tt.expectedOk, ok)
+		})
+	}
+	assert.Equal(t, "utn", common.SelectedProfile)
+}
+
+func TestCompletion(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	mockGithubService := new(MockGithubService)
+	mockGithubService.On("RepoNames").Return([]string{"tp1", "tp2"}, nil).Once()
+	mockGithubService.On("RepoNames").Return([]string{"tp3"}, nil).Once()
+	mockGithubService.On("CollaboratorNames", "tp1").Return([]string{"alumno1"}, nil)
+	mockConfigService := new(MockConfigService)
+	// Every completion but the one without repository looks for the profile
+	mockConfigService.On("ActiveProfile").Return("utn", nil).Times(4)
+	mockConfigService.On("ActiveProfile").Return("unlam", nil)
+	// Contexts are listed without an AI service
+	appContainer = &MockContainer{mockGitHubService: mockGithubService, mockConfigService: mockConfigService, contextNames: []string{"tp1-code"}}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"Repositories", []string{"collaborator", "list", "-r", ""}, []string{"tp1", "tp2"}},
+		{"Cached repositories", []string{"repository", "invite", "--repo", ""}, []string{"tp1", "tp2"}},
+		{"Collaborators without repository", []string{"repository", "invite", "-c", ""}, []string{}},
+		{"Collaborators", []string{"repository", "invite", "-r", "tp1", "-c", ""}, []string{"alumno1"}},
+		{"Contexts", []string{"ai", "ask", "-n", ""}, []string{"tp1-code"}},
+		// The profile stored with config use-profile has its own values
+		{"Repositories of another profile", []string{"collaborator", "list", "-r", ""}, []string{"tp3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, tt.args...))
+			defer rootCmd.SetOut(nil)
+			defer rootCmd.SetArgs(nil)
+
+			assert.NoError(t, rootCmd.Execute())
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			assert.Equal(t, tt.expected, lines[:len(lines)-1])
+			assert.Equal(t, fmt.Sprintf(":%d", cobra.ShellCompDirectiveNoFileComp), lines[len(lines)-1])
+		})
+	}
+	mockGithubService.AssertExpectations(t)
+}
+
 // Helper function to capture console output for testing
 func captureOutput(f func()) string {
 	// Create a pipe to redirect os.Stdout
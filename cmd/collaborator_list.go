@@ -9,7 +9,7 @@ var CollaboratorListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List collaborators for a repository",
 	Long:  `List collaborators for a repository`,
-	Run:   ListCollaborators,
+	RunE:  ListCollaborators,
 }
 
 func init() {
@@ -19,4 +19,8 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	err = CollaboratorListCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	if err != nil {
+		panic(err)
+	}
 }
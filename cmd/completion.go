@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate the autocompletion script for a shell",
+	Long: `Generate the autocompletion script for a shell. Besides commands and flags,
+repositories (-r), collaborators of the chosen repository (-c) and context names
+(ai ask -n) are completed. Values are cached for a minute.
+
+  bash:        source <(github-cli completion bash)
+  zsh:         github-cli completion zsh > "${fpath[1]}/_github-cli"
+  fish:        github-cli completion fish | source
+  powershell:  github-cli completion powershell | Out-String | Invoke-Expression
+
+Add the line to the shell profile to load the completions in every session.
+`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE:                  GenerateCompletion,
+}
+
+func init() {
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(completionCmd)
+}
+
+// completeRepos completes the repositories of the configured owner.
+func completeRepos(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeNames(completionKey("repos"), func() ([]string, error) {
+		ghService, err := appContainer.NewGithubService()
+		if err != nil {
+			return nil, err
+		}
+		return ghService.RepoNames()
+	})
+}
+
+// completeCollaborators completes the collaborators of the repository given with --repo.
+func completeCollaborators(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	repo, err := cmd.Flags().GetString("repo")
+	if err != nil || repo == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeNames(completionKey("collaborators", repo), func() ([]string, error) {
+		ghService, err := appContainer.NewGithubService()
+		if err != nil {
+			return nil, err
+		}
+		return ghService.CollaboratorNames(repo)
+	})
+}
+
+// completeContexts completes the contexts loaded in the vector store.
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeNames(completionKey("contexts"), appContainer.ContextNames)
+}
+
+// completeAliases completes the alias names, they are read from the config files so they are not cached.
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, err := appContainer.NewAliasService().Names()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNames returns the cached names or the ones returned by fetch. Errors are only
+// logged for debugging, the shell offers nothing rather than printing them while typing.
+func completeNames(key string, fetch func() ([]string, error)) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	dir, err := common.CacheDir()
+	if err == nil {
+		names, err = common.NewCompletionCache(filepath.Join(dir, common.CompletionCacheDirName), common.CompletionCacheTTL).Get(key, fetch)
+	} else {
+		names, err = fetch()
+	}
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionKey identifies the values of kind for the configuration in use, so profiles
+// and projects do not share completions.
+func completionKey(kind string, args ...string) string {
+	files, _ := common.ConfigFiles()
+	for i, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			files[i] = abs
+		}
+	}
+	profile, err := appContainer.NewConfigService().ActiveProfile()
+	if err != nil {
+		profile = common.ActiveProfile("")
+	}
+	parts := append([]string{kind, profile, os.Getenv(common.EnvVarPrefix + "OWNER")}, files...)
+	return strings.Join(append(parts, args...), "\x00")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration management.",
+	Long: `Configuration management.
+
+Settings are resolved from highest to lowest precedence:
+  1. GITHUB_CLI_<KEY> environment variables, e.g. GITHUB_CLI_TOKEN or GITHUB_CLI_OLLAMA_MODEL
+  2. the active profile, chosen with --profile, $GITHUB_CLI_PROFILE or config use-profile
+  3. the file given with --config or $GITHUB_CLI_CONFIG, which disables 4 and 5
+  4. the project file: .github-cli.yaml in the current directory or a parent, or ./.env
+  5. the user file: ~/.config/github-cli/config.yaml ($XDG_CONFIG_HOME is honored)
+  6. the token stored by auth login
+
+Profiles are defined in any of the config files, for example:
+
+profile: work
+profiles:
+  work:
+    owner: my-company
+    token: ghp_xxx
+  utn:
+    owner: utn-frba
+    token_command: pass show github/utn
+    ollama_model: llama3.2
+  lab:
+    owner: utn-lab
+    llm_provider: openai
+    openai_url: http://gpu-box:8080/v1
+    openai_model: qwen2.5-coder
+
+Top level keys provide defaults for every profile.
+
+Instead of storing the token, token_env names the environment variable holding it
+and token_command a command printing it. They are resolved when a GitHub command
+runs, token_command only once per process, and never printed.
+
+llm_provider selects the AI backend: ollama (default, ollama_url and ollama_model),
+openai for any OpenAI compatible server such as llama.cpp, vLLM or LocalAI
+(openai_url, openai_model, openai_api_key, openai_embedding_model) or anthropic
+(anthropic_url, anthropic_model, anthropic_api_key). Contexts need embeddings,
+which only ollama and openai provide.
+
+vector_store selects where contexts are kept: local, files under the user data
+directory (~/.local/share/github-cli/vectors, or vector_store_dir), or qdrant at
+qdrant_url. It defaults to qdrant when qdrant_url is set and to local otherwise.
+
+ai load keeps Go, Python, JavaScript, TypeScript and Java declarations whole in
+each chunk when they fit, and cuts other files by characters. chunk_sizes sets
+the size and overlap per language (go, python, javascript, typescript, java or
+default), e.g. chunk_sizes: go=2000/0,python=1500/150,default=500/50`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify a config action")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value.",
+	Long: `Print the resolved value of a configuration key, tokens are redacted. For example:
+git-cli config get owner
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: GetConfigValue,
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+}
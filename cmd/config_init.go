@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configInitCmd represents the config init command
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a config file interactively.",
+	Long: `Ask for the required values and write them to the config file. For example:
+git-cli config init
+git-cli --profile utn config init
+
+The user config file is written unless --config or $GITHUB_CLI_CONFIG are given.
+With --profile the values are stored in that profile.
+`,
+	Args: cobra.NoArgs,
+	RunE: InitConfig,
+}
+
+func init() {
+	configCmd.AddCommand(configInitCmd)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configListCmd represents the config list command
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configuration values.",
+	Long: `List the resolved configuration values, tokens are redacted. For example:
+git-cli config list
+`,
+	Args: cobra.NoArgs,
+	RunE: ListConfigValues,
+}
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configProfilesCmd represents the config profiles command
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List configuration profiles.",
+	Long: `List configuration profiles, the active one is marked with an asterisk. For example:
+git-cli config profiles
+`,
+	RunE: ListProfiles,
+}
+
+func init() {
+	configCmd.AddCommand(configProfilesCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value.",
+	Long: `Store a configuration value in the config file. For example:
+git-cli config set ollama_model llama3.2
+git-cli --profile utn config set owner utn-frba
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: SetConfigValue,
+}
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+}
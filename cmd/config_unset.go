@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configUnsetCmd represents the config unset command
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value.",
+	Long: `Remove a configuration value from the config file. For example:
+git-cli config unset qdrant_url
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: UnsetConfigValue,
+}
+
+func init() {
+	configCmd.AddCommand(configUnsetCmd)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configUseProfileCmd represents the config use-profile command
+var configUseProfileCmd = &cobra.Command{
+	Use:   "use-profile <name>",
+	Short: "Set the active configuration profile.",
+	Long: `Set the profile used when neither --profile nor $GITHUB_CLI_PROFILE are given. For example:
+git-cli config use-profile utn
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: UseProfile,
+}
+
+func init() {
+	configCmd.AddCommand(configUseProfileCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration.",
+	Long: `Check every configuration value, the GitHub authentication and that Ollama
+and Qdrant are reachable. Each problem is reported with a hint to fix it. For example:
+git-cli config validate
+`,
+	Args: cobra.NoArgs,
+	RunE: ValidateConfig,
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
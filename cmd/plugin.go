@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// pluginCmd represents the plugin command
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Plugin management.",
+	Long: `Plugin management.
+
+Any executable named github-cli-<name> on your PATH runs as github-cli <name>, with
+the arguments passed through. Plugins get the resolved configuration in the
+GITHUB_CLI_OWNER, GITHUB_CLI_TOKEN, GITHUB_CLI_PROFILE and GITHUB_CLI_CONFIG
+environment variables. For example:
+git-cli --profile utn grades --course k3001`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify a plugin action")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+}
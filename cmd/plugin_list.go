@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// pluginListCmd represents the plugin list command
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found on PATH.",
+	Long: `List the github-cli-<name> executables found on PATH. For example:
+git-cli plugin list
+`,
+	RunE: ListPlugins,
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+}
@@ -4,7 +4,6 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
 )
 
@@ -15,8 +14,8 @@ var repositoryCmd = &cobra.Command{
 	Long: `Repository management. For example:
 git-cli repository list
 git-cli repository invite -r my-repo -c my-collaborator`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Error: must specify a repository action")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageError("must specify a repository action")
 	},
 }
 
@@ -11,7 +11,7 @@ var repositoryInviteCmd = &cobra.Command{
 	Long: `Invite a collaborator to a repository. For example:
 git-cli repository invite -r my-repo -c my-collaborator
 `,
-	Run: InviteCollaborator,
+	RunE: InviteCollaborator,
 }
 
 func init() {
@@ -26,4 +26,12 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	err = repositoryInviteCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	if err != nil {
+		panic(err)
+	}
+	err = repositoryInviteCmd.RegisterFlagCompletionFunc("collaborator", completeCollaborators)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -11,7 +11,7 @@ var repositoryListCmd = &cobra.Command{
 	Long: `List Repositories. For example:
 git-cli repository list
 `,
-	Run: ListRepositories,
+	RunE: ListRepositories,
 }
 
 func init() {
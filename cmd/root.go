@@ -4,32 +4,176 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
 	"github.com/ffumaneri/github-cli/ioc"
+	"log/slog"
 	"os"
+	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var appContainer ioc.Container
 
+// verbose shows the causes wrapped by the reported errors.
+var verbose bool
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "github-cli",
 	Short: "Utils for GitHub",
-	Long:  `I did this app because I wanted to make easier my UTN work.`,
+	Long: `I did this app because I wanted to make easier my UTN work.
+
+Exit codes:
+  0  success
+  1  unexpected error
+  2  invalid arguments, flags or configuration
+  3  authentication failed, or not allowed by GitHub
+  4  repository, user or resource not found
+  5  GitHub rate limit exceeded
+  6  GitHub, the AI backend or the vector store cannot be reached
+  7  the AI backend or the vector store failed
+Plugins exit with their own codes.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Cobra checks required flags after this hook, check them first to report them as usage errors
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return err
+		}
+		if err := cmd.ValidateFlagGroups(); err != nil {
+			return err
+		}
+		if err := common.SetupLogging(commandName(cmd)); err != nil {
+			return err
+		}
+		// Arguments and flags are valid, errors from now on are not usage errors
+		cmd.SilenceUsage = true
+		return nil
+	},
+	SilenceErrors: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(container ioc.Container) {
 	appContainer = container
-	err := rootCmd.Execute()
+	args, done, err := ExpandAlias(os.Args[1:])
+	if !done {
+		err = runArgs(args)
+	}
+	_ = appContainer.Close()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(reportError(err))
+	}
+}
+
+// runArgs runs the plugin or the command selected by args.
+func runArgs(args []string) error {
+	if name, pluginArgs, ok := pluginCommand(args); ok {
+		if err := common.SetupLogging(name); err != nil {
+			return err
+		}
+		return RunPlugin(name, pluginArgs)
+	}
+	rootCmd.SetArgs(args)
+	return executeCommand()
+}
+
+// executeCommand runs the command selected by the arguments. Errors cobra returns before
+// running it are usage errors.
+func executeCommand() error {
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	if cmd != nil && cmd.SilenceUsage {
+		slog.Debug("command finished", common.LogDuration, time.Since(start), "error", err)
+	}
+	if err != nil && cmd != nil && !cmd.SilenceUsage {
+		return common.WithKind(common.KindValidation, err)
+	}
+	return err
+}
+
+// reportError prints err and returns the exit code it maps to. Plugins report their own
+// errors, only their exit code is kept.
+func reportError(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
 	}
+	fmt.Println("FAIL")
+	fmt.Fprintln(os.Stderr, "Error:", common.Describe(err, verbose))
+	return common.ExitCode(err)
+}
+
+func isPluginExit(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
+
+// pluginCommand returns the plugin to run when args name no built-in command, e.g.
+// `github-cli --profile utn grades --course k3001` runs github-cli-grades --course k3001.
+// The global flags before the plugin name are applied.
+func pluginCommand(args []string) (name string, pluginArgs []string, ok bool) {
+	i, ok := externalCommand(args)
+	if !ok {
+		return "", nil, false
+	}
+	if _, err := common.LookPlugin(args[i]); err != nil {
+		return "", nil, false
+	}
+	return args[i], args[i+1:], true
+}
+
+// externalCommand returns the index of the first argument when it names no built-in
+// command, so it may be an alias or a plugin. The global flags before it are applied,
+// --config and --profile choose where aliases are read from.
+func externalCommand(args []string) (int, bool) {
+	if _, _, err := rootCmd.Find(args); err == nil {
+		return 0, false
+	}
+	flags := rootCmd.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			if err := flags.Parse(args[:i]); err != nil {
+				return 0, false
+			}
+			return i, true
+		}
+		if arg == "--" {
+			return 0, false
+		}
+		// Skip the value of flags like --profile utn
+		flagName := strings.TrimLeft(arg, "-")
+		if strings.Contains(flagName, "=") {
+			continue
+		}
+		flag := flags.Lookup(flagName)
+		if flag == nil && len(flagName) == 1 {
+			flag = flags.ShorthandLookup(flagName)
+		}
+		if flag != nil && flag.Value.Type() != "bool" {
+			i++
+		}
+	}
+	return 0, false
+}
+
+// commandName names cmd in the log records, e.g. "audit show".
+func commandName(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// isBuiltinCommand reports whether name is a command of the CLI, aliases cannot hide them.
+func isBuiltinCommand(name string) bool {
+	cmd, _, err := rootCmd.Find([]string{name})
+	return err == nil && cmd != rootCmd
 }
 
 func init() {
@@ -37,7 +181,29 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.github-cli.yaml)")
+	rootCmd.PersistentFlags().StringVar(&common.SelectedConfigFile, "config", "", "config file, replaces the discovered ones (defaults to $GITHUB_CLI_CONFIG)")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "show the causes of errors")
+	rootCmd.PersistentFlags().BoolVar(&common.NoCache, "no-cache", false, "do not reuse or store GitHub responses")
+	rootCmd.PersistentFlags().BoolVar(&common.Offline, "offline", false, "answer GitHub reads from the cache, failing commands that change something")
+	rootCmd.MarkFlagsMutuallyExclusive("offline", "no-cache")
+	rootCmd.PersistentFlags().BoolVar(&common.DryRun, "dry-run", false, "log the requests that would change something on GitHub instead of sending them")
+	// Offline changes must fail, a dry run would pretend they succeeded
+	rootCmd.MarkFlagsMutuallyExclusive("offline", "dry-run")
+	rootCmd.PersistentFlags().StringVar(&common.LogLevel, "log-level", common.LogLevel, "lowest level of the logs written to stderr: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&common.LogFormat, "log-format", common.LogFormat, "format of the logs: text or json")
+	err := rootCmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{
+		"debug", "info", "warn", "error",
+	}, cobra.ShellCompDirectiveNoFileComp))
+	if err != nil {
+		panic(err)
+	}
+	err = rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{
+		common.LogFormatText, common.LogFormatJson,
+	}, cobra.ShellCompDirectiveNoFileComp))
+	if err != nil {
+		panic(err)
+	}
+	rootCmd.PersistentFlags().StringVar(&common.SelectedProfile, "profile", "", "configuration profile to use (defaults to $GITHUB_CLI_PROFILE or the one set with config use-profile)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse repositories and manage collaborators in a terminal UI.",
+	Long: `Browse the repositories of the owner in a full-screen terminal UI. Filter them
+with /, open one with enter to see its collaborators and pending invitations,
+invite a user with i and remove a collaborator or cancel an invitation with d.
+While it runs, logs are written to tui.log in the cache directory
+(~/.cache/github-cli on Linux) instead of stderr. For example:
+git-cli tui
+`,
+	Args: cobra.NoArgs,
+	RunE: RunTui,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
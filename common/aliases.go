@@ -0,0 +1,203 @@
+package common
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ShellAliasPrefix starts the expansion of the aliases run by the shell instead of
+// being parsed as github-cli arguments.
+const ShellAliasPrefix = "!"
+
+// aliasNamePattern keeps names usable as keys of the config file, which are lower cased
+// and split on dots.
+var aliasNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// aliasArgPattern matches the positional arguments of an expansion: $1, $2...
+var aliasArgPattern = regexp.MustCompile(`\$(\d+)`)
+
+// Alias is a name standing for a longer command line.
+type Alias struct {
+	Name      string
+	Expansion string
+}
+
+// Shell reports whether the alias is run by the shell.
+func (alias Alias) Shell() bool {
+	return strings.HasPrefix(alias.Expansion, ShellAliasPrefix)
+}
+
+// Script returns the command run by the shell for a shell alias.
+func (alias Alias) Script() string {
+	return strings.TrimPrefix(alias.Expansion, ShellAliasPrefix)
+}
+
+// Expand returns the arguments replacing the alias: the expansion split like a shell
+// would, with $1, $2... replaced by args and the arguments not referenced appended.
+func (alias Alias) Expand(args []string) ([]string, error) {
+	words, err := SplitArgs(alias.Expansion)
+	if err != nil {
+		return nil, err
+	}
+	used := 0
+	for _, word := range words {
+		for _, match := range aliasArgPattern.FindAllStringSubmatch(word, -1) {
+			n, _ := strconv.Atoi(match[1])
+			used = max(used, n)
+		}
+	}
+	if len(args) < used {
+		return nil, Errorf(KindValidation, "alias %s needs %d arguments, got %d", alias.Name, used, len(args))
+	}
+	expanded := make([]string, 0, len(words)+len(args)-used)
+	for _, word := range words {
+		expanded = append(expanded, aliasArgPattern.ReplaceAllStringFunc(word, func(match string) string {
+			n, _ := strconv.Atoi(match[1:])
+			if n == 0 {
+				return match
+			}
+			return args[n-1]
+		}))
+	}
+	return append(expanded, args[used:]...), nil
+}
+
+// LoadAliases returns the aliases of every config file, sorted by name.
+func LoadAliases(configLoader ConfigLoader) ([]Alias, error) {
+	files, err := LoadConfigFiles(configLoader)
+	if err != nil {
+		return nil, err
+	}
+	aliases := make([]Alias, 0, len(files.Aliases))
+	for name, expansion := range files.Aliases {
+		aliases = append(aliases, Alias{Name: name, Expansion: expansion})
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].Name < aliases[j].Name
+	})
+	return aliases, nil
+}
+
+// FindAlias returns the alias name, or nil when it is not defined.
+func FindAlias(configLoader ConfigLoader, name string) (*Alias, error) {
+	files, err := LoadConfigFiles(configLoader)
+	if err != nil {
+		return nil, err
+	}
+	expansion, ok := files.Aliases[name]
+	if !ok {
+		return nil, nil
+	}
+	return &Alias{Name: name, Expansion: expansion}, nil
+}
+
+// SetAlias stores alias in the writable config file, replacing the one with the same name.
+func SetAlias(configUpdater ConfigUpdater, alias Alias) error {
+	if !aliasNamePattern.MatchString(alias.Name) {
+		return Errorf(KindValidation, "invalid alias name %q, use lower case letters, digits, - and _", alias.Name)
+	}
+	if strings.TrimSpace(alias.Script()) == "" {
+		return Errorf(KindValidation, "the expansion of alias %s is empty", alias.Name)
+	}
+	if !alias.Shell() {
+		if _, err := SplitArgs(alias.Expansion); err != nil {
+			return err
+		}
+	}
+	path, err := WritableConfigFile()
+	if err != nil {
+		return err
+	}
+	return configUpdater(path, func(values map[string]any) error {
+		aliases, _ := values["aliases"].(map[string]any)
+		if aliases == nil {
+			aliases = map[string]any{}
+			values["aliases"] = aliases
+		}
+		aliases[alias.Name] = alias.Expansion
+		return nil
+	})
+}
+
+// DeleteAlias removes the alias name from the writable config file.
+func DeleteAlias(configUpdater ConfigUpdater, name string) error {
+	path, err := WritableConfigFile()
+	if err != nil {
+		return err
+	}
+	return configUpdater(path, func(values map[string]any) error {
+		aliases, _ := values["aliases"].(map[string]any)
+		if _, ok := aliases[name]; !ok {
+			return Errorf(KindValidation, "alias %s is not defined in %s", name, path)
+		}
+		delete(aliases, name)
+		if len(aliases) == 0 {
+			delete(values, "aliases")
+		}
+		return nil
+	})
+}
+
+// SplitArgs splits a command line into words like a POSIX shell does, honoring single
+// and double quotes and backslash escapes. Variables are left as they are.
+func SplitArgs(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, Errorf(KindValidation, "unterminated single quote in %q", line)
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i, inWord = end, true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				// Inside double quotes a backslash only escapes these
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, Errorf(KindValidation, "unterminated double quote in %q", line)
+			}
+			inWord = true
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"repository invite -r tp-$1 -c $2", []string{"repository", "invite", "-r", "tp-$1", "-c", "$2"}},
+		{`ai ask -q 'what does $1 do?'`, []string{"ai", "ask", "-q", "what does $1 do?"}},
+		{`ai ask -q "say \"hi\" to $1"`, []string{"ai", "ask", "-q", `say "hi" to $1`}},
+		{`a\ b  ''  c`, []string{"a b", "", "c"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			words, err := SplitArgs(tt.line)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, words)
+		})
+	}
+
+	_, err := SplitArgs(`ai ask -q "unterminated`)
+	assert.ErrorContains(t, err, "unterminated double quote")
+	_, err = SplitArgs(`ai ask -q 'unterminated`)
+	assert.ErrorContains(t, err, "unterminated single quote")
+}
+
+func TestAlias_Expand(t *testing.T) {
+	tests := []struct {
+		name      string
+		expansion string
+		args      []string
+		expected  []string
+		err       string
+	}{
+		{"Positional", "repository invite -r tp-$1 -c $2", []string{"3", "alice"}, []string{"repository", "invite", "-r", "tp-3", "-c", "alice"}, ""},
+		{"Extra arguments appended", "collaborator list", []string{"-r", "tp-3"}, []string{"collaborator", "list", "-r", "tp-3"}, ""},
+		{"Arguments with spaces", "ai ask -q $1", []string{"what is this?"}, []string{"ai", "ask", "-q", "what is this?"}, ""},
+		{"Reused", "x $1 $1", []string{"a", "b"}, []string{"x", "a", "a", "b"}, ""},
+		{"Missing arguments", "repository invite -r tp-$1 -c $2", []string{"3"}, nil, "alias invite-tp needs 2 arguments, got 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := Alias{Name: "invite-tp", Expansion: tt.expansion}.Expand(tt.args)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Equal(t, KindValidation, KindOf(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, expanded)
+		})
+	}
+}
+
+func TestAliases(t *testing.T) {
+	inProjectDir(t)
+	loader := func(path string, config interface{}) error {
+		file := config.(*FileConfig)
+		if path == ".env" {
+			file.Aliases = map[string]string{"tps": "repository list", "grades": "!grades.sh $1"}
+		} else {
+			file.Aliases = map[string]string{"tps": "collaborator list", "invite-tp": "repository invite -r tp-$1 -c $2"}
+		}
+		return nil
+	}
+
+	aliases, err := LoadAliases(loader)
+	assert.NoError(t, err)
+	// The project file takes precedence
+	assert.Equal(t, []Alias{
+		{Name: "grades", Expansion: "!grades.sh $1"},
+		{Name: "invite-tp", Expansion: "repository invite -r tp-$1 -c $2"},
+		{Name: "tps", Expansion: "repository list"},
+	}, aliases)
+	assert.True(t, aliases[0].Shell())
+	assert.Equal(t, "grades.sh $1", aliases[0].Script())
+
+	alias, err := FindAlias(loader, "tps")
+	assert.NoError(t, err)
+	assert.Equal(t, &Alias{Name: "tps", Expansion: "repository list"}, alias)
+	alias, err = FindAlias(loader, "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, alias)
+}
+
+func TestSetAndDeleteAlias(t *testing.T) {
+	inProjectDir(t)
+	stored := map[string]any{"owner": "utn"}
+	updater := func(path string, update func(values map[string]any) error) error {
+		return update(stored)
+	}
+
+	assert.NoError(t, SetAlias(updater, Alias{Name: "invite-tp", Expansion: "repository invite -r tp-$1 -c $2"}))
+	assert.NoError(t, SetAlias(updater, Alias{Name: "tps", Expansion: "!github-cli repository list | grep tp-"}))
+	assert.Equal(t, map[string]any{"owner": "utn", "aliases": map[string]any{
+		"invite-tp": "repository invite -r tp-$1 -c $2",
+		"tps":       "!github-cli repository list | grep tp-",
+	}}, stored)
+
+	for _, alias := range []Alias{{Name: "Invite", Expansion: "x"}, {Name: "a.b", Expansion: "x"}, {Name: "-r", Expansion: "x"}, {Name: "empty", Expansion: "!"}, {Name: "quote", Expansion: `ai ask -q "x`}} {
+		err := SetAlias(updater, alias)
+		assert.Error(t, err, alias.Name)
+		assert.Equal(t, KindValidation, KindOf(err))
+	}
+
+	assert.NoError(t, DeleteAlias(updater, "tps"))
+	assert.NoError(t, DeleteAlias(updater, "invite-tp"))
+	assert.Equal(t, map[string]any{"owner": "utn"}, stored)
+	assert.ErrorContains(t, DeleteAlias(updater, "tps"), "alias tps is not defined in")
+}
@@ -0,0 +1,130 @@
+package common
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditLogFileName is the file under DataDir recording the changes made on GitHub.
+const AuditLogFileName = "audit.jsonl"
+
+// AuditEntry records a request that changed, or tried to change, something on GitHub.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile,omitempty"`
+	Command string    `json:"command"`
+	Action  string    `json:"action"`
+	Repo    string    `json:"repo,omitempty"`
+	User    string    `json:"user,omitempty"`
+	Method  string    `json:"method"`
+	Path    string    `json:"path"`
+	// Status is 0 when no response was received
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditFilter selects audit entries, zero fields match every entry.
+type AuditFilter struct {
+	Since  time.Time
+	Repo   string
+	Action string
+}
+
+// Matches reports whether entry is selected. Repo matches with or without the owner.
+func (filter AuditFilter) Matches(entry AuditEntry) bool {
+	if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+		return false
+	}
+	if filter.Repo != "" && entry.Repo != filter.Repo && !strings.HasSuffix(entry.Repo, "/"+filter.Repo) {
+		return false
+	}
+	return filter.Action == "" || entry.Action == filter.Action
+}
+
+// AuditLogPath returns the audit log of the user.
+func AuditLogPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, AuditLogFileName), nil
+}
+
+// AuditLog appends entries to a JSONL file and reads them back.
+type AuditLog struct {
+	path string
+}
+
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Append adds entry as a line written at once, so commands running at the same time do
+// not mix their entries.
+func (log *AuditLog) Append(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(log.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(log.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	return errors.Join(err, file.Close())
+}
+
+// Entries returns the entries selected by filter, oldest first. A missing log has no
+// entries and lines that cannot be read, like one cut short by a crash, are skipped.
+func (log *AuditLog) Entries(filter AuditFilter) ([]AuditEntry, error) {
+	file, err := os.Open(log.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// ParseSince parses the start of a period: a duration before now like 36h or 7d, a date
+// like 2024-03-01 or an RFC 3339 time.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return date, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, Errorf(KindValidation, "invalid since %q, use a duration like 24h or 7d, or a date like 2024-03-01", value)
+}
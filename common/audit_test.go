@@ -0,0 +1,91 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github-cli", AuditLogFileName)
+	log := NewAuditLog(path)
+	march := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	entries, err := log.Entries(AuditFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "a missing log has no entries")
+
+	invite := AuditEntry{Time: march, Profile: "utn", Command: "github-cli repository invite -r tp1 -c alice",
+		Action: "invite", Repo: "utn/tp1", User: "alice", Method: "PUT", Path: "/repos/utn/tp1/collaborators/alice", Status: 201}
+	remove := AuditEntry{Time: march.AddDate(0, 0, 7), Command: "github-cli tui", Action: "remove-collaborator",
+		Repo: "utn/tp2", User: "bob", Method: "DELETE", Path: "/repos/utn/tp2/collaborators/bob", Status: 403, Error: "403 Forbidden"}
+	assert.NoError(t, log.Append(invite))
+	assert.NoError(t, log.Append(remove))
+	// A line cut short by a crash is skipped
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	file.WriteString(`{"time":"2024-03-09`)
+	file.Close()
+
+	tests := []struct {
+		name     string
+		filter   AuditFilter
+		expected []AuditEntry
+	}{
+		{"All", AuditFilter{}, []AuditEntry{invite, remove}},
+		{"Since", AuditFilter{Since: march.AddDate(0, 0, 1)}, []AuditEntry{remove}},
+		{"Repo without owner", AuditFilter{Repo: "tp1"}, []AuditEntry{invite}},
+		{"Repo with owner", AuditFilter{Repo: "utn/tp2"}, []AuditEntry{remove}},
+		{"Action", AuditFilter{Action: "invite"}, []AuditEntry{invite}},
+		{"No match", AuditFilter{Repo: "tp1", Action: "remove-collaborator"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.Entries(tt.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, entries)
+		})
+	}
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestAuditLogPath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/data")
+
+	path, err := AuditLogPath()
+
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("/tmp/data", AppName, AuditLogFileName), path)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+		err      bool
+	}{
+		{"36h", now.Add(-36 * time.Hour), false},
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2024-03-01T08:00:00Z", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+		{"-2d", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			since, err := ParseSince(tt.value, now)
+			if tt.err {
+				assert.Equal(t, KindValidation, KindOf(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(since), "expected %s, got %s", tt.expected, since)
+		})
+	}
+}
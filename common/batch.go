@@ -0,0 +1,198 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Operations a step of a batch file can run
+const (
+	BatchOpInvite     = "invite"
+	BatchOpRemove     = "remove"
+	BatchOpCreateRepo = "create-repo"
+	BatchOpPushFile   = "push-file"
+	BatchOpLabel      = "label"
+)
+
+// MaxBatchConcurrency keeps batches below the GitHub secondary rate limits, which are
+// hit quickly by parallel writes.
+const MaxBatchConcurrency = 20
+
+// batchVarPattern matches the variables used in the steps: ${name}.
+var batchVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var batchColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// BatchFile is a list of operations run in order by the batch command, e.g.
+//
+//	concurrency: 4
+//	vars:
+//	  course: k3001
+//	steps:
+//	  - op: create-repo
+//	    repo: tp1-${course}
+//	    private: true
+//	  - op: push-file
+//	    repo: tp1-${course}
+//	    path: README.md
+//	    source: templates/README.md
+//	    wait: true
+//	  - op: invite
+//	    repo: tp1-${course}
+//	    user: alice
+//	    continue_on_error: true
+type BatchFile struct {
+	// Concurrency is how many steps run at the same time, 1 when not set
+	Concurrency int               `yaml:"concurrency"`
+	Vars        map[string]string `yaml:"vars"`
+	Steps       []BatchStep       `yaml:"steps"`
+}
+
+// BatchStep is one operation of a batch file, the fields used depend on Op.
+type BatchStep struct {
+	Name string `yaml:"name"` // shown in the report instead of the operation
+	Op   string `yaml:"op"`
+	Repo string `yaml:"repo"`
+
+	User string `yaml:"user"` // invite and remove
+
+	Description string `yaml:"description"` // create-repo and label
+	Private     bool   `yaml:"private"`     // create-repo
+
+	Path    string `yaml:"path"` // push-file
+	Content string `yaml:"content"`
+	Source  string `yaml:"source"` // file with the content, relative to the batch file
+	Message string `yaml:"message"`
+	Branch  string `yaml:"branch"`
+
+	Label string `yaml:"label"` // label
+	Color string `yaml:"color"`
+
+	// ContinueOnError keeps running the next steps when this one fails
+	ContinueOnError bool `yaml:"continue_on_error"`
+	// Wait starts the step once every previous step finished, for steps needing what
+	// earlier ones create when they run concurrently
+	Wait bool `yaml:"wait"`
+}
+
+// Title describes the step in progress lines and reports.
+func (step BatchStep) Title() string {
+	if step.Name != "" {
+		return step.Name
+	}
+	switch step.Op {
+	case BatchOpInvite:
+		return fmt.Sprintf("invite %s to %s", step.User, step.Repo)
+	case BatchOpRemove:
+		return fmt.Sprintf("remove %s from %s", step.User, step.Repo)
+	case BatchOpCreateRepo:
+		return fmt.Sprintf("create repo %s", step.Repo)
+	case BatchOpPushFile:
+		return fmt.Sprintf("push %s to %s", step.Path, step.Repo)
+	case BatchOpLabel:
+		return fmt.Sprintf("label %s in %s", step.Label, step.Repo)
+	}
+	return step.Op
+}
+
+// ParseBatchFile reads a batch file and checks every step, so nothing runs when any of
+// them is wrong. The ${name} variables of the steps are replaced by vars, or by the vars
+// of the file when vars does not have them.
+func ParseBatchFile(data []byte, vars map[string]string) (*BatchFile, error) {
+	var batch BatchFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// Typos in field names would otherwise silently drop values
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&batch); err != nil && !errors.Is(err, io.EOF) {
+		return nil, WrapError(KindValidation, err, "invalid batch file: "+err.Error())
+	}
+	if len(batch.Steps) == 0 {
+		return nil, NewError(KindValidation, "the batch file has no steps")
+	}
+	if batch.Concurrency == 0 {
+		batch.Concurrency = 1
+	}
+	if batch.Concurrency < 1 || batch.Concurrency > MaxBatchConcurrency {
+		return nil, Errorf(KindValidation, "concurrency must be between 1 and %d, got %d", MaxBatchConcurrency, batch.Concurrency)
+	}
+	if batch.Vars == nil {
+		batch.Vars = map[string]string{}
+	}
+	for name, value := range vars {
+		batch.Vars[name] = value
+	}
+	for i := range batch.Steps {
+		if err := batch.Steps[i].expand(batch.Vars); err != nil {
+			return nil, Errorf(KindValidation, "step %d: %s", i+1, err)
+		}
+		if err := batch.Steps[i].validate(); err != nil {
+			return nil, Errorf(KindValidation, "step %d: %s", i+1, err)
+		}
+	}
+	return &batch, nil
+}
+
+// expand replaces the variables in the string fields of the step.
+func (step *BatchStep) expand(vars map[string]string) error {
+	var missing string
+	value := reflect.ValueOf(step).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() != reflect.String {
+			continue
+		}
+		field.SetString(batchVarPattern.ReplaceAllStringFunc(field.String(), func(match string) string {
+			name := match[2 : len(match)-1]
+			replacement, ok := vars[name]
+			if !ok && missing == "" {
+				missing = name
+			}
+			return replacement
+		}))
+	}
+	if missing != "" {
+		return fmt.Errorf("variable %s is not defined, add it to vars or pass --var %s=...", missing, missing)
+	}
+	return nil
+}
+
+func (step *BatchStep) validate() error {
+	var required []string
+	switch step.Op {
+	case BatchOpInvite, BatchOpRemove:
+		required = []string{"repo", step.Repo, "user", step.User}
+	case BatchOpCreateRepo:
+		required = []string{"repo", step.Repo}
+	case BatchOpPushFile:
+		required = []string{"repo", step.Repo, "path", step.Path}
+		if (step.Content == "") == (step.Source == "") {
+			return errors.New("push-file needs either content or source")
+		}
+		if step.Message == "" {
+			step.Message = "Update " + step.Path
+		}
+	case BatchOpLabel:
+		required = []string{"repo", step.Repo, "label", step.Label}
+		step.Color = strings.TrimPrefix(step.Color, "#")
+		if step.Color != "" && !batchColorPattern.MatchString(step.Color) {
+			return fmt.Errorf("invalid color %q, use six hex digits like 0e8a16", step.Color)
+		}
+	case "":
+		return errors.New("op is missing")
+	default:
+		return fmt.Errorf("unknown op %q, use %s, %s, %s, %s or %s", step.Op,
+			BatchOpInvite, BatchOpRemove, BatchOpCreateRepo, BatchOpPushFile, BatchOpLabel)
+	}
+	for i := 0; i < len(required); i += 2 {
+		if strings.TrimSpace(required[i+1]) == "" {
+			return fmt.Errorf("%s needs %s", step.Op, required[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBatchFile(t *testing.T) {
+	data := []byte(`
+concurrency: 4
+vars:
+  course: k3001
+  teacher: prof
+steps:
+  - op: create-repo
+    repo: tp1-${course}
+    private: true
+  - op: push-file
+    repo: tp1-${course}
+    path: README.md
+    content: "# TP 1 ${course}"
+    wait: true
+  - op: label
+    repo: tp1-${course}
+    label: delivered
+    color: "#0E8A16"
+  - name: invite the teacher
+    op: invite
+    repo: tp1-${course}
+    user: ${teacher}
+    continue_on_error: true
+`)
+
+	batch, err := ParseBatchFile(data, map[string]string{"course": "k3002"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, batch.Concurrency)
+	assert.Equal(t, []BatchStep{
+		{Op: BatchOpCreateRepo, Repo: "tp1-k3002", Private: true},
+		{Op: BatchOpPushFile, Repo: "tp1-k3002", Path: "README.md", Content: "# TP 1 k3002", Message: "Update README.md", Wait: true},
+		{Op: BatchOpLabel, Repo: "tp1-k3002", Label: "delivered", Color: "0E8A16"},
+		{Name: "invite the teacher", Op: BatchOpInvite, Repo: "tp1-k3002", User: "prof", ContinueOnError: true},
+	}, batch.Steps)
+	assert.Equal(t, []string{"create repo tp1-k3002", "push README.md to tp1-k3002", "label delivered in tp1-k3002", "invite the teacher"},
+		[]string{batch.Steps[0].Title(), batch.Steps[1].Title(), batch.Steps[2].Title(), batch.Steps[3].Title()})
+}
+
+func TestParseBatchFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"Empty", "", "the batch file has no steps"},
+		{"Unknown field", "steps:\n  - op: invite\n    repository: tp1\n", "field repository not found"},
+		{"Unknown op", "steps:\n  - op: fork\n    repo: tp1\n", `step 1: unknown op "fork"`},
+		{"Missing op", "steps:\n  - repo: tp1\n", "step 1: op is missing"},
+		{"Missing user", "steps:\n  - op: create-repo\n    repo: tp1\n  - op: remove\n    repo: tp1\n", "step 2: remove needs user"},
+		{"Content and source", "steps:\n  - op: push-file\n    repo: tp1\n    path: a\n    content: x\n    source: y\n", "step 1: push-file needs either content or source"},
+		{"Invalid color", "steps:\n  - op: label\n    repo: tp1\n    label: x\n    color: green\n", `step 1: invalid color "green"`},
+		{"Undefined variable", "steps:\n  - op: create-repo\n    repo: tp-${course}\n", "step 1: variable course is not defined"},
+		{"Concurrency", "concurrency: 50\nsteps:\n  - op: create-repo\n    repo: tp1\n", "concurrency must be between 1 and 20, got 50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBatchFile([]byte(tt.data), nil)
+			assert.ErrorContains(t, err, tt.err)
+			assert.Equal(t, KindValidation, KindOf(err))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CompletionCacheTTL is how long completion values are reused before asking GitHub or
+// the vector store again, short enough to see a new repository on the next minute.
+const CompletionCacheTTL = time.Minute
+
+// Directories under CacheDir
+const (
+	CompletionCacheDirName = "completion"
+	HttpCacheDirName       = "http"
+)
+
+// CacheDir returns the directory where the CLI keeps data that can be recreated:
+// $XDG_CACHE_HOME/github-cli, ~/.cache/github-cli, or the cache directory on macOS and Windows.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, AppName), nil
+}
+
+// WriteFileAtomic writes data to a temporary file renamed to path, so a command running
+// at the same time never reads half a file. Missing directories are created only
+// readable by the user.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// CompletionCache keeps the values offered by shell completion in a file per key, so
+// pressing tab twice does not call the API twice.
+type CompletionCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+func NewCompletionCache(dir string, ttl time.Duration) *CompletionCache {
+	return &CompletionCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Get returns the values cached for key while they are younger than the TTL, otherwise
+// the ones returned by fetch. Errors are not cached and failing to write the cache is not
+// an error, completion works without it.
+func (cache *CompletionCache) Get(key string, fetch func() ([]string, error)) ([]string, error) {
+	path := cache.path(key)
+	if info, err := os.Stat(path); err == nil && cache.now().Sub(info.ModTime()) < cache.ttl {
+		if data, err := os.ReadFile(path); err == nil {
+			var values []string
+			if err = json.Unmarshal(data, &values); err == nil {
+				return values, nil
+			}
+		}
+	}
+	values, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	cache.save(path, values)
+	return values, nil
+}
+
+func (cache *CompletionCache) save(path string, values []string) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return
+	}
+	_ = WriteFileAtomic(path, data)
+}
+
+// path names the file after a hash of key, keys hold repository names and config paths.
+func (cache *CompletionCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(cache.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionCache(t *testing.T) {
+	cache := NewCompletionCache(filepath.Join(t.TempDir(), "completion"), time.Minute)
+	calls := 0
+	fetch := func() ([]string, error) {
+		calls++
+		return []string{"repo1", "repo2"}, nil
+	}
+
+	values, err := cache.Get("repos", fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"repo1", "repo2"}, values)
+
+	values, err = cache.Get("repos", fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"repo1", "repo2"}, values)
+	assert.Equal(t, 1, calls, "the second call is served from the cache")
+
+	_, err = cache.Get("collaborators", func() ([]string, error) { return nil, errors.New("API error") })
+	assert.EqualError(t, err, "API error")
+
+	// Once the TTL expires the values are fetched again
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = cache.Get("repos", fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCacheDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache")
+	t.Setenv("HOME", "/home/utn")
+
+	dir, err := CacheDir()
+
+	assert.NoError(t, err)
+	assert.Equal(t, AppName, filepath.Base(dir))
+}
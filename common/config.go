@@ -1,42 +1,638 @@
 package common
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-// Config structure to hold the configuration
+// Config structure to hold the configuration. It is split in sections so each command
+// only validates the values it needs, while config files keep flat keys.
 type Config struct {
-	Token        string
-	Owner        string
+	GithubConfig      `mapstructure:",squash"`
+	LlmConfig         `mapstructure:",squash"`
+	VectorStoreConfig `mapstructure:",squash"`
+
+	// searched lists the sources the values were looked up in, reported on missing keys
+	searched []string
+	// profile is the name of the active profile, if any
+	profile string
+	// storedTokenErr is why the token stored by auth login could not be read
+	storedTokenErr error
+}
+
+// GithubConfig holds the values needed by the commands that call the GitHub API.
+type GithubConfig struct {
+	Token string `secret:"true"`
+	Owner string
+
+	// Alternatives to storing Token, resolved when the client is created
+	Token_Env     string // name of the environment variable holding the token
+	Token_Command string // command printing the token, e.g. pass show github/utn
+
+	// GitHub App authentication, used instead of Token when App_Id is set
+	App_Id              int64
+	App_Installation_Id int64
+	App_Private_Key     string // path to the PEM encoded private key of the app
+}
+
+// LlmConfig holds the values needed by the AI commands. Llm_Provider selects the
+// backend, each one has its own base URL and model.
+type LlmConfig struct {
+	Llm_Provider string // ollama when empty, openai or anthropic
+
+	Ollama_Url   string // defaults to $OLLAMA_HOST or http://localhost:11434
 	Ollama_Model string
-	Qdrant_Url   string
+
+	// Any OpenAI compatible server, e.g. llama.cpp, vLLM or LocalAI
+	Openai_Url             string // e.g. http://localhost:8080/v1
+	Openai_Model           string
+	Openai_Api_Key         string `secret:"true"` // defaults to $OPENAI_API_KEY, local servers do not need it
+	Openai_Embedding_Model string
+
+	Anthropic_Url     string
+	Anthropic_Model   string
+	Anthropic_Api_Key string `secret:"true"` // defaults to $ANTHROPIC_API_KEY
 }
 
+const (
+	LlmProviderOllama    = "ollama"
+	LlmProviderOpenAI    = "openai"
+	LlmProviderAnthropic = "anthropic"
+)
+
+// Provider returns the selected LLM backend.
+func (config *LlmConfig) Provider() string {
+	if config.Llm_Provider == "" {
+		return LlmProviderOllama
+	}
+	return strings.ToLower(config.Llm_Provider)
+}
+
+// Model returns the model of the selected LLM backend.
+func (config *LlmConfig) Model() string {
+	switch config.Provider() {
+	case LlmProviderOpenAI:
+		return config.Openai_Model
+	case LlmProviderAnthropic:
+		return config.Anthropic_Model
+	}
+	return config.Ollama_Model
+}
+
+// VectorStoreConfig holds the values needed by the AI commands that use a context.
+type VectorStoreConfig struct {
+	Vector_Store     string // local or qdrant, defaults to qdrant when qdrant_url is set
+	Vector_Store_Dir string // directory of the local store, defaults to vectors under the user data directory
+	Qdrant_Url       string
+
+	// Chunk_Sizes overrides the size and overlap of the chunks of loaded files per language,
+	// e.g. go=2000/0,python=1500/150,default=500/50
+	Chunk_Sizes string
+}
+
+// ChunkSize is how many characters a chunk of a loaded file has at most, and how many of
+// them consecutive chunks share when a text has to be cut.
+type ChunkSize struct {
+	Size    int
+	Overlap int
+}
+
+// Languages with their own chunk size, default applies to the files of any other
+const (
+	LanguageDefault    = "default"
+	LanguageGo         = "go"
+	LanguagePython     = "python"
+	LanguageJavaScript = "javascript"
+	LanguageTypeScript = "typescript"
+	LanguageJava       = "java"
+)
+
+// defaultChunkSizes keep plain text in small chunks, code is chunked by declaration and
+// only declarations larger than the size are cut.
+var defaultChunkSizes = map[string]ChunkSize{
+	LanguageDefault:    {Size: 300, Overlap: 30},
+	LanguageGo:         {Size: 1500, Overlap: 100},
+	LanguagePython:     {Size: 1500, Overlap: 100},
+	LanguageJavaScript: {Size: 1500, Overlap: 100},
+	LanguageTypeScript: {Size: 1500, Overlap: 100},
+	LanguageJava:       {Size: 1500, Overlap: 100},
+}
+
+// ChunkSizes returns the chunk size of every language, chunk_sizes overriding the defaults.
+func (config *VectorStoreConfig) ChunkSizes() (map[string]ChunkSize, error) {
+	sizes := make(map[string]ChunkSize, len(defaultChunkSizes))
+	for language, size := range defaultChunkSizes {
+		sizes[language] = size
+	}
+	if strings.TrimSpace(config.Chunk_Sizes) == "" {
+		return sizes, nil
+	}
+	for _, entry := range strings.Split(config.Chunk_Sizes, ",") {
+		language, value, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		language = strings.ToLower(strings.TrimSpace(language))
+		if _, ok := defaultChunkSizes[language]; !ok {
+			languages := make([]string, 0, len(defaultChunkSizes))
+			for name := range defaultChunkSizes {
+				languages = append(languages, name)
+			}
+			sort.Strings(languages)
+			return nil, Errorf(KindValidation, "invalid configuration value chunk_sizes: unknown language %q, expected %s", language, strings.Join(languages, ", "))
+		}
+		size := sizes[language]
+		sizeValue, overlapValue, hasOverlap := strings.Cut(value, "/")
+		var err error
+		if size.Size, err = strconv.Atoi(strings.TrimSpace(sizeValue)); err == nil && hasOverlap {
+			size.Overlap, err = strconv.Atoi(strings.TrimSpace(overlapValue))
+		}
+		if err != nil || size.Size <= 0 || size.Overlap < 0 || size.Overlap >= size.Size {
+			return nil, Errorf(KindValidation, "invalid configuration value chunk_sizes: %q, use language=size/overlap with an overlap smaller than the size", entry)
+		}
+		sizes[language] = size
+	}
+	return sizes, nil
+}
+
+const (
+	VectorStoreLocal  = "local"
+	VectorStoreQdrant = "qdrant"
+)
+
+// Kind returns the selected vector store.
+func (config *VectorStoreConfig) Kind() string {
+	if config.Vector_Store != "" {
+		return strings.ToLower(config.Vector_Store)
+	}
+	if config.Qdrant_Url != "" {
+		return VectorStoreQdrant
+	}
+	return VectorStoreLocal
+}
+
+// LocalStoreDir returns the directory holding a file per collection of the local store.
+func (config *VectorStoreConfig) LocalStoreDir() (string, error) {
+	if config.Vector_Store_Dir != "" {
+		return config.Vector_Store_Dir, nil
+	}
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vectors"), nil
+}
+
+// ManifestDir returns the directory keeping what was loaded in each collection of the
+// store. The local store keeps it next to the collections, Qdrant a directory per server.
+func (config *VectorStoreConfig) ManifestDir() (string, error) {
+	if config.Kind() == VectorStoreLocal {
+		dir, err := config.LocalStoreDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "manifests"), nil
+	}
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(strings.TrimRight(config.Qdrant_Url, "/")))
+	return filepath.Join(dir, "manifests", "qdrant-"+hex.EncodeToString(sum[:4])), nil
+}
+
+// UsesGithubApp reports whether requests must be authenticated as a GitHub App installation.
+func (config *GithubConfig) UsesGithubApp() bool {
+	return config.App_Id != 0
+}
+
+// MissingKeyError reports a required key no configuration source provides.
+type MissingKeyError struct {
+	Key      string
+	Section  string
+	Searched []string
+}
+
+func (e *MissingKeyError) Error() string {
+	return fmt.Sprintf("missing required configuration value %s for %s, searched: %s", e.Key, e.Section, strings.Join(e.Searched, ", "))
+}
+
+func (e *MissingKeyError) Kind() ErrorKind {
+	return KindValidation
+}
+
+// FileConfig is the content of a config file. Values set in the active profile take
+// precedence over the top level ones.
+type FileConfig struct {
+	Config   `mapstructure:",squash"`
+	Profile  string
+	Profiles map[string]Config
+	// Aliases maps names to the command lines they stand for
+	Aliases map[string]string
+}
+
+const (
+	ProfileEnvVar = "GITHUB_CLI_PROFILE"
+	ConfigEnvVar  = "GITHUB_CLI_CONFIG"
+	// EnvVarPrefix is prepended to the upper cased key name to override it, e.g. GITHUB_CLI_TOKEN
+	EnvVarPrefix = "GITHUB_CLI_"
+	// ProjectConfigFileName is looked up from the current directory towards the root
+	ProjectConfigFileName = ".github-cli.yaml"
+)
+
+// SelectedProfile is the profile chosen on the command line, it takes precedence over
+// $GITHUB_CLI_PROFILE and the profile stored in the config files.
+var SelectedProfile string
+
+// SelectedConfigFile is the config file given with --config, it takes precedence over $GITHUB_CLI_CONFIG.
+var SelectedConfigFile string
+
+// DryRun is set with --dry-run, requests that would change something on GitHub are only logged.
+var DryRun bool
+
+// NoCache is set with --no-cache, GitHub responses are neither reused nor stored.
+var NoCache bool
+
+// Offline is set with --offline, GitHub reads are answered from the cache and changes fail.
+var Offline bool
+
+// ErrOffline is returned by the changes to GitHub attempted with --offline.
+var ErrOffline = NewError(KindValidation, "cannot change anything on GitHub while offline")
+
 var cachedConfig *Config // This will store the configuration as a singleton
 
 type ConfigLoader func(path string, config interface{}) error
 
+// ConfigUpdater applies update to the values stored in the config file at path, creating it when missing.
+type ConfigUpdater func(path string, update func(values map[string]any) error) error
+
+// NewConfig resolves the configuration. From highest to lowest precedence:
+//
+//  1. GITHUB_CLI_<KEY> environment variables, e.g. GITHUB_CLI_TOKEN
+//  2. the active profile (--profile, $GITHUB_CLI_PROFILE or the stored profile key)
+//  3. the file given with --config or $GITHUB_CLI_CONFIG, which disables 4 and 5
+//  4. the project file: .github-cli.yaml in the current directory or a parent, or ./.env
+//  5. the user file: config.yaml under the user config directory (~/.config/github-cli)
+//  6. the token stored by `auth login`
+//
+// Values are not validated here, use Github, Llm or VectorStore to get a validated section.
 func NewConfig(configLoader ConfigLoader) (*Config, error) {
 	if cachedConfig != nil {
 		return cachedConfig, nil
 	}
 
-	config := &Config{}
-	err := configLoader(".env", config)
+	config, err := ResolveConfig(configLoader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %v", err)
+		return nil, err
 	}
 
-	// Ensure all required fields are set
-	if config.Token == "" || config.Owner == "" || config.Ollama_Model == "" {
-		return nil, fmt.Errorf("missing required configuration values")
+	cachedConfig = config
+	return config, nil
+}
+
+// ResolveConfig merges every configuration source without validating the result.
+func ResolveConfig(configLoader ConfigLoader) (*Config, error) {
+	files, err := LoadConfigFiles(configLoader)
+	if err != nil {
+		return nil, err
+	}
+	searched, err := ConfigFiles()
+	if err != nil {
+		return nil, err
 	}
 
-	// More
-	if config.Qdrant_Url == "" {
-		return nil, fmt.Errorf("missing required configuration values")
+	config := &files.Config
+	if name := ActiveProfile(files.Profile); name != "" {
+		profile, ok := files.Profiles[name]
+		if !ok {
+			return nil, Errorf(KindValidation, "profile %q is not defined", name)
+		}
+		config = config.merge(&profile)
+		config.profile = name
+		searched = append([]string{fmt.Sprintf("profile %q", name)}, searched...)
 	}
 
-	cachedConfig = config
+	overrides, err := envOverrides()
+	if err != nil {
+		return nil, err
+	}
+	config = config.merge(overrides)
+	config.searched = searched
+
+	if !config.UsesGithubApp() && !config.HasTokenSource() {
+		// Fall back to the token stored by `auth login`. Only GitHub commands need it, so
+		// an unreadable file is reported by Github and the other commands still run.
+		config.Token, config.storedTokenErr = storedToken()
+		if config.storedTokenErr != nil {
+			slog.Warn("cannot read the token stored by auth login", "error", config.storedTokenErr)
+		}
+	}
 	return config, nil
 }
+
+// ProfileName returns the name of the active profile, empty when none is used.
+func (config *Config) ProfileName() string {
+	return config.profile
+}
+
+// Github returns the GitHub section, failing when a value needed to call the API is missing.
+func (config *Config) Github() (*GithubConfig, error) {
+	section := "GitHub commands"
+	var problems []error
+	if config.Owner == "" {
+		problems = append(problems, config.missingKey("owner", section))
+	}
+	// Either a personal token or a complete GitHub App setup is needed
+	if config.UsesGithubApp() {
+		if config.App_Installation_Id == 0 {
+			problems = append(problems, config.missingKey("app_installation_id", section))
+		}
+		if config.App_Private_Key == "" {
+			problems = append(problems, config.missingKey("app_private_key", section))
+		}
+	} else if !config.HasTokenSource() {
+		missing := config.missingKey("token", section)
+		if store, err := NewFileCredentialsStore(); err == nil {
+			missing.Searched = append(missing.Searched, store.path+" (auth login)")
+		}
+		problems = append(problems, missing)
+		if config.storedTokenErr != nil {
+			problems = append(problems, Errorf(KindAuth, "cannot read the token stored by auth login: %w", config.storedTokenErr))
+		}
+	}
+	return &config.GithubConfig, errors.Join(problems...)
+}
+
+// Llm returns the LLM section, failing when a value needed by the selected provider is missing.
+func (config *Config) Llm() (*LlmConfig, error) {
+	section := "AI commands"
+	var problems []error
+	switch config.Provider() {
+	case LlmProviderOllama:
+		if config.Ollama_Model == "" {
+			problems = append(problems, config.missingKey("ollama_model", section))
+		}
+	case LlmProviderOpenAI:
+		if config.Openai_Url == "" {
+			problems = append(problems, config.missingKey("openai_url", section))
+		}
+		if config.Openai_Model == "" {
+			problems = append(problems, config.missingKey("openai_model", section))
+		}
+	case LlmProviderAnthropic:
+		if config.Anthropic_Model == "" {
+			problems = append(problems, config.missingKey("anthropic_model", section))
+		}
+	default:
+		problems = append(problems, Errorf(KindValidation, "invalid configuration value llm_provider: %q, expected %s, %s or %s",
+			config.Llm_Provider, LlmProviderOllama, LlmProviderOpenAI, LlmProviderAnthropic))
+	}
+	return &config.LlmConfig, errors.Join(problems...)
+}
+
+// VectorStore returns the vector store section, failing when a value needed to store or search contexts is missing.
+func (config *Config) VectorStore() (*VectorStoreConfig, error) {
+	switch config.Kind() {
+	case VectorStoreLocal:
+	case VectorStoreQdrant:
+		if config.Qdrant_Url == "" {
+			return &config.VectorStoreConfig, config.missingKey("qdrant_url", "AI contexts")
+		}
+	default:
+		return &config.VectorStoreConfig, Errorf(KindValidation, "invalid configuration value vector_store: %q, expected %s or %s",
+			config.Vector_Store, VectorStoreLocal, VectorStoreQdrant)
+	}
+	_, err := config.ChunkSizes()
+	return &config.VectorStoreConfig, err
+}
+
+// Validate checks every section.
+func (config *Config) Validate() error {
+	return errors.Join(config.FieldErrors()...)
+}
+
+// FieldErrors returns a problem for every missing value of every section.
+func (config *Config) FieldErrors() []error {
+	var problems []error
+	_, githubErr := config.Github()
+	_, llmErr := config.Llm()
+	_, vectorStoreErr := config.VectorStore()
+	for _, err := range []error{githubErr, llmErr, vectorStoreErr} {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			problems = append(problems, joined.Unwrap()...)
+		} else if err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems
+}
+
+func (config *Config) missingKey(key, section string) *MissingKeyError {
+	searched := append([]string{"$" + EnvVarPrefix + strings.ToUpper(key)}, config.searched...)
+	return &MissingKeyError{Key: key, Section: section, Searched: searched}
+}
+
+// UserConfigFile returns the path of the config file under the user config directory.
+func UserConfigFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// ExplicitConfigFile returns the file given with --config or $GITHUB_CLI_CONFIG, if any.
+func ExplicitConfigFile() string {
+	if SelectedConfigFile != "" {
+		return SelectedConfigFile
+	}
+	return os.Getenv(ConfigEnvVar)
+}
+
+// ProjectConfigFile looks for .github-cli.yaml in the current directory and its parents,
+// falling back to a .env file in the current directory.
+func ProjectConfigFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, ProjectConfigFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if _, err := os.Stat(".env"); err == nil {
+		return ".env"
+	}
+	return ""
+}
+
+// ConfigFiles returns the config files to load, from lowest to highest precedence.
+// An explicit file replaces the discovery of the project and user config files.
+func ConfigFiles() ([]string, error) {
+	if explicit := ExplicitConfigFile(); explicit != "" {
+		return []string{explicit}, nil
+	}
+	userFile, err := UserConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	files := []string{userFile}
+	if projectFile := ProjectConfigFile(); projectFile != "" {
+		files = append(files, projectFile)
+	}
+	return files, nil
+}
+
+// WritableConfigFile returns the file modified by commands that change the configuration.
+func WritableConfigFile() (string, error) {
+	if explicit := ExplicitConfigFile(); explicit != "" {
+		return explicit, nil
+	}
+	return UserConfigFile()
+}
+
+// LoadConfigFiles loads and merges the config files returned by ConfigFiles.
+// Discovered files are optional, an explicit one must exist.
+func LoadConfigFiles(configLoader ConfigLoader) (*FileConfig, error) {
+	paths, err := ConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+	explicit := ExplicitConfigFile()
+	merged := &FileConfig{Profiles: map[string]Config{}, Aliases: map[string]string{}}
+	for _, path := range paths {
+		file := &FileConfig{}
+		err = configLoader(path, file)
+		if errors.Is(err, os.ErrNotExist) && path != explicit {
+			continue
+		}
+		if err != nil {
+			return nil, Errorf(KindValidation, "failed to load config %s: %v", path, err)
+		}
+		merged.Config = *merged.Config.merge(&file.Config)
+		if file.Profile != "" {
+			merged.Profile = file.Profile
+		}
+		for name, profile := range file.Profiles {
+			current := merged.Profiles[name]
+			merged.Profiles[name] = *current.merge(&profile)
+		}
+		for name, expansion := range file.Aliases {
+			merged.Aliases[name] = expansion
+		}
+	}
+	return merged, nil
+}
+
+// ProfileNames returns the defined profiles sorted by name.
+func (files *FileConfig) ProfileNames() []string {
+	names := make([]string, 0, len(files.Profiles))
+	for name := range files.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile resolves the profile to use: --profile, then $GITHUB_CLI_PROFILE, then the stored one.
+func ActiveProfile(storedProfile string) string {
+	if SelectedProfile != "" {
+		return SelectedProfile
+	}
+	if profile := os.Getenv(ProfileEnvVar); profile != "" {
+		return profile
+	}
+	return storedProfile
+}
+
+// UseProfile stores name as the active profile in the writable config file.
+func UseProfile(configLoader ConfigLoader, configUpdater ConfigUpdater, name string) error {
+	files, err := LoadConfigFiles(configLoader)
+	if err != nil {
+		return err
+	}
+	if _, ok := files.Profiles[name]; !ok {
+		return Errorf(KindValidation, "profile %q is not defined", name)
+	}
+	path, err := WritableConfigFile()
+	if err != nil {
+		return err
+	}
+	return configUpdater(path, func(values map[string]any) error {
+		values["profile"] = name
+		return nil
+	})
+}
+
+// envOverrides reads the GITHUB_CLI_<KEY> environment variables.
+func envOverrides() (*Config, error) {
+	overrides := &Config{}
+	value := reflect.ValueOf(overrides).Elem()
+	for _, field := range configFields() {
+		name := EnvVarPrefix + strings.ToUpper(field.Name)
+		env := os.Getenv(name)
+		if env == "" {
+			continue
+		}
+		switch target := value.FieldByIndex(field.Index); target.Kind() {
+		case reflect.String:
+			target.SetString(env)
+		case reflect.Int64:
+			number, err := strconv.ParseInt(env, 10, 64)
+			if err != nil {
+				return nil, Errorf(KindValidation, "invalid value for %s: %v", name, err)
+			}
+			target.SetInt(number)
+		}
+	}
+	return overrides, nil
+}
+
+// merge returns a copy of config where the non-zero values of other take precedence.
+func (config *Config) merge(other *Config) *Config {
+	merged := *config
+	target := reflect.ValueOf(&merged).Elem()
+	source := reflect.ValueOf(other).Elem()
+	for _, field := range configFields() {
+		if value := source.FieldByIndex(field.Index); !value.IsZero() {
+			target.FieldByIndex(field.Index).Set(value)
+		}
+	}
+	return &merged
+}
+
+// configFields returns the fields holding values of every section.
+func configFields() []reflect.StructField {
+	var fields []reflect.StructField
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(Config{})) {
+		if !field.Anonymous && field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func storedToken() (string, error) {
+	store, err := NewFileCredentialsStore()
+	if err != nil {
+		return "", err
+	}
+	credentials, err := store.Load()
+	if err != nil || credentials == nil {
+		return "", err
+	}
+	return credentials.Token, nil
+}
@@ -2,9 +2,85 @@ package common
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
+// inProjectDir runs the test from an empty directory with a .env file and no user config directory.
+func inProjectDir(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	if err := os.WriteFile(filepath.Join(dir, ".env"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+// newTestConfig builds a Config from its most common values.
+func newTestConfig(token, owner, ollamaModel, qdrantUrl string) Config {
+	return Config{
+		GithubConfig:      GithubConfig{Token: token, Owner: owner},
+		LlmConfig:         LlmConfig{Ollama_Model: ollamaModel},
+		VectorStoreConfig: VectorStoreConfig{Qdrant_Url: qdrantUrl},
+	}
+}
+
+// values drops the bookkeeping of config so it can be compared.
+func values(config *Config) *Config {
+	copied := *config
+	copied.searched = nil
+	copied.profile = ""
+	return &copied
+}
+
+// dotEnvOnly simulates a setup without user config file, only the local .env is loaded by loader.
+func dotEnvOnly(loader ConfigLoader) ConfigLoader {
+	return func(path string, config interface{}) error {
+		if path != ".env" {
+			return os.ErrNotExist
+		}
+		return loader(path, &config.(*FileConfig).Config)
+	}
+}
+
+func TestNewConfig_StoredToken(t *testing.T) {
+	inProjectDir(t)
+	store, err := NewFileCredentialsStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Save(&Credentials{Token: "storedToken"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cachedConfig = nil
+	config, err := NewConfig(dotEnvOnly(func(path string, config interface{}) error {
+		cfg := config.(*Config)
+		cfg.Owner = "validOwner"
+		cfg.Ollama_Model = "validModel"
+		cfg.Qdrant_Url = "validQdrantUrl"
+		return nil
+	}))
+	cachedConfig = nil
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	if config.Token != "storedToken" {
+		t.Errorf("NewConfig() Token = %s, expected storedToken", config.Token)
+	}
+}
+
 func TestNewConfig(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -68,6 +144,38 @@ func TestNewConfig(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "Valid GitHub App configuration",
+			mockConfigLoader: func(path string, config interface{}) error {
+				cfg, ok := config.(*Config)
+				if !ok {
+					return errors.New("invalid config type")
+				}
+				cfg.Owner = "validOwner"
+				cfg.Ollama_Model = "validModel"
+				cfg.Qdrant_Url = "validQdrantUrl"
+				cfg.App_Id = 1
+				cfg.App_Installation_Id = 2
+				cfg.App_Private_Key = "app.pem"
+				return nil
+			},
+			expectedError: false,
+		},
+		{
+			name: "Incomplete GitHub App configuration",
+			mockConfigLoader: func(path string, config interface{}) error {
+				cfg, ok := config.(*Config)
+				if !ok {
+					return errors.New("invalid config type")
+				}
+				cfg.Owner = "validOwner"
+				cfg.Ollama_Model = "validModel"
+				cfg.Qdrant_Url = "validQdrantUrl"
+				cfg.App_Id = 1
+				return nil
+			},
+			expectedError: true,
+		},
 		{
 			name: "ConfigLoader error",
 			mockConfigLoader: func(path string, config interface{}) error {
@@ -79,14 +187,336 @@ func TestNewConfig(t *testing.T) {
 
 	// Reset cachedConfig for testing
 	cachedConfig = nil
+	// Isolate from config files and credentials stored by `auth login`
+	inProjectDir(t)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cachedConfig = nil // Ensure cachedConfig is nil for each test
-			_, err := NewConfig(tt.mockConfigLoader)
+			config, err := NewConfig(dotEnvOnly(tt.mockConfigLoader))
+			if err == nil {
+				err = config.Validate()
+			}
 			if (err != nil) != tt.expectedError {
 				t.Errorf("NewConfig() error = %v, expectedError %v", err, tt.expectedError)
 			}
 		})
 	}
 }
+
+func profilesLoader(dotEnv Config, userFile FileConfig) ConfigLoader {
+	return func(path string, config interface{}) error {
+		cfg := config.(*FileConfig)
+		if path == ".env" {
+			cfg.Config = dotEnv
+		} else {
+			*cfg = userFile
+		}
+		return nil
+	}
+}
+
+func TestNewConfig_Profiles(t *testing.T) {
+	inProjectDir(t)
+	profiles := FileConfig{
+		Config:  newTestConfig("", "", "defaultModel", "defaultQdrantUrl"),
+		Profile: "work",
+		Profiles: map[string]Config{
+			"work": newTestConfig("workToken", "company", "", ""),
+			"utn":  newTestConfig("utnToken", "utn", "utnModel", ""),
+		},
+	}
+	tests := []struct {
+		name          string
+		flag          string
+		env           string
+		tokenEnv      string
+		dotEnv        Config
+		expected      Config
+		expectedError bool
+	}{
+		{
+			name:     "Stored profile",
+			expected: newTestConfig("workToken", "company", "defaultModel", "defaultQdrantUrl"),
+		},
+		{
+			name:     "Environment variable wins over stored profile",
+			env:      "utn",
+			expected: newTestConfig("utnToken", "utn", "utnModel", "defaultQdrantUrl"),
+		},
+		{
+			name:     "Flag wins over environment variable",
+			flag:     "work",
+			env:      "utn",
+			expected: newTestConfig("workToken", "company", "defaultModel", "defaultQdrantUrl"),
+		},
+		{
+			name:     "Project .env wins over user file top level values",
+			dotEnv:   newTestConfig("", "", "", "localQdrantUrl"),
+			expected: newTestConfig("workToken", "company", "defaultModel", "localQdrantUrl"),
+		},
+		{
+			name:     "Key environment variable wins over everything",
+			tokenEnv: "envToken",
+			expected: newTestConfig("envToken", "company", "defaultModel", "defaultQdrantUrl"),
+		},
+		{
+			name:          "Unknown profile",
+			flag:          "personal",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cachedConfig = nil
+			SelectedProfile = tt.flag
+			t.Setenv(ProfileEnvVar, tt.env)
+			t.Setenv("GITHUB_CLI_TOKEN", tt.tokenEnv)
+			defer func() {
+				cachedConfig = nil
+				SelectedProfile = ""
+			}()
+
+			config, err := NewConfig(profilesLoader(tt.dotEnv, profiles))
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, &tt.expected, values(config))
+			}
+		})
+	}
+}
+
+func TestConfigFiles(t *testing.T) {
+	dir := inProjectDir(t)
+	userFile := filepath.Join(dir, "config", AppName, "config.yaml")
+
+	files, err := ConfigFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{userFile, ".env"}, files)
+
+	// A project file in a parent directory is preferred over .env
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFileName), nil, 0600))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0700))
+	assert.NoError(t, os.Chdir(filepath.Join(dir, "sub")))
+	files, err = ConfigFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{userFile, filepath.Join(dir, ProjectConfigFileName)}, files)
+
+	// An explicit file disables discovery, the flag wins over the environment variable
+	t.Setenv(ConfigEnvVar, "from-env.yaml")
+	files, err = ConfigFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"from-env.yaml"}, files)
+
+	SelectedConfigFile = "from-flag.yaml"
+	defer func() { SelectedConfigFile = "" }()
+	files, err = ConfigFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"from-flag.yaml"}, files)
+}
+
+func TestLoadConfigFiles_MissingExplicitFile(t *testing.T) {
+	inProjectDir(t)
+	t.Setenv(ConfigEnvVar, "missing.yaml")
+	_, err := LoadConfigFiles(func(path string, config interface{}) error {
+		return os.ErrNotExist
+	})
+	assert.Error(t, err)
+}
+
+func TestEnvOverrides(t *testing.T) {
+	t.Setenv("GITHUB_CLI_OWNER", "envOwner")
+	t.Setenv("GITHUB_CLI_APP_ID", "12")
+	overrides, err := envOverrides()
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{GithubConfig: GithubConfig{Owner: "envOwner", App_Id: 12}}, overrides)
+
+	t.Setenv("GITHUB_CLI_APP_ID", "twelve")
+	_, err = envOverrides()
+	assert.Error(t, err)
+}
+
+func TestUseProfile(t *testing.T) {
+	inProjectDir(t)
+	loader := profilesLoader(Config{}, FileConfig{Profiles: map[string]Config{"work": {}}})
+	var stored map[string]any
+	updater := func(path string, update func(values map[string]any) error) error {
+		stored = map[string]any{}
+		return update(stored)
+	}
+
+	assert.NoError(t, UseProfile(loader, updater, "work"))
+	assert.Equal(t, map[string]any{"profile": "work"}, stored)
+
+	stored = nil
+	assert.Error(t, UseProfile(loader, updater, "personal"))
+	assert.Nil(t, stored)
+}
+
+func TestConfig_Sections(t *testing.T) {
+	inProjectDir(t)
+	cachedConfig = nil
+	defer func() { cachedConfig = nil }()
+	// Only the GitHub values are configured, other sections fail only when requested
+	config, err := NewConfig(dotEnvOnly(func(path string, config interface{}) error {
+		*config.(*Config) = newTestConfig("validToken", "validOwner", "", "")
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	github, err := config.Github()
+	assert.NoError(t, err)
+	assert.Equal(t, "validOwner", github.Owner)
+
+	_, err = config.Llm()
+	var missing *MissingKeyError
+	if assert.ErrorAs(t, err, &missing) {
+		assert.Equal(t, "ollama_model", missing.Key)
+		assert.Equal(t, []string{"$GITHUB_CLI_OLLAMA_MODEL", filepath.Join(os.Getenv("XDG_CONFIG_HOME"), AppName, "config.yaml"), ".env"}, missing.Searched)
+	}
+
+	// Without qdrant_url contexts are kept in the local store
+	vectorStore, err := config.VectorStore()
+	assert.NoError(t, err)
+	assert.Equal(t, VectorStoreLocal, vectorStore.Kind())
+	assert.Len(t, config.FieldErrors(), 1)
+
+	config.Vector_Store = "qdrant"
+	_, err = config.VectorStore()
+	if assert.ErrorAs(t, err, &missing) {
+		assert.Equal(t, "qdrant_url", missing.Key)
+	}
+	config.Vector_Store = "chroma"
+	_, err = config.VectorStore()
+	assert.ErrorContains(t, err, `invalid configuration value vector_store: "chroma"`)
+}
+
+func TestLocalStoreDir(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+
+	dir, err := (&VectorStoreConfig{}).LocalStoreDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dataDir, AppName, "vectors"), dir)
+
+	dir, err = (&VectorStoreConfig{Vector_Store_Dir: "/data/vectors"}).LocalStoreDir()
+	assert.NoError(t, err)
+	assert.Equal(t, "/data/vectors", dir)
+}
+
+func TestManifestDir(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+
+	dir, err := (&VectorStoreConfig{Vector_Store_Dir: "/data/vectors"}).ManifestDir()
+	assert.NoError(t, err)
+	assert.Equal(t, "/data/vectors/manifests", dir)
+
+	first, err := (&VectorStoreConfig{Qdrant_Url: "http://localhost:6333"}).ManifestDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dataDir, AppName, "manifests"), filepath.Dir(first))
+	second, _ := (&VectorStoreConfig{Qdrant_Url: "http://localhost:6333/"}).ManifestDir()
+	assert.Equal(t, first, second)
+	other, _ := (&VectorStoreConfig{Qdrant_Url: "http://qdrant:6333"}).ManifestDir()
+	assert.NotEqual(t, first, other)
+}
+
+func TestChunkSizes(t *testing.T) {
+	sizes, err := (&VectorStoreConfig{}).ChunkSizes()
+	assert.NoError(t, err)
+	assert.Equal(t, ChunkSize{Size: 300, Overlap: 30}, sizes[LanguageDefault])
+	assert.Equal(t, ChunkSize{Size: 1500, Overlap: 100}, sizes[LanguageGo])
+
+	sizes, err = (&VectorStoreConfig{Chunk_Sizes: "go=2000/0, Python=1200,default=500/50"}).ChunkSizes()
+	assert.NoError(t, err)
+	assert.Equal(t, ChunkSize{Size: 2000}, sizes[LanguageGo])
+	assert.Equal(t, ChunkSize{Size: 1200, Overlap: 100}, sizes[LanguagePython])
+	assert.Equal(t, ChunkSize{Size: 500, Overlap: 50}, sizes[LanguageDefault])
+	assert.Equal(t, ChunkSize{Size: 1500, Overlap: 100}, sizes[LanguageJava])
+
+	for _, value := range []string{"rust=100", "go", "go=big", "go=100/100", "go=0", "go=100/-1"} {
+		_, err = (&VectorStoreConfig{Chunk_Sizes: value}).ChunkSizes()
+		assert.Equal(t, KindValidation, KindOf(err), value)
+		assert.ErrorContains(t, err, "chunk_sizes", value)
+	}
+
+	_, err = (&Config{VectorStoreConfig: VectorStoreConfig{Chunk_Sizes: "go=1/2"}}).VectorStore()
+	assert.ErrorContains(t, err, "invalid configuration value chunk_sizes")
+}
+
+func TestConfig_GithubMissingToken(t *testing.T) {
+	inProjectDir(t)
+	SelectedProfile = "work"
+	defer func() { SelectedProfile = "" }()
+	config, err := ResolveConfig(profilesLoader(Config{}, FileConfig{Profiles: map[string]Config{"work": {}}}))
+	assert.NoError(t, err)
+	assert.Equal(t, "work", config.ProfileName())
+
+	_, err = config.Github()
+	var missing *MissingKeyError
+	if assert.ErrorAs(t, err, &missing) {
+		assert.Equal(t, "owner", missing.Key)
+	}
+	assert.ErrorContains(t, err, "missing required configuration value token for GitHub commands")
+	assert.ErrorContains(t, err, `profile "work"`)
+	assert.ErrorContains(t, err, "credentials.json (auth login)")
+}
+
+func TestConfig_BadStoredToken(t *testing.T) {
+	inProjectDir(t)
+	store, err := NewFileCredentialsStore()
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(store.path), 0700))
+	assert.NoError(t, os.WriteFile(store.path, []byte("{not json"), 0600))
+
+	// Commands not calling GitHub still run
+	config, err := ResolveConfig(profilesLoader(Config{GithubConfig: GithubConfig{Owner: "acme"}, LlmConfig: LlmConfig{Ollama_Model: "llama3.2"}}, FileConfig{}))
+	assert.NoError(t, err)
+	_, err = config.Llm()
+	assert.NoError(t, err)
+
+	_, err = config.Github()
+	assert.ErrorContains(t, err, "missing required configuration value token for GitHub commands")
+	assert.ErrorContains(t, err, "cannot read the token stored by auth login: failed to parse credentials file")
+}
+
+func TestConfig_LlmProviders(t *testing.T) {
+	tests := []struct {
+		name         string
+		llm          LlmConfig
+		expectedKeys []string
+		invalid      bool
+	}{
+		{"Ollama by default", LlmConfig{Ollama_Model: "llama3.2"}, nil, false},
+		{"Ollama without model", LlmConfig{Openai_Model: "qwen2.5-coder"}, []string{"ollama_model"}, false},
+		{"OpenAI compatible", LlmConfig{Llm_Provider: "openai", Openai_Url: "http://localhost:8080/v1", Openai_Model: "qwen2.5-coder"}, nil, false},
+		{"OpenAI compatible without url and model", LlmConfig{Llm_Provider: "OpenAI"}, []string{"openai_url", "openai_model"}, false},
+		{"Anthropic without model", LlmConfig{Llm_Provider: "anthropic"}, []string{"anthropic_model"}, false},
+		{"Unknown provider", LlmConfig{Llm_Provider: "gemini"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{LlmConfig: tt.llm}
+
+			_, err := config.Llm()
+
+			var keys []string
+			for _, problem := range config.FieldErrors() {
+				var missing *MissingKeyError
+				if errors.As(problem, &missing) && missing.Section == "AI commands" {
+					keys = append(keys, missing.Key)
+				}
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+			if tt.invalid {
+				assert.ErrorContains(t, err, `invalid configuration value llm_provider: "gemini"`)
+			} else {
+				assert.Equal(t, tt.expectedKeys == nil, err == nil)
+			}
+		})
+	}
+}
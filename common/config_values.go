@@ -0,0 +1,129 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ConfigKeys returns the keys accepted in config files, e.g. token or ollama_model.
+func ConfigKeys() []string {
+	fields := configFields()
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, strings.ToLower(field.Name))
+	}
+	return keys
+}
+
+// IsSecretKey reports whether the value of key must never be printed.
+func IsSecretKey(key string) bool {
+	field, ok := configField(key)
+	return ok && field.Tag.Get("secret") == "true"
+}
+
+// Redact hides a secret value keeping only a short prefix to tell tokens apart.
+func Redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) <= 8 {
+		return "********"
+	}
+	return value[:4] + "********"
+}
+
+// Get returns the value of key formatted as a string, secrets are redacted.
+func (config *Config) Get(key string) (string, error) {
+	field, ok := configField(key)
+	if !ok {
+		return "", unknownKey(key)
+	}
+	value := reflect.ValueOf(config).Elem().FieldByIndex(field.Index)
+	if value.IsZero() {
+		return "", nil
+	}
+	formatted := fmt.Sprint(value.Interface())
+	if IsSecretKey(key) {
+		return Redact(formatted), nil
+	}
+	return formatted, nil
+}
+
+// SetConfigValue stores key in the writable config file, inside the profile selected with --profile if any.
+func SetConfigValue(configUpdater ConfigUpdater, key, value string) error {
+	return SetConfigValues(configUpdater, map[string]string{key: value})
+}
+
+// SetConfigValues stores several keys at once, nothing is written when any of them is invalid.
+func SetConfigValues(configUpdater ConfigUpdater, values map[string]string) error {
+	typed := make(map[string]any, len(values))
+	for key, value := range values {
+		field, ok := configField(key)
+		if !ok {
+			return unknownKey(key)
+		}
+		typed[strings.ToLower(key)] = value
+		if field.Type.Kind() == reflect.Int64 {
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return Errorf(KindValidation, "invalid value for %s: %v", key, err)
+			}
+			typed[strings.ToLower(key)] = number
+		}
+	}
+	return updateConfigSection(configUpdater, func(section map[string]any) {
+		for key, value := range typed {
+			section[key] = value
+		}
+	})
+}
+
+// UnsetConfigValue removes key from the writable config file, inside the profile selected with --profile if any.
+func UnsetConfigValue(configUpdater ConfigUpdater, key string) error {
+	if _, ok := configField(key); !ok {
+		return unknownKey(key)
+	}
+	return updateConfigSection(configUpdater, func(section map[string]any) {
+		delete(section, strings.ToLower(key))
+	})
+}
+
+func updateConfigSection(configUpdater ConfigUpdater, update func(section map[string]any)) error {
+	path, err := WritableConfigFile()
+	if err != nil {
+		return err
+	}
+	return configUpdater(path, func(values map[string]any) error {
+		if SelectedProfile == "" {
+			update(values)
+			return nil
+		}
+		profiles, _ := values["profiles"].(map[string]any)
+		if profiles == nil {
+			profiles = map[string]any{}
+			values["profiles"] = profiles
+		}
+		section, _ := profiles[SelectedProfile].(map[string]any)
+		if section == nil {
+			section = map[string]any{}
+			profiles[SelectedProfile] = section
+		}
+		update(section)
+		return nil
+	})
+}
+
+func configField(key string) (reflect.StructField, bool) {
+	for _, field := range configFields() {
+		if strings.EqualFold(field.Name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func unknownKey(key string) error {
+	return Errorf(KindValidation, "unknown configuration key %q, valid keys are: %s", key, strings.Join(ConfigKeys(), ", "))
+}
@@ -0,0 +1,62 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Get(t *testing.T) {
+	config := &Config{GithubConfig: GithubConfig{Token: "ghp_1234567890", Owner: "acme", App_Id: 12}}
+
+	value, err := config.Get("owner")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", value)
+
+	value, err = config.Get("TOKEN")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghp_********", value, "tokens must be redacted")
+
+	value, err = config.Get("app_id")
+	assert.NoError(t, err)
+	assert.Equal(t, "12", value)
+
+	value, err = config.Get("qdrant_url")
+	assert.NoError(t, err)
+	assert.Empty(t, value)
+
+	_, err = config.Get("unknown")
+	assert.ErrorContains(t, err, "valid keys are")
+}
+
+func TestSetAndUnsetConfigValue(t *testing.T) {
+	inProjectDir(t)
+	stored := map[string]any{}
+	updater := func(path string, update func(values map[string]any) error) error {
+		return update(stored)
+	}
+
+	assert.NoError(t, SetConfigValue(updater, "owner", "acme"))
+	assert.NoError(t, SetConfigValue(updater, "app_id", "12"))
+	assert.Equal(t, map[string]any{"owner": "acme", "app_id": int64(12)}, stored)
+
+	assert.Error(t, SetConfigValue(updater, "app_id", "twelve"))
+	assert.Error(t, SetConfigValue(updater, "unknown", "value"))
+
+	assert.NoError(t, UnsetConfigValue(updater, "app_id"))
+	assert.Equal(t, map[string]any{"owner": "acme"}, stored)
+
+	// With --profile the value goes into the profile section
+	SelectedProfile = "utn"
+	defer func() { SelectedProfile = "" }()
+	assert.NoError(t, SetConfigValue(updater, "owner", "utn-frba"))
+	assert.Equal(t, map[string]any{"owner": "acme", "profiles": map[string]any{"utn": map[string]any{"owner": "utn-frba"}}}, stored)
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "", Redact(""))
+	assert.Equal(t, "********", Redact("short"))
+	assert.Equal(t, "gho_********", Redact("gho_abcdefghijkl"))
+	assert.True(t, IsSecretKey("token"))
+	assert.False(t, IsSecretKey("owner"))
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const AppName = "github-cli"
+
+// Credentials obtained through `auth login`
+type Credentials struct {
+	Token  string `json:"token"`
+	Login  string `json:"login"`
+	Scopes string `json:"scopes"`
+}
+
+type ICredentialsStore interface {
+	Load() (*Credentials, error)
+	Save(credentials *Credentials) error
+	Delete() error
+}
+
+// ConfigDir returns the directory where the CLI keeps its user level configuration.
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, AppName), nil
+}
+
+// DataDir returns the directory where the CLI keeps its user level data: $XDG_DATA_HOME/github-cli,
+// ~/.local/share/github-cli, or the application data directory on macOS and Windows.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, AppName), nil
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, AppName), nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Application Support", AppName), nil
+	}
+	return filepath.Join(home, ".local", "share", AppName), nil
+}
+
+// FileCredentialsStore keeps the credentials in a JSON file only readable by the current user.
+type FileCredentialsStore struct {
+	path string
+}
+
+func NewFileCredentialsStore() (*FileCredentialsStore, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &FileCredentialsStore{path: filepath.Join(dir, "credentials.json")}, nil
+}
+
+// Load returns the stored credentials or nil when the user never logged in.
+func (store *FileCredentialsStore) Load() (*Credentials, error) {
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	credentials := &Credentials{}
+	if err = json.Unmarshal(data, credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file %s: %w", store.path, err)
+	}
+	return credentials, nil
+}
+
+func (store *FileCredentialsStore) Save(credentials *Credentials) error {
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so a failure never leaves a truncated credentials file
+	tmp := store.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	// Enforce permissions in case the file already existed with a wider mode
+	if err = os.Chmod(tmp, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, store.path)
+}
+
+func (store *FileCredentialsStore) Delete() error {
+	err := os.Remove(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCredentialsStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store, err := NewFileCredentialsStore()
+	assert.NoError(t, err)
+
+	credentials, err := store.Load()
+	assert.NoError(t, err)
+	assert.Nil(t, credentials, "no credentials expected before login")
+
+	err = store.Save(&Credentials{Token: "gho_token", Login: "user1", Scopes: "repo"})
+	assert.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), AppName, "credentials.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	credentials, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, &Credentials{Token: "gho_token", Login: "user1", Scopes: "repo"}, credentials)
+
+	assert.NoError(t, store.Delete())
+	credentials, err = store.Load()
+	assert.NoError(t, err)
+	assert.Nil(t, credentials)
+
+	// Deleting twice is not an error
+	assert.NoError(t, store.Delete())
+}
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrorKind classifies the errors reported to the user, each kind exits with its own code.
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindValidation
+	KindAuth
+	KindNotFound
+	KindRateLimited
+	KindNetwork
+	KindAiBackend
+)
+
+// Exit codes of the CLI, plugins keep their own.
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitValidation  = 2
+	ExitAuth        = 3
+	ExitNotFound    = 4
+	ExitRateLimited = 5
+	ExitNetwork     = 6
+	ExitAiBackend   = 7
+)
+
+// ExitCode returns the exit code of the errors of kind.
+func (kind ErrorKind) ExitCode() int {
+	switch kind {
+	case KindValidation:
+		return ExitValidation
+	case KindAuth:
+		return ExitAuth
+	case KindNotFound:
+		return ExitNotFound
+	case KindRateLimited:
+		return ExitRateLimited
+	case KindNetwork:
+		return ExitNetwork
+	case KindAiBackend:
+		return ExitAiBackend
+	}
+	return ExitError
+}
+
+// Error is an error of a known kind. The message is what the user reads, the cause is
+// only shown with --verbose unless the message already includes it.
+type Error struct {
+	kind    ErrorKind
+	message string
+	err     error
+}
+
+// NewError returns an error of kind with message.
+func NewError(kind ErrorKind, message string) *Error {
+	return &Error{kind: kind, message: message}
+}
+
+// Errorf returns an error of kind formatted like fmt.Errorf does, %w keeps the cause.
+func Errorf(kind ErrorKind, format string, args ...any) error {
+	return &Error{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// WrapError returns an error of kind showing message, err is kept as the cause.
+func WrapError(kind ErrorKind, err error, message string) error {
+	return &Error{kind: kind, message: message, err: err}
+}
+
+// WithKind classifies err as kind keeping its message. Errors already classified keep their kind.
+func WithKind(kind ErrorKind, err error) error {
+	if err == nil || KindOf(err) != KindUnknown {
+		return err
+	}
+	return &Error{kind: kind, err: err}
+}
+
+func (e *Error) Error() string {
+	if e.message == "" && e.err != nil {
+		return e.err.Error()
+	}
+	return e.message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+func (e *Error) Kind() ErrorKind {
+	return e.kind
+}
+
+// KindOf returns the kind of the outermost classified error in the chain of err.
+func KindOf(err error) ErrorKind {
+	var classified interface{ Kind() ErrorKind }
+	if errors.As(err, &classified) {
+		return classified.Kind()
+	}
+	return KindUnknown
+}
+
+// ExitCode returns the exit code for err, ExitOK when it is nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	return KindOf(err).ExitCode()
+}
+
+// IsNetworkError reports whether err comes from failing to reach a server.
+func IsNetworkError(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// Describe returns the message of err followed, when verbose, by the causes it does not
+// already include.
+func Describe(err error, verbose bool) string {
+	message := err.Error()
+	if !verbose {
+		return message
+	}
+	shown := message
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		text := cause.Error()
+		if strings.Contains(shown, text) {
+			continue
+		}
+		message += "\n  caused by: " + text
+		shown += "\n" + text
+	}
+	return message
+}
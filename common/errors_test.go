@@ -0,0 +1,57 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	notFound := WrapError(KindNotFound, errors.New("GET /repos/utn/tp1: 404 Not Found"), "repository utn/tp1 not found")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"No error", nil, ExitOK},
+		{"Unclassified", errors.New("boom"), ExitError},
+		{"Validation", NewError(KindValidation, "owner is required"), ExitValidation},
+		{"Missing key", &MissingKeyError{Key: "owner", Section: "github"}, ExitValidation},
+		{"Wrapped", fmt.Errorf("Error while trying to list collaborators: %w", notFound), ExitNotFound},
+		{"Outermost kind wins", WrapError(KindAiBackend, WrapError(KindNetwork, errors.New("refused"), "cannot reach"), "ai"), ExitAiBackend},
+		{"Rate limited", NewError(KindRateLimited, "rate limit"), ExitRateLimited},
+		{"Auth", Errorf(KindAuth, "token_env: environment variable %s is not set", "GH"), ExitAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestWithKind(t *testing.T) {
+	err := WithKind(KindAiBackend, errors.New("model not found"))
+	assert.EqualError(t, err, "model not found")
+	assert.Equal(t, KindAiBackend, KindOf(err))
+
+	err = WithKind(KindAiBackend, NewError(KindValidation, "invalid"))
+	assert.Equal(t, KindValidation, KindOf(err))
+	assert.Nil(t, WithKind(KindAiBackend, nil))
+}
+
+func TestDescribe(t *testing.T) {
+	cause := &url.Error{Op: "Get", URL: "https://api.github.com/user", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	err := fmt.Errorf("Error while trying to list repositories: %w", WrapError(KindNetwork, cause, "cannot reach GitHub"))
+
+	assert.Equal(t, "Error while trying to list repositories: cannot reach GitHub", Describe(err, false))
+	assert.Equal(t, "Error while trying to list repositories: cannot reach GitHub\n"+
+		"  caused by: Get \"https://api.github.com/user\": dial tcp: connection refused", Describe(err, true))
+	assert.True(t, IsNetworkError(cause))
+	assert.False(t, IsNetworkError(errors.New("connection refused")))
+}
@@ -3,14 +3,19 @@ package common
 import (
 	"fmt"
 	"github.com/ffumaneri/github-cli/concurrency"
+	"log/slog"
+	"math"
 	"os"
-	"time"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 type WalkDirCallback func(path string, size int64)
 
 type IFS interface {
-	WalkDir(path string, callback WalkDirCallback) error
+	// WalkDir calls callback for every file below path, nil filter visiting all of them.
+	WalkDir(path string, filter *WalkFilter, callback WalkDirCallback) error
 }
 
 const DefaultMaxLevel = 4
@@ -19,56 +24,144 @@ type FS struct {
 	maxLevel int
 }
 
-func (fs *FS) WalkDir(path string, callback WalkDirCallback) error {
+// walk is the state of a WalkDir shared by the directories read concurrently.
+type walk struct {
+	wp       *concurrency.WorkerPool
+	pending  sync.WaitGroup
+	callback WalkDirCallback
+	filter   WalkFilter
+	include  ignoreRules
+	exclude  ignoreRules
+}
+
+// WalkDir calls callback for every file below path, reading directories concurrently,
+// and returns once all of them were visited. Directories that cannot be read are logged
+// and skipped, .git directories are always skipped. Directories nested deeper than the
+// maximum level are skipped as SkipTooDeep.
+func (fs *FS) WalkDir(path string, filter *WalkFilter, callback WalkDirCallback) error {
 	if fs.maxLevel == 0 {
 		fs.maxLevel = DefaultMaxLevel
 	}
-	wp := concurrency.NewWorkerPool(10)
-	wp.Start()
-	wp.AddTask(concurrency.Executor{func() error {
-		return fs.walkDir(wp, path, callback, 0)
-	}, func(err error) {
-		fmt.Println(err)
-	}})
-	wp.WaitForTimeout(1 * time.Millisecond)
+	info, err := os.Stat(path)
+	if err != nil {
+		return WithKind(KindValidation, err)
+	}
+	if !info.IsDir() {
+		return Errorf(KindValidation, "%s is not a directory", path)
+	}
+	w := &walk{callback: callback}
+	if filter != nil {
+		if err = filter.Validate(); err != nil {
+			return err
+		}
+		w.filter = *filter
+		w.include = globs(path, filter.Include)
+		w.exclude = globs(path, filter.Exclude)
+	}
+	w.wp = concurrency.NewWorkerPool(10)
+	w.wp.Start()
+	defer w.wp.Stop()
+	// Completion is tracked with pending, the results of the pool are only drained
+	go func() {
+		for {
+			select {
+			case <-w.wp.Results:
+			case <-w.wp.Quit:
+				return
+			}
+		}
+	}()
+	gitDir, _ := parseIgnorePattern(path, ".git/")
+	fs.addDir(w, path, ignoreRules{gitDir}, 0)
+	w.pending.Wait()
 	return nil
 }
 
-func (fs *FS) walkDir(wp *concurrency.WorkerPool, path string, callback WalkDirCallback, level int) error {
-	if level > fs.maxLevel {
-		return nil
-	}
-	f, err := os.Open(path)
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
-
-		}
-	}(f)
+// addDir queues the walk of path without blocking, so workers adding the directories they
+// find never wait for a full queue only workers would empty.
+func (fs *FS) addDir(w *walk, path string, rules ignoreRules, level int) {
+	w.pending.Add(1)
+	go w.wp.AddTask(concurrency.Executor{
+		Execute: func() error {
+			// Logged before Done, the pool may be stopped as soon as nothing is pending
+			defer w.pending.Done()
+			if err := fs.walkDir(w, path, rules, level); err != nil {
+				slog.Warn("cannot read directory", LogFile, path, "error", err)
+			}
+			return nil
+		},
+	})
+}
 
+func (fs *FS) walkDir(w *walk, path string, rules ignoreRules, level int) error {
+	f, err := os.Open(path)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	files, err := f.Readdir(0)
 	if err != nil {
 		return err
 	}
-	var children []string
+	rules = rules.with(path, w.filter.IgnoreFiles)
 	for _, file := range files {
+		name := fmt.Sprintf("%s%s%s", path, "/", file.Name())
+		reason := w.skip(name, file.IsDir(), rules)
+		if reason == "" && file.IsDir() && level >= fs.maxLevel {
+			reason = SkipTooDeep
+		}
+		if reason != "" {
+			if w.filter.Skipped != nil {
+				w.filter.Skipped(name, reason)
+			}
+			continue
+		}
 		if !file.IsDir() {
-			callback(fmt.Sprintf("%s%s%s", path, "/", file.Name()), file.Size())
+			w.callback(name, file.Size())
 		} else {
-			children = append(children, fmt.Sprintf("%s%s%s", path, "/", file.Name()))
+			fs.addDir(w, name, rules, level+1)
+		}
+	}
+	return nil
+}
+
+// skip returns why the entry is not visited, empty when it is.
+func (w *walk) skip(name string, isDir bool, rules ignoreRules) string {
+	if rules.ignored(name, isDir) {
+		return SkipIgnored
+	}
+	for _, pattern := range w.exclude {
+		if pattern.match(name, isDir) {
+			return SkipExcluded
 		}
 	}
-	for _, child := range children {
-		wp.AddTask(concurrency.Executor{func() error {
-			return fs.walkDir(wp, child, callback, level+1)
-		}, func(err error) {
-			fmt.Println(err)
-		}})
+	if isDir || len(w.include) == 0 {
+		return ""
 	}
+	for _, pattern := range w.include {
+		if pattern.match(name, false) {
+			return ""
+		}
+	}
+	return SkipExcluded
+}
 
-	return nil
+// ParseFileSize reads sizes like 512, 300KB, 1.5MB or 2G, in bytes with units of 1024.
+func ParseFileSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	number = strings.TrimSuffix(number, "B")
+	multiplier := int64(1)
+	for i, unit := range []string{"K", "M", "G"} {
+		if strings.HasSuffix(number, unit) {
+			number = strings.TrimSuffix(number, unit)
+			multiplier = 1 << (10 * (i + 1))
+			break
+		}
+	}
+	size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || !(size >= 0) || math.IsInf(size, 1) {
+		return 0, Errorf(KindValidation, "invalid size %q, use bytes or a unit like 500KB or 1MB", value)
+	}
+	return int64(size * float64(multiplier)), nil
 }
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFS_WalkDir(t *testing.T) {
+	root := t.TempDir()
+	var expected []string
+	// More directories than the pool queues, every one of them must be visited
+	for i := 0; i < 300; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("dir%03d", i))
+		assert.NoError(t, os.MkdirAll(dir, 0700))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0600))
+		expected = append(expected, root+"/"+fmt.Sprintf("dir%03d", i)+"/main.go")
+	}
+	deep := filepath.Join(root, "a", "b", "c")
+	assert.NoError(t, os.MkdirAll(deep, 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(deep, "deep.go"), []byte("package c"), 0600))
+	expected = append(expected, root+"/a/b/c/deep.go")
+	sort.Strings(expected)
+
+	var mu sync.Mutex
+	var visited []string
+	fs := &FS{maxLevel: 3}
+	err := fs.WalkDir(root, nil, func(path string, size int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		visited = append(visited, path)
+	})
+
+	assert.NoError(t, err)
+	sort.Strings(visited)
+	assert.Equal(t, expected, visited)
+
+	fs = &FS{maxLevel: 2}
+	visited = nil
+	var skipped []string
+	assert.NoError(t, fs.WalkDir(root, &WalkFilter{Skipped: func(path, reason string) {
+		mu.Lock()
+		defer mu.Unlock()
+		skipped = append(skipped, reason+" "+path)
+	}}, func(path string, size int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		visited = append(visited, path)
+	}))
+	assert.Len(t, visited, 300)
+	assert.Equal(t, []string{SkipTooDeep + " " + root + "/a/b/c"}, skipped)
+}
+
+func TestFS_WalkDir_Invalid(t *testing.T) {
+	fs := &FS{}
+	err := fs.WalkDir(filepath.Join(t.TempDir(), "missing"), nil, func(string, int64) {})
+	assert.Error(t, err)
+	assert.Equal(t, KindValidation, KindOf(err))
+
+	file := filepath.Join(t.TempDir(), "main.go")
+	assert.NoError(t, os.WriteFile(file, nil, 0600))
+	assert.ErrorContains(t, fs.WalkDir(file, nil, func(string, int64) {}), "is not a directory")
+}
+
+func TestParseFileSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"300KB", 300 << 10},
+		{"1mb", 1 << 20},
+		{"1.5M", 3 << 19},
+		{"2G", 2 << 30},
+	}
+	for _, tt := range tests {
+		size, err := ParseFileSize(tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.expected, size, tt.value)
+	}
+	for _, value := range []string{"", "MB", "-1", "1TB", "NaN", "Inf"} {
+		_, err := ParseFileSize(value)
+		assert.Equal(t, KindValidation, KindOf(err), value)
+	}
+}
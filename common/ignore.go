@@ -0,0 +1,186 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// IgnoreFileName is read like a .gitignore, for the files that are tracked but should not
+// be loaded.
+const IgnoreFileName = ".github-cli-ignore"
+
+// Reasons an entry is skipped
+const (
+	SkipIgnored  = "ignored"  // matched by an ignore file
+	SkipExcluded = "excluded" // matched by an exclude glob or by no include glob
+	SkipTooLarge = "too large"
+	SkipBinary   = "binary"
+	SkipTooDeep  = "too deep" // in a directory nested deeper than the walk goes
+)
+
+// WalkFilter selects the entries a walk visits. Globs without a slash match the name of
+// the entry at any level, the others its path relative to the root, where ** matches any
+// number of directories.
+type WalkFilter struct {
+	// IgnoreFiles are the names of the files with ignore rules read in every directory,
+	// applying to its entries like a .gitignore
+	IgnoreFiles []string
+	// Include limits the files visited to the ones matching any of them, when not empty
+	Include []string
+	// Exclude skips the files and directories matching any of them
+	Exclude []string
+	// Skipped, when set, is called with the entries not visited and the reason; a skipped
+	// directory is reported once for everything in it
+	Skipped func(path, reason string)
+}
+
+// Validate checks the include and exclude globs.
+func (filter *WalkFilter) Validate() error {
+	for _, glob := range append(append([]string{}, filter.Include...), filter.Exclude...) {
+		for _, segment := range strings.Split(strings.Trim(glob, "/"), "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return Errorf(KindValidation, "invalid glob %q: %s", glob, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ignorePattern is a line of an ignore file, or a glob, relative to base.
+type ignorePattern struct {
+	base     string
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func parseIgnorePattern(base, line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+	pattern := ignorePattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// \# and \! start patterns with those characters
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A slash anywhere but at the end ties the pattern to base
+	pattern.anchored = strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignorePattern{}, false
+	}
+	pattern.segments = strings.Split(line, "/")
+	return pattern, true
+}
+
+func (pattern ignorePattern) match(name string, isDir bool) bool {
+	if pattern.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(pattern.base, name)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if !pattern.anchored {
+		matched, _ := path.Match(pattern.segments[0], parts[len(parts)-1])
+		return matched
+	}
+	return matchSegments(pattern.segments, parts)
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], parts[0])
+	return matched && matchSegments(pattern[1:], parts[1:])
+}
+
+// ignoreRules are the patterns applying in a directory, from the root down; the last one
+// matching decides.
+type ignoreRules []ignorePattern
+
+func (rules ignoreRules) ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, pattern := range rules {
+		if pattern.match(name, isDir) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+// with returns the rules plus the ones of the ignore files in dir, leaving rules as they
+// are for the other directories using them.
+func (rules ignoreRules) with(dir string, ignoreFiles []string) ignoreRules {
+	for _, ignoreFile := range ignoreFiles {
+		data, err := os.ReadFile(filepath.Join(dir, ignoreFile))
+		if err != nil {
+			continue
+		}
+		rules = rules[:len(rules):len(rules)]
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if pattern, ok := parseIgnorePattern(dir, scanner.Text()); ok {
+				rules = append(rules, pattern)
+			}
+		}
+	}
+	return rules
+}
+
+// globs parses the include or exclude globs of a walk of root.
+func globs(root string, values []string) ignoreRules {
+	var rules ignoreRules
+	for _, value := range values {
+		if pattern, ok := parseIgnorePattern(root, value); ok {
+			pattern.negate = false
+			rules = append(rules, pattern)
+		}
+	}
+	return rules
+}
+
+// IsBinary reports whether content looks binary, having a NUL byte or not being UTF-8 in
+// its first 8000 bytes like git checks.
+func IsBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+		// The cut may split the last character
+		for i := len(content) - 1; i >= len(content)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(content[i]) {
+				if !utf8.FullRune(content[i:]) {
+					content = content[:i]
+				}
+				break
+			}
+		}
+	}
+	return bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content)
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnorePattern(t *testing.T) {
+	tests := []struct {
+		line     string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"*.log", "/src/build.log", false, true},
+		{"*.log", "/src/logs/build.log", false, true},
+		{"*.log", "/src/build.go", false, false},
+		{"node_modules/", "/src/web/node_modules", true, true},
+		{"node_modules/", "/src/node_modules", false, false},
+		{"/dist", "/src/dist", true, true},
+		{"/dist", "/src/web/dist", true, false},
+		{"web/*.go", "/src/web/api.go", false, true},
+		{"web/*.go", "/src/web/v1/api.go", false, false},
+		{"**/gen", "/src/web/v1/gen", true, true},
+		{"web/**/*.go", "/src/web/api.go", false, true},
+		{"web/**/*.go", "/src/web/v1/v2/api.go", false, true},
+		{"web/**", "/src/web/v1/api.go", false, true},
+		{`\#notes`, "/src/#notes", false, true},
+		{"*.log", "/other/build.log", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line+" "+tt.path, func(t *testing.T) {
+			pattern, ok := parseIgnorePattern("/src", tt.line)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, pattern.match(tt.path, tt.isDir))
+		})
+	}
+
+	for _, line := range []string{"", "# comment", "   ", "/"} {
+		_, ok := parseIgnorePattern("/src", line)
+		assert.False(t, ok, line)
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	var rules ignoreRules
+	for _, line := range []string{"*.go", "!main.go", "web/main.go"} {
+		pattern, _ := parseIgnorePattern("/src", line)
+		rules = append(rules, pattern)
+	}
+	assert.True(t, rules.ignored("/src/api.go", false))
+	assert.False(t, rules.ignored("/src/main.go", false))
+	assert.True(t, rules.ignored("/src/web/main.go", false))
+	assert.False(t, rules.ignored("/src/README.md", false))
+}
+
+func TestWalkFilter_Validate(t *testing.T) {
+	assert.NoError(t, (&WalkFilter{Include: []string{"*.go", "web/**/*.ts"}, Exclude: []string{"vendor/"}}).Validate())
+	err := (&WalkFilter{Exclude: []string{"web/[a-"}}).Validate()
+	assert.ErrorContains(t, err, `invalid glob "web/[a-"`)
+	assert.Equal(t, KindValidation, KindOf(err))
+}
+
+func TestIsBinary(t *testing.T) {
+	assert.False(t, IsBinary([]byte("package main\n")))
+	assert.False(t, IsBinary([]byte("¿Qué tal?")))
+	assert.False(t, IsBinary(nil))
+	assert.True(t, IsBinary([]byte("PNG\x00\x01")))
+	assert.True(t, IsBinary([]byte{0xff, 0xfe, 'a'}))
+	// A character split by the sniffed prefix is not binary
+	assert.False(t, IsBinary([]byte(strings.Repeat("a", 7999)+"é")))
+}
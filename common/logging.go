@@ -0,0 +1,73 @@
+package common
+
+import (
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Log formats of --log-format
+const (
+	LogFormatText = "text"
+	LogFormatJson = "json"
+)
+
+// Attributes the log records share, so records about the same command, repository or
+// file can be found whichever part of the CLI wrote them.
+const (
+	LogCommand  = "command"
+	LogRepo     = "repo"
+	LogFile     = "file"
+	LogDuration = "duration"
+)
+
+// TuiLogFileName is the file under CacheDir receiving the logs while the terminal UI runs.
+const TuiLogFileName = "tui.log"
+
+// LogLevel is set with --log-level, records below it are dropped.
+var LogLevel = "info"
+
+// LogFormat is set with --log-format.
+var LogFormat = LogFormatText
+
+// NewLogger returns a logger writing the records of level or above to w, as text or as
+// JSON lines.
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, Errorf(KindValidation, "invalid log level %q, use debug, info, warn or error", level)
+	}
+	options := &slog.HandlerOptions{Level: minLevel}
+	switch format {
+	case LogFormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case LogFormatJson:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return nil, Errorf(KindValidation, "invalid log format %q, use %s or %s", format, LogFormatText, LogFormatJson)
+}
+
+// SetupLogging makes the logger selected with --log-level and --log-format the default
+// one, writing to stderr so logs never mix with the output of command, which every
+// record carries.
+func SetupLogging(command string) error {
+	logger, err := NewLogger(os.Stderr, LogLevel, LogFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger.With(LogCommand, command))
+	return nil
+}
+
+// RedirectLogging makes the default logger write to w instead of stderr, for commands
+// drawing on the whole terminal. Level, format and command stay the same. restore sets
+// back the previous default logger.
+func RedirectLogging(w io.Writer, command string) (restore func(), err error) {
+	logger, err := NewLogger(w, LogLevel, LogFormat)
+	if err != nil {
+		return nil, err
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger.With(LogCommand, command))
+	return func() { slog.SetDefault(previous) }, nil
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := NewLogger(&out, "WARN", LogFormatJson)
+	assert.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("cannot read directory", LogFile, "src/vendor", LogRepo, "utn/tp1")
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "cannot read directory", record["msg"])
+	assert.Equal(t, "src/vendor", record[LogFile])
+	assert.Equal(t, "utn/tp1", record[LogRepo])
+
+	out.Reset()
+	logger, err = NewLogger(&out, "debug", LogFormatText)
+	assert.NoError(t, err)
+	logger.Debug("document loaded", LogFile, "main.go")
+	assert.Contains(t, out.String(), `level=DEBUG msg="document loaded" file=main.go`)
+}
+
+func TestRedirectLogging(t *testing.T) {
+	var out bytes.Buffer
+	previous := slog.Default()
+	restore, err := RedirectLogging(&out, "tui")
+	assert.NoError(t, err)
+	slog.Info("offline, answered from the cache", LogRepo, "utn/tp1")
+	restore()
+
+	assert.Contains(t, out.String(), `msg="offline, answered from the cache" command=tui repo=utn/tp1`)
+	assert.Same(t, previous, slog.Default())
+}
+
+func TestNewLogger_Invalid(t *testing.T) {
+	_, err := NewLogger(nil, "verbose", LogFormatText)
+	assert.EqualError(t, err, `invalid log level "verbose", use debug, info, warn or error`)
+	assert.Equal(t, KindValidation, KindOf(err))
+
+	_, err = NewLogger(nil, "info", "xml")
+	assert.EqualError(t, err, `invalid log format "xml", use text or json`)
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// PluginPrefix starts the name of the executables run as subcommands, github-cli-foo runs as `github-cli foo`.
+const PluginPrefix = AppName + "-"
+
+// Plugin is an executable found on PATH that runs as a subcommand.
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// FindPlugins lists the plugins on PATH sorted by name. When several directories hold
+// the same plugin, the first one wins like it does for any other command.
+func FindPlugins() []Plugin {
+	seen := map[string]bool{}
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// LookPlugin returns the path of the plugin name, or an error when it is not on PATH.
+func LookPlugin(name string) (string, error) {
+	return exec.LookPath(PluginPrefix + name)
+}
+
+func pluginName(fileName string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		fileName = strings.TrimSuffix(strings.ToLower(fileName), ".exe")
+	}
+	name, ok := strings.CutPrefix(fileName, PluginPrefix)
+	return name, ok && name != ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.HasSuffix(strings.ToLower(path), ".exe")
+	}
+	return info.Mode()&0111 != 0
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by extension on Windows")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	write := func(dir, name string, mode os.FileMode) {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode))
+	}
+	write(first, "github-cli-grades", 0755)
+	write(first, "github-cli-notes.txt", 0644)
+	write(second, "github-cli-grades", 0755)
+	write(second, "github-cli-classroom", 0755)
+	write(second, "git-lfs", 0755)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	plugins := FindPlugins()
+
+	assert.Equal(t, []Plugin{
+		{Name: "classroom", Path: filepath.Join(second, "github-cli-classroom")},
+		{Name: "grades", Path: filepath.Join(first, "github-cli-grades")},
+	}, plugins)
+
+	path, err := LookPlugin("classroom")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(second, "github-cli-classroom"), path)
+	_, err = LookPlugin("notes.txt")
+	assert.Error(t, err)
+}
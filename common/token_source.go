@@ -0,0 +1,79 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// commandTokens caches the output of every token_command, so the helper runs once per process
+var commandTokens = struct {
+	sync.Mutex
+	values map[string]string
+}{values: map[string]string{}}
+
+// runTokenCommand runs command with the shell and returns its standard output.
+var runTokenCommand = func(command string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return output, err
+}
+
+// HasTokenSource reports whether a token is configured, directly or through token_env or token_command.
+func (config *GithubConfig) HasTokenSource() bool {
+	return config.Token != "" || config.Token_Env != "" || config.Token_Command != ""
+}
+
+// ResolveToken returns the token to call the API with. A token set in the configuration
+// takes precedence over token_env, which takes precedence over token_command.
+func (config *GithubConfig) ResolveToken() (string, error) {
+	switch {
+	case config.Token != "":
+		return config.Token, nil
+	case config.Token_Env != "":
+		token := strings.TrimSpace(os.Getenv(config.Token_Env))
+		if token == "" {
+			return "", Errorf(KindAuth, "token_env: environment variable %s is not set", config.Token_Env)
+		}
+		return token, nil
+	case config.Token_Command != "":
+		return commandToken(config.Token_Command)
+	}
+	return "", NewError(KindAuth, "no token configured, set token, token_env or token_command or run `auth login`")
+}
+
+func commandToken(command string) (string, error) {
+	commandTokens.Lock()
+	defer commandTokens.Unlock()
+	if token, ok := commandTokens.values[command]; ok {
+		return token, nil
+	}
+	output, err := runTokenCommand(command)
+	if err != nil {
+		// The output is never included, it may hold part of the token
+		return "", Errorf(KindAuth, "token_command %q failed: %w", command, err)
+	}
+	// Helpers like `pass show` print the secret on the first line
+	token, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", Errorf(KindAuth, "token_command %q printed no token", command)
+	}
+	commandTokens.values[command] = token
+	return token, nil
+}
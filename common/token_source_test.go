@@ -0,0 +1,82 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveToken(t *testing.T) {
+	t.Setenv("TEST_GITHUB_TOKEN", "envToken")
+	runs := 0
+	defaultRunTokenCommand := runTokenCommand
+	runTokenCommand = func(command string) ([]byte, error) {
+		runs++
+		switch command {
+		case "pass show github/utn":
+			return []byte("commandToken\nlogin: utn\n"), nil
+		case "empty":
+			return []byte("\n"), nil
+		}
+		return nil, errors.New("exit status 1")
+	}
+	defer func() {
+		runTokenCommand = defaultRunTokenCommand
+		commandTokens.values = map[string]string{}
+	}()
+
+	tests := []struct {
+		name          string
+		config        GithubConfig
+		expectedToken string
+		expectedError string
+	}{
+		{"Token", GithubConfig{Token: "token", Token_Env: "TEST_GITHUB_TOKEN"}, "token", ""},
+		{"Token env", GithubConfig{Token_Env: "TEST_GITHUB_TOKEN", Token_Command: "pass show github/utn"}, "envToken", ""},
+		{"Token env not set", GithubConfig{Token_Env: "TEST_MISSING_TOKEN"}, "", "token_env: environment variable TEST_MISSING_TOKEN is not set"},
+		{"Token command", GithubConfig{Token_Command: "pass show github/utn"}, "commandToken", ""},
+		{"Token command without output", GithubConfig{Token_Command: "empty"}, "", `token_command "empty" printed no token`},
+		{"Token command failure", GithubConfig{Token_Command: "fail"}, "", `token_command "fail" failed: exit status 1`},
+		{"Nothing configured", GithubConfig{}, "", "no token configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.config.ResolveToken()
+
+			assert.Equal(t, tt.expectedToken, token)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedError)
+			}
+		})
+	}
+
+	// The command runs once per process
+	runs = 0
+	for i := 0; i < 2; i++ {
+		token, err := (&GithubConfig{Token_Command: "pass show github/utn"}).ResolveToken()
+		assert.NoError(t, err)
+		assert.Equal(t, "commandToken", token)
+	}
+	assert.Equal(t, 0, runs)
+}
+
+func TestConfig_GithubTokenSources(t *testing.T) {
+	inProjectDir(t)
+	config, err := ResolveConfig(dotEnvOnly(func(path string, config interface{}) error {
+		*config.(*Config) = Config{GithubConfig: GithubConfig{Owner: "utn", Token_Command: "pass show github/utn"}}
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	github, err := config.Github()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pass show github/utn", github.Token_Command)
+	// The token is resolved when the client is created, never kept in the configuration
+	value, _ := config.Get("token")
+	assert.Equal(t, "", value)
+}
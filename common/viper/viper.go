@@ -1,26 +1,61 @@
 package viper
 
 import (
+	"errors"
 	"fmt"
 	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
 )
 
-// LoadConfig is a wrapper function to load configuration from the given file.
+// ViperLoadConfig is a wrapper function to load configuration from the given file.
 func ViperLoadConfig(path string, config interface{}) error {
+	// A new instance per file, so loading several files never mixes their values
+	v := viper.New()
 	// Set the file to read
-	viper.SetConfigFile(path)
+	v.SetConfigFile(path)
 
 	// Read in the config file
-	err := viper.ReadInConfig()
+	err := v.ReadInConfig()
 	if err != nil {
 		return fmt.Errorf("error reading config file: %w", err)
 	}
 
 	// Unmarshal the config into the provided struct
-	err = viper.Unmarshal(config)
+	err = v.Unmarshal(config)
 	if err != nil {
 		return fmt.Errorf("error unmarshalling config: %w", err)
 	}
 
 	return nil
 }
+
+// ViperUpdateConfig reads the config file at path, lets update modify its values and writes it back.
+// The file is created, only readable by the current user, when it does not exist.
+func ViperUpdateConfig(path string, update func(values map[string]any) error) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	err := v.ReadInConfig()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	values := v.AllSettings()
+	if err = update(values); err != nil {
+		return err
+	}
+
+	// Rebuild from scratch so keys removed by update are not written back
+	out := viper.New()
+	out.SetConfigPermissions(0600)
+	for key, value := range values {
+		out.Set(key, value)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	if err = out.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
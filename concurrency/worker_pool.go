@@ -1,7 +1,7 @@
 package concurrency
 
 import (
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -26,17 +26,21 @@ func NewWorkerPool(numOfWorkers int16) *WorkerPool {
 	}
 }
 func (wp *WorkerPool) run() {
-	for i := 0; i <= int(wp.NumOfWorkers); i++ {
+	for i := 0; i < int(wp.NumOfWorkers); i++ {
 		go func() {
 			for {
 				select {
 				case task, ok := <-wp.Tasks:
 					if !ok {
-						log.Fatal("Fatal. WorkerPool channel closed")
+						slog.Error("worker pool tasks channel closed")
 						return
 					}
 					err := task.Execute()
-					wp.Results <- true
+					select {
+					case wp.Results <- true:
+					case <-wp.Quit:
+						return
+					}
 					if err != nil {
 						task.ErrorHandler(err)
 					}
@@ -64,7 +68,7 @@ func (wp *WorkerPool) WaitForTimeout(d time.Duration) {
 				timer = time.NewTimer(d)
 			case <-timer.C:
 				close(wp.Quit)
-				log.Println("WorkerPool Quit")
+				slog.Debug("worker pool stopped, no task finished in time", "timeout", d)
 				return
 			}
 		}
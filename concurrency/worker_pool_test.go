@@ -0,0 +1,36 @@
+package concurrency
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool_Limit(t *testing.T) {
+	pool := NewWorkerPool(3)
+	pool.Start()
+	defer pool.Stop()
+	var running, maxRunning atomic.Int32
+	var mu sync.Mutex
+	for i := 0; i < 12; i++ {
+		pool.AddTask(Executor{Execute: func() error {
+			n := running.Add(1)
+			mu.Lock()
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		}})
+	}
+	for i := 0; i < 12; i++ {
+		<-pool.Results
+	}
+
+	assert.Equal(t, int32(3), maxRunning.Load())
+}
@@ -0,0 +1,173 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const DefaultBaseURL = "https://api.github.com/"
+
+// jwtLifetime is kept below the 10 minutes maximum allowed by GitHub to tolerate clock drift.
+const jwtLifetime = 9 * time.Minute
+
+// tokenRefreshMargin is how long before expiration an installation token is renewed.
+const tokenRefreshMargin = time.Minute
+
+// AppTransport is an http.RoundTripper that authenticates requests as a GitHub App installation.
+// It mints a JWT signed with the app private key, exchanges it for an installation token and
+// refreshes that token before it expires.
+type AppTransport struct {
+	BaseURL        string
+	Base           http.RoundTripper
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewAppTransport(base http.RoundTripper, appID, installationID int64, privateKey []byte) (*AppTransport, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, common.WithKind(common.KindValidation, err)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &AppTransport{
+		BaseURL:        DefaultBaseURL,
+		Base:           base,
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		now:            time.Now,
+	}, nil
+}
+
+func NewAppTransportFromFile(base http.RoundTripper, appID, installationID int64, privateKeyFile string) (*AppTransport, error) {
+	privateKey, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, common.Errorf(common.KindValidation, "could not read private key file: %w", err)
+	}
+	return NewAppTransport(base, appID, installationID, privateKey)
+}
+
+func (t *AppTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Token()
+	if err != nil {
+		return nil, err
+	}
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", "Bearer "+token)
+	return t.Base.RoundTrip(authReq)
+}
+
+// Token returns a valid installation token, requesting a new one when the cached token is about to expire.
+func (t *AppTransport) Token() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && t.now().Add(tokenRefreshMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+	token, expiresAt, err := t.requestInstallationToken()
+	if err != nil {
+		return "", err
+	}
+	t.token, t.expiresAt = token, expiresAt
+	return token, nil
+}
+
+func (t *AppTransport) requestInstallationToken() (string, time.Time, error) {
+	jwt, err := t.signJWT()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", t.BaseURL, t.installationID)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return "", time.Time{}, common.WithKind(common.KindNetwork, fmt.Errorf("could not request installation token: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", time.Time{}, common.Errorf(common.KindAuth, "could not request installation token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, fmt.Errorf("could not decode installation token: %w", err)
+	}
+	if body.Token == "" {
+		return "", time.Time{}, errors.New("empty installation token received")
+	}
+	return body.Token, body.ExpiresAt, nil
+}
+
+// signJWT builds the RS256 JSON Web Token GitHub expects when authenticating as an app.
+func (t *AppTransport) signJWT() (string, error) {
+	now := t.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(), // backdated to allow for clock drift
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(t.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("could not sign JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
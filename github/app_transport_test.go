@@ -0,0 +1,109 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, pemKey
+}
+
+func verifyJWT(t *testing.T, key *rsa.PrivateKey, jwt string) map[string]any {
+	parts := strings.Split(jwt, ".")
+	if !assert.Len(t, parts, 3) {
+		return nil
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assert.NoError(t, err)
+	claims := map[string]any{}
+	assert.NoError(t, json.Unmarshal(payload, &claims))
+	return claims
+}
+
+func TestAppTransport_RoundTrip(t *testing.T) {
+	key, pemKey := generateKey(t)
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/installations/99/access_tokens":
+			tokenRequests++
+			assert.Equal(t, http.MethodPost, r.Method)
+			claims := verifyJWT(t, key, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+			assert.Equal(t, "42", claims["iss"])
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"token":"installation-token-%d","expires_at":%q}`, tokenRequests, time.Now().Add(time.Hour).Format(time.RFC3339))
+		case "/user/repos":
+			_, _ = fmt.Fprint(w, r.Header.Get("Authorization"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	transport, err := NewAppTransport(nil, 42, 99, pemKey)
+	assert.NoError(t, err)
+	transport.BaseURL = server.URL + "/"
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/user/repos")
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, "Bearer installation-token-1", string(body))
+	}
+	assert.Equal(t, 1, tokenRequests, "token should be cached between requests")
+
+	// Move the clock close to expiration so the token gets refreshed
+	transport.now = func() time.Time { return time.Now().Add(59*time.Minute + 30*time.Second) }
+	token, err := transport.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "installation-token-2", token)
+	assert.Equal(t, 2, tokenRequests)
+}
+
+func TestAppTransport_TokenError(t *testing.T) {
+	_, pemKey := generateKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	transport, err := NewAppTransport(nil, 42, 99, pemKey)
+	assert.NoError(t, err)
+	transport.BaseURL = server.URL + "/"
+
+	_, err = transport.Token()
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestNewAppTransport_InvalidKey(t *testing.T) {
+	_, err := NewAppTransport(nil, 42, 99, []byte("not a key"))
+	assert.Error(t, err)
+}
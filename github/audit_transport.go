@@ -0,0 +1,132 @@
+package github
+
+import (
+	"encoding/json"
+	"github.com/ffumaneri/github-cli/common"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AuditTransport is an http.RoundTripper that records the requests changing something on
+// GitHub with the action, repository and user they are about.
+type AuditTransport struct {
+	Base   http.RoundTripper
+	record func(entry common.AuditEntry)
+	now    func() time.Time
+}
+
+func NewAuditTransport(base http.RoundTripper, record func(entry common.AuditEntry)) *AuditTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &AuditTransport{Base: base, record: record, now: time.Now}
+}
+
+func (t *AuditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.Base.RoundTrip(req)
+	}
+	entry := auditTarget(req)
+	entry.Time, entry.Method, entry.Path = t.now(), req.Method, req.URL.Path
+	resp, err := t.Base.RoundTrip(req)
+	switch {
+	case err != nil:
+		entry.Error = err.Error()
+	case resp.StatusCode >= http.StatusBadRequest:
+		entry.Status, entry.Error = resp.StatusCode, resp.Status
+	default:
+		entry.Status = resp.StatusCode
+	}
+	t.record(entry)
+	return resp, err
+}
+
+// auditTarget names the action of req and the repository and user it is about, from
+// the REST routes the commands use. Other routes are named after the method and the
+// first segment below the repository, e.g. "post milestones".
+func auditTarget(req *http.Request) common.AuditEntry {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	var entry common.AuditEntry
+	if name, ok := createdRepoName(req, parts); ok {
+		entry.Action, entry.Repo = "create-repo", name
+		if parts[0] == "orgs" {
+			entry.Repo = parts[1] + "/" + name
+		}
+		return entry
+	}
+	if len(parts) < 3 || parts[0] != "repos" {
+		entry.Action = strings.ToLower(req.Method) + " " + parts[0]
+		return entry
+	}
+	entry.Repo = parts[1] + "/" + parts[2]
+	switch {
+	case len(parts) == 3 && req.Method == http.MethodPatch:
+		entry.Action = "update-repo"
+		if archived, ok := requestArchived(req); ok && archived {
+			entry.Action = "archive"
+		} else if ok {
+			entry.Action = "unarchive"
+		}
+	case len(parts) == 3 && req.Method == http.MethodDelete:
+		entry.Action = "delete-repo"
+	case len(parts) == 5 && parts[3] == "collaborators" && req.Method == http.MethodPut:
+		entry.Action, entry.User = "invite", parts[4]
+	case len(parts) == 5 && parts[3] == "collaborators" && req.Method == http.MethodDelete:
+		entry.Action, entry.User = "remove-collaborator", parts[4]
+	case len(parts) == 5 && parts[3] == "invitations" && req.Method == http.MethodPatch:
+		entry.Action = "update-invitation"
+	case len(parts) == 5 && parts[3] == "invitations" && req.Method == http.MethodDelete:
+		entry.Action = "cancel-invitation"
+	case len(parts) > 4 && parts[3] == "contents" && req.Method == http.MethodPut:
+		entry.Action = "push-file"
+	case len(parts) >= 4 && parts[3] == "labels" && (req.Method == http.MethodPost || req.Method == http.MethodPatch):
+		entry.Action = "label"
+	case len(parts) > 3:
+		entry.Action = strings.ToLower(req.Method) + " " + parts[3]
+	default:
+		entry.Action = strings.ToLower(req.Method)
+	}
+	return entry
+}
+
+// createdRepoName returns the name of the repository created by req, a POST to
+// /user/repos or /orgs/{org}/repos.
+func createdRepoName(req *http.Request, parts []string) (string, bool) {
+	if req.Method != http.MethodPost || parts[len(parts)-1] != "repos" ||
+		!(len(parts) == 2 && parts[0] == "user" || len(parts) == 3 && parts[0] == "orgs") {
+		return "", false
+	}
+	var repo struct {
+		Name string `json:"name"`
+	}
+	requestBody(req, &repo)
+	return repo.Name, true
+}
+
+// requestArchived reads the archived field of a repository update without consuming the body.
+func requestArchived(req *http.Request) (archived bool, ok bool) {
+	var update struct {
+		Archived *bool `json:"archived"`
+	}
+	if !requestBody(req, &update) || update.Archived == nil {
+		return false, false
+	}
+	return *update.Archived, true
+}
+
+// requestBody decodes the JSON body of req into v without consuming it.
+func requestBody(req *http.Request, v any) bool {
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	return err == nil && json.Unmarshal(data, v) == nil
+}
@@ -0,0 +1,95 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/google/go-github/v65/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/repos/utn/tp1/collaborators/bob":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Must have admin rights to Repository."}`))
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 7}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	var recorded []common.AuditEntry
+	transport := NewAuditTransport(nil, func(entry common.AuditEntry) {
+		recorded = append(recorded, entry)
+	})
+	transport.now = func() time.Time { return now }
+	client := github.NewClient(&http.Client{Transport: transport})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	ctx := context.Background()
+
+	// Reads are not recorded
+	_, _, err := client.Repositories.Get(ctx, "utn", "tp1")
+	assert.NoError(t, err)
+	_, _, err = client.Repositories.AddCollaborator(ctx, "utn", "tp1", "alice", nil)
+	assert.NoError(t, err)
+	_, err = client.Repositories.RemoveCollaborator(ctx, "utn", "tp1", "bob")
+	assert.Error(t, err)
+	_, err = client.Repositories.DeleteInvitation(ctx, "utn", "tp1", 7)
+	assert.NoError(t, err)
+	_, _, err = client.Repositories.Edit(ctx, "utn", "tp1", &github.Repository{Archived: github.Bool(true)})
+	assert.NoError(t, err)
+	_, _, err = client.Issues.CreateLabel(ctx, "utn", "tp1", &github.Label{Name: github.String("tp")})
+	assert.NoError(t, err)
+	_, _, err = client.Repositories.Create(ctx, "utn", &github.Repository{Name: github.String("tp2")})
+	assert.NoError(t, err)
+	_, _, err = client.Repositories.CreateFile(ctx, "utn", "tp2", "docs/README.md", &github.RepositoryContentFileOptions{Content: []byte("hi")})
+	assert.NoError(t, err)
+	_, _, err = client.Issues.CreateMilestone(ctx, "utn", "tp1", &github.Milestone{Title: github.String("tp")})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []common.AuditEntry{
+		{Time: now, Action: "invite", Repo: "utn/tp1", User: "alice", Method: "PUT", Path: "/repos/utn/tp1/collaborators/alice", Status: 201},
+		{Time: now, Action: "remove-collaborator", Repo: "utn/tp1", User: "bob", Method: "DELETE", Path: "/repos/utn/tp1/collaborators/bob", Status: 403, Error: "403 Forbidden"},
+		{Time: now, Action: "cancel-invitation", Repo: "utn/tp1", Method: "DELETE", Path: "/repos/utn/tp1/invitations/7", Status: 200},
+		{Time: now, Action: "archive", Repo: "utn/tp1", Method: "PATCH", Path: "/repos/utn/tp1", Status: 200},
+		{Time: now, Action: "label", Repo: "utn/tp1", Method: "POST", Path: "/repos/utn/tp1/labels", Status: 200},
+		{Time: now, Action: "create-repo", Repo: "utn/tp2", Method: "POST", Path: "/orgs/utn/repos", Status: 200},
+		{Time: now, Action: "push-file", Repo: "utn/tp2", Method: "PUT", Path: "/repos/utn/tp2/contents/docs/README.md", Status: 201},
+		{Time: now, Action: "post milestones", Repo: "utn/tp1", Method: "POST", Path: "/repos/utn/tp1/milestones", Status: 200},
+	}, recorded)
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAuditTransport_NetworkError(t *testing.T) {
+	var recorded []common.AuditEntry
+	client := &http.Client{Transport: NewAuditTransport(failingTransport{}, func(entry common.AuditEntry) {
+		recorded = append(recorded, entry)
+	})}
+
+	_, err := client.Post("https://api.github.com/user/repos", "application/json", nil)
+
+	assert.Error(t, err)
+	if assert.Len(t, recorded, 1) {
+		assert.Equal(t, "create-repo", recorded[0].Action)
+		assert.Equal(t, 0, recorded[0].Status)
+		assert.Equal(t, "connection refused", recorded[0].Error)
+	}
+}
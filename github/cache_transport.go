@@ -0,0 +1,161 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheTransport is an http.RoundTripper keeping the GET responses that carry an ETag
+// or a Last-Modified date on disk. The next request for the same URL is sent with
+// If-None-Match and If-Modified-Since, and a 304 Not Modified, which does not count
+// against the rate limit, is answered with the stored response.
+type CacheTransport struct {
+	Base http.RoundTripper
+	// Offline, when set, answers GET requests only from the stored responses, calling it
+	// with the time each one was last confirmed by GitHub, and fails the other requests.
+	Offline func(req *http.Request, asOf time.Time)
+	// dir keeps the responses of one set of credentials, so they are never shared
+	dir string
+	now func() time.Time
+}
+
+// cachedResponse is the file stored for a request.
+type cachedResponse struct {
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	// AsOf is when GitHub last sent or confirmed the response
+	AsOf time.Time `json:"as_of"`
+}
+
+// NewCacheTransport stores the responses under dir, in a directory named after a hash of
+// credentials, the token or the app installation the requests are authenticated with.
+func NewCacheTransport(base http.RoundTripper, dir string, credentials string) *CacheTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	sum := sha256.Sum256([]byte(credentials))
+	return &CacheTransport{Base: base, dir: filepath.Join(dir, hex.EncodeToString(sum[:8])), now: time.Now}
+}
+
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Offline != nil {
+		return t.offline(req)
+	}
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+	path := t.path(req)
+	cached := t.load(path)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		cached.AsOf = t.now()
+		t.save(path, cached)
+		return cached.response(req, resp.Header), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.save(path, &cachedResponse{resp.StatusCode, resp.Header, body, etag, lastModified, t.now()})
+	return resp, nil
+}
+
+// offline answers req from the stored responses without sending anything.
+func (t *CacheTransport) offline(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if req.Method != http.MethodGet {
+		return nil, common.ErrOffline
+	}
+	cached := t.load(t.path(req))
+	if cached == nil {
+		return nil, common.NewError(common.KindNetwork, "not cached yet, run the command once online")
+	}
+	t.Offline(req, cached.AsOf)
+	return cached.response(req, nil), nil
+}
+
+// save stores cached, failing to store only means the next request is not conditional.
+func (t *CacheTransport) save(path string, cached *cachedResponse) {
+	if data, err := json.Marshal(cached); err == nil {
+		_ = common.WriteFileAtomic(path, data)
+	}
+}
+
+// path names the file after a hash of the URL and the representation asked for.
+func (t *CacheTransport) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\x00" + req.Header.Get("Accept")))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (t *CacheTransport) load(path string) *cachedResponse {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cached cachedResponse
+	if err = json.Unmarshal(data, &cached); err != nil {
+		return nil
+	}
+	return &cached
+}
+
+// response rebuilds the stored response, with the headers of the 304 that confirmed it,
+// like the current rate limit, replacing the stored ones.
+func (cached *cachedResponse) response(req *http.Request, notModified http.Header) *http.Response {
+	header := cached.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for key, values := range notModified {
+		if key != "Content-Length" {
+			header[key] = values
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.Status, http.StatusText(cached.Status)),
+		StatusCode:    cached.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
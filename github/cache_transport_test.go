@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/google/go-github/v65/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheTransport(t *testing.T) {
+	var requests []string
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		switch r.URL.Path {
+		case "/users/utn/repos":
+			conditional = append(conditional, r.Header.Get("If-None-Match"))
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.Header().Set("X-RateLimit-Remaining", "4998")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`[{"full_name": "utn/tp1"}]`))
+		case "/repos/utn/tp1":
+			// Without validators the response is not stored
+			w.Write([]byte(`{"full_name": "utn/tp1"}`))
+		}
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	newClient := func(token string) *github.Client {
+		client := github.NewClient(&http.Client{Transport: NewCacheTransport(nil, dir, token)})
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+		return client
+	}
+	ctx := context.Background()
+
+	client := newClient("token")
+	for i := 0; i < 2; i++ {
+		repos, resp, err := client.Repositories.ListByUser(ctx, "utn", nil)
+		assert.NoError(t, err)
+		if assert.Len(t, repos, 1) {
+			assert.Equal(t, "utn/tp1", repos[0].GetFullName())
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	// The stored response carries the rate limit of the 304
+	_, resp, _ := client.Repositories.ListByUser(ctx, "utn", nil)
+	assert.Equal(t, 4998, resp.Rate.Remaining)
+	assert.Equal(t, []string{"", `"v1"`, `"v1"`}, conditional)
+
+	// Other credentials do not share the responses
+	_, _, err := newClient("other token").Repositories.ListByUser(ctx, "utn", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", `"v1"`, `"v1"`, ""}, conditional)
+
+	for i := 0; i < 2; i++ {
+		_, _, err = client.Repositories.Get(ctx, "utn", "tp1")
+		assert.NoError(t, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	assert.Len(t, files, 2, "one response per token, the repository has no ETag")
+
+	// A damaged file is ignored
+	for _, file := range files {
+		assert.NoError(t, os.WriteFile(file, []byte("{"), 0600))
+	}
+	_, _, err = client.Repositories.ListByUser(ctx, "utn", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", conditional[len(conditional)-1])
+	assert.Len(t, requests, 7)
+}
+
+func TestCacheTransport_Offline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"login": "alice"}]`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	storedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	online := NewCacheTransport(nil, dir, "token")
+	online.now = func() time.Time { return storedAt }
+	client := github.NewClient(&http.Client{Transport: online})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	ctx := context.Background()
+	_, _, err := client.Repositories.ListCollaborators(ctx, "utn", "tp1", nil)
+	assert.NoError(t, err)
+	server.Close()
+
+	var served []string
+	offline := NewCacheTransport(nil, dir, "token")
+	offline.Offline = func(req *http.Request, asOf time.Time) {
+		served = append(served, req.URL.Path+" "+asOf.UTC().Format(time.RFC3339))
+	}
+	client = github.NewClient(&http.Client{Transport: offline})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	collaborators, _, err := client.Repositories.ListCollaborators(ctx, "utn", "tp1", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, collaborators, 1) {
+		assert.Equal(t, "alice", collaborators[0].GetLogin())
+	}
+	assert.Equal(t, []string{"/repos/utn/tp1/collaborators 2024-03-01T10:00:00Z"}, served)
+
+	_, _, err = client.Repositories.ListCollaborators(ctx, "utn", "tp2", nil)
+	assert.ErrorContains(t, err, "not cached yet, run the command once online")
+	assert.Equal(t, common.KindNetwork, common.KindOf(err))
+
+	_, _, err = client.Repositories.AddCollaborator(ctx, "utn", "tp1", "bob", nil)
+	assert.ErrorContains(t, err, "cannot change anything on GitHub while offline")
+	assert.Equal(t, common.KindValidation, common.KindOf(err))
+}
@@ -0,0 +1,141 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDeviceCodeUrl = "https://github.com/login/device/code"
+	DefaultTokenUrl      = "https://github.com/login/oauth/access_token"
+	deviceGrantType      = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// DeviceCode is the code the user must enter at VerificationUri to authorize the CLI.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationUri string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type IDeviceFlow interface {
+	RequestCode() (*DeviceCode, error)
+	PollToken(code *DeviceCode) (string, error)
+}
+
+// DeviceFlow implements the OAuth device authorization grant.
+// The endpoints are configurable so a local stand-in can replace github.com.
+type DeviceFlow struct {
+	ClientId      string
+	DeviceCodeUrl string
+	TokenUrl      string
+	Scopes        []string
+	client        *http.Client
+	sleep         func(d time.Duration)
+}
+
+// NewDeviceFlow returns the flow of the OAuth app clientId, sending its requests with
+// httpClient, http.DefaultClient when nil.
+func NewDeviceFlow(clientId, deviceCodeUrl, tokenUrl string, scopes []string, httpClient *http.Client) *DeviceFlow {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if deviceCodeUrl == "" {
+		deviceCodeUrl = DefaultDeviceCodeUrl
+	}
+	if tokenUrl == "" {
+		tokenUrl = DefaultTokenUrl
+	}
+	return &DeviceFlow{
+		ClientId:      clientId,
+		DeviceCodeUrl: deviceCodeUrl,
+		TokenUrl:      tokenUrl,
+		Scopes:        scopes,
+		client:        httpClient,
+		sleep:         time.Sleep,
+	}
+}
+
+func (flow *DeviceFlow) RequestCode() (*DeviceCode, error) {
+	if flow.ClientId == "" {
+		return nil, common.NewError(common.KindValidation, "missing OAuth client id")
+	}
+	code := &DeviceCode{}
+	err := flow.post(flow.DeviceCodeUrl, url.Values{
+		"client_id": {flow.ClientId},
+		"scope":     {strings.Join(flow.Scopes, " ")},
+	}, code)
+	if err != nil {
+		return nil, fmt.Errorf("could not request device code: %w", err)
+	}
+	if code.DeviceCode == "" {
+		return nil, errors.New("could not request device code: empty response")
+	}
+	return code, nil
+}
+
+// PollToken waits until the user authorizes the device and returns the access token.
+func (flow *DeviceFlow) PollToken(code *DeviceCode) (string, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for code.ExpiresIn <= 0 || time.Now().Before(deadline) {
+		flow.sleep(interval)
+		var body struct {
+			AccessToken      string `json:"access_token"`
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			Interval         int    `json:"interval"`
+		}
+		err := flow.post(flow.TokenUrl, url.Values{
+			"client_id":   {flow.ClientId},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {deviceGrantType},
+		}, &body)
+		if err != nil {
+			return "", fmt.Errorf("could not request access token: %w", err)
+		}
+		switch body.Error {
+		case "":
+			return body.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			if body.Interval > 0 {
+				interval = time.Duration(body.Interval) * time.Second
+			} else {
+				interval += 5 * time.Second
+			}
+		default:
+			return "", common.Errorf(common.KindAuth, "device authorization failed: %s %s", body.Error, body.ErrorDescription)
+		}
+	}
+	return "", common.NewError(common.KindAuth, "device code expired before authorization")
+}
+
+func (flow *DeviceFlow) post(endpoint string, values url.Values, result any) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := flow.client.Do(req)
+	if err != nil {
+		return common.WithKind(common.KindNetwork, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,82 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokenReplies  []string
+		expectedToken string
+		expectedSleep []time.Duration
+		wantErr       bool
+	}{
+		{
+			name:          "authorized after pending",
+			tokenReplies:  []string{`{"error":"authorization_pending"}`, `{"access_token":"gho_token"}`},
+			expectedToken: "gho_token",
+			expectedSleep: []time.Duration{time.Second, time.Second},
+		},
+		{
+			name:          "slow down increases the interval",
+			tokenReplies:  []string{`{"error":"slow_down"}`, `{"access_token":"gho_token"}`},
+			expectedToken: "gho_token",
+			expectedSleep: []time.Duration{time.Second, 6 * time.Second},
+		},
+		{
+			name:          "access denied",
+			tokenReplies:  []string{`{"error":"access_denied","error_description":"denied by user"}`},
+			expectedSleep: []time.Duration{time.Second},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+				switch r.URL.Path {
+				case "/device/code":
+					assert.Equal(t, "repo read:org", r.PostForm.Get("scope"))
+					_, _ = fmt.Fprint(w, `{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://example.com/device","expires_in":900,"interval":1}`)
+				case "/oauth/access_token":
+					assert.Equal(t, "dc", r.PostForm.Get("device_code"))
+					_, _ = fmt.Fprint(w, tt.tokenReplies[calls])
+					calls++
+				}
+			}))
+			defer server.Close()
+
+			var slept []time.Duration
+			flow := NewDeviceFlow("client-id", server.URL+"/device/code", server.URL+"/oauth/access_token", []string{"repo", "read:org"}, server.Client())
+			flow.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+			code, err := flow.RequestCode()
+			assert.NoError(t, err)
+			assert.Equal(t, "ABCD-1234", code.UserCode)
+
+			token, err := flow.PollToken(code)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedToken, token)
+			}
+			assert.Equal(t, tt.expectedSleep, slept)
+		})
+	}
+}
+
+func TestDeviceFlow_MissingClientId(t *testing.T) {
+	_, err := NewDeviceFlow("", "", "", nil, nil).RequestCode()
+	assert.Error(t, err)
+}
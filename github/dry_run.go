@@ -0,0 +1,50 @@
+package github
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// DryRunTransport is an http.RoundTripper that sends the requests reading data and only
+// logs the ones that would change something, answering them with 204 No Content so the
+// commands carry on as if they had succeeded.
+type DryRunTransport struct {
+	Base http.RoundTripper
+	// log receives each skipped request with its body, which was consumed
+	log func(req *http.Request, body string)
+}
+
+func NewDryRunTransport(base http.RoundTripper, log func(req *http.Request, body string)) *DryRunTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &DryRunTransport{Base: base, log: log}
+}
+
+func (t *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.Base.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	t.log(req, string(bytes.TrimSpace(body)))
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v65/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunTransport(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id": 1, "full_name": "utn/api"}]`))
+	}))
+	defer server.Close()
+
+	var logged []string
+	client := github.NewClient(&http.Client{Transport: NewDryRunTransport(nil, func(req *http.Request, body string) {
+		logged = append(logged, strings.TrimSpace(req.Method+" "+req.URL.RequestURI()+" "+body))
+	})})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	// Reads are sent
+	repos, _, err := client.Repositories.ListByUser(context.Background(), "utn", nil)
+	assert.NoError(t, err)
+	assert.Len(t, repos, 1)
+
+	// Writes are only logged
+	_, _, err = client.Repositories.AddCollaborator(context.Background(), "utn", "api", "alice",
+		&github.RepositoryAddCollaboratorOptions{Permission: "push"})
+	assert.NoError(t, err)
+	_, err = client.Repositories.RemoveCollaborator(context.Background(), "utn", "api", "bob")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{http.MethodGet}, methods)
+	assert.Equal(t, []string{
+		`PUT /repos/utn/api/collaborators/alice {"permission":"push"}`,
+		"DELETE /repos/utn/api/collaborators/bob",
+	}, logged)
+}
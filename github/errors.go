@@ -0,0 +1,46 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/google/go-github/v65/github"
+	"net/http"
+	"time"
+)
+
+// apiError classifies the errors returned by the GitHub API, resource names what the
+// request was about in not found and permission errors.
+func apiError(err error, resource string) error {
+	if err == nil || common.KindOf(err) != common.KindUnknown {
+		return err
+	}
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var responseErr *github.ErrorResponse
+	switch {
+	case errors.As(err, &rateLimitErr):
+		return common.WrapError(common.KindRateLimited, err,
+			fmt.Sprintf("GitHub API rate limit exceeded, it resets at %s", rateLimitErr.Rate.Reset.Local().Format(time.Kitchen)))
+	case errors.As(err, &abuseErr):
+		message := "GitHub secondary rate limit exceeded"
+		if abuseErr.RetryAfter != nil {
+			message += fmt.Sprintf(", retry in %s", abuseErr.RetryAfter.Round(time.Second))
+		}
+		return common.WrapError(common.KindRateLimited, err, message)
+	case errors.As(err, &responseErr) && responseErr.Response != nil:
+		switch responseErr.Response.StatusCode {
+		case http.StatusUnauthorized:
+			return common.WrapError(common.KindAuth, err, "GitHub rejected the credentials, check the token or run `auth login`")
+		case http.StatusForbidden:
+			return common.WrapError(common.KindAuth, err, fmt.Sprintf("not allowed to access %s: %s", resource, responseErr.Message))
+		case http.StatusNotFound:
+			return common.WrapError(common.KindNotFound, err, fmt.Sprintf("%s not found", resource))
+		case http.StatusUnprocessableEntity:
+			return common.WrapError(common.KindValidation, err, fmt.Sprintf("GitHub rejected the request for %s: %s", resource, responseErr.Message))
+		}
+	case common.IsNetworkError(err):
+		return common.WrapError(common.KindNetwork, err, "cannot reach GitHub")
+	}
+	return err
+}
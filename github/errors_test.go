@@ -0,0 +1,47 @@
+package github
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/google/go-github/v65/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiError(t *testing.T) {
+	response := func(status int) *github.ErrorResponse {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status, Request: &http.Request{Method: http.MethodGet, URL: &url.URL{}}}, Message: "Resource not accessible"}
+	}
+	retryAfter := 30 * time.Second
+
+	tests := []struct {
+		name            string
+		err             error
+		expectedKind    common.ErrorKind
+		expectedMessage string
+	}{
+		{"Unauthorized", response(http.StatusUnauthorized), common.KindAuth, "GitHub rejected the credentials, check the token or run `auth login`"},
+		{"Forbidden", response(http.StatusForbidden), common.KindAuth, "not allowed to access repository utn/tp1: Resource not accessible"},
+		{"Not found", response(http.StatusNotFound), common.KindNotFound, "repository utn/tp1 not found"},
+		{"Rate limited", &github.RateLimitError{Response: &http.Response{}}, common.KindRateLimited, "GitHub API rate limit exceeded, it resets at " + time.Time{}.Local().Format(time.Kitchen)},
+		{"Secondary rate limit", &github.AbuseRateLimitError{Response: &http.Response{}, RetryAfter: &retryAfter}, common.KindRateLimited, "GitHub secondary rate limit exceeded, retry in 30s"},
+		{"Network", &url.Error{Op: "Get", URL: "https://api.github.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, common.KindNetwork, "cannot reach GitHub"},
+		{"Unknown", errors.New("boom"), common.KindUnknown, "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := apiError(tt.err, "repository utn/tp1")
+
+			assert.EqualError(t, err, tt.expectedMessage)
+			assert.Equal(t, tt.expectedKind, common.KindOf(err))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+	assert.Nil(t, apiError(nil, "repository utn/tp1"))
+}
@@ -1,27 +1,78 @@
 package github
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"github.com/google/go-github/v65/github"
+	"net/http"
+	"strings"
 )
 
 type IGithubWrapper interface {
 	GetRepos(owner string) ([]string, error)
 	GetCollaboratorsByRepo(owner string, repo string) ([]string, error)
 	InviteCollaborator(owner string, repo, user string) error
+	GetInvitationsByRepo(owner string, repo string) ([]Invitation, error)
+	CancelInvitation(owner string, repo string, invitationId int64) error
+	RemoveCollaborator(owner string, repo, user string) error
+	GetAuthenticatedUser() (login string, scopes []string, err error)
+	CreateRepo(owner string, repo string, description string, private bool) (created bool, err error)
+	PushFile(owner string, repo string, path string, file FileChange) (changed bool, err error)
+	SaveLabel(owner string, repo string, label Label) (changed bool, err error)
+}
+
+// Invitation is a pending invitation to collaborate on a repository.
+type Invitation struct {
+	Id    int64
+	Login string
+}
+
+// FileChange is the content a file of a repository is set to, on Branch or on the
+// default branch when it is empty.
+type FileChange struct {
+	Content []byte
+	Message string
+	Branch  string
+}
+
+// Label is an issue label, Color is six hex digits without the #.
+type Label struct {
+	Name        string
+	Color       string
+	Description string
 }
 
 func NewGithubWrapper(client *github.Client, owner string) *GithubWrapper {
-	return &GithubWrapper{client.Repositories, owner}
+	return &GithubWrapper{client.Repositories, client.Users, client.Issues, owner}
 }
 
 type IGithubRepositories interface {
 	ListByUser(ctx context.Context, owner string, opt *github.RepositoryListByUserOptions) ([]*github.Repository, *github.Response, error)
 	AddCollaborator(ctx context.Context, owner, repo, user string, opts *github.RepositoryAddCollaboratorOptions) (*github.CollaboratorInvitation, *github.Response, error)
 	ListCollaborators(ctx context.Context, owner, repo string, opts *github.ListCollaboratorsOptions) ([]*github.User, *github.Response, error)
+	RemoveCollaborator(ctx context.Context, owner, repo, user string) (*github.Response, error)
+	ListInvitations(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryInvitation, *github.Response, error)
+	DeleteInvitation(ctx context.Context, owner, repo string, invitationID int64) (*github.Response, error)
+	Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+	Create(ctx context.Context, org string, repo *github.Repository) (*github.Repository, *github.Response, error)
+	GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error)
+	CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+	UpdateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+}
+type IGithubUsers interface {
+	Get(ctx context.Context, user string) (*github.User, *github.Response, error)
+}
+type IGithubIssues interface {
+	GetLabel(ctx context.Context, owner, repo, name string) (*github.Label, *github.Response, error)
+	CreateLabel(ctx context.Context, owner, repo string, label *github.Label) (*github.Label, *github.Response, error)
+	EditLabel(ctx context.Context, owner, repo, name string, label *github.Label) (*github.Label, *github.Response, error)
 }
 type GithubWrapper struct {
 	Repositories IGithubRepositories
+	Users        IGithubUsers
+	Issues       IGithubIssues
 	owner        string
 }
 
@@ -31,7 +82,7 @@ func (gw *GithubWrapper) GetRepos(owner string) ([]string, error) {
 	for i, repo := range repos {
 		repoNames[i] = repo.GetFullName()
 	}
-	return repoNames, err
+	return repoNames, apiError(err, "owner "+owner)
 }
 
 func (gw *GithubWrapper) GetCollaboratorsByRepo(owner string, repo string) ([]string, error) {
@@ -40,12 +91,142 @@ func (gw *GithubWrapper) GetCollaboratorsByRepo(owner string, repo string) ([]st
 	for i, user := range users {
 		userNames[i] = user.GetLogin()
 	}
-	return userNames, err
+	return userNames, apiError(err, "repository "+owner+"/"+repo)
 }
 func (gw *GithubWrapper) InviteCollaborator(owner string, repo, user string) error {
 	_, _, err := gw.Repositories.AddCollaborator(context.Background(), owner, repo, user, nil)
 	if err != nil {
-		return err
+		return apiError(err, fmt.Sprintf("repository %s/%s or user %s", owner, repo, user))
 	}
 	return nil
 }
+
+// GetInvitationsByRepo returns the invitations to repo the invitees have not accepted yet.
+func (gw *GithubWrapper) GetInvitationsByRepo(owner string, repo string) ([]Invitation, error) {
+	invitations, _, err := gw.Repositories.ListInvitations(context.Background(), owner, repo, nil)
+	result := make([]Invitation, len(invitations))
+	for i, invitation := range invitations {
+		result[i] = Invitation{Id: invitation.GetID(), Login: invitation.GetInvitee().GetLogin()}
+	}
+	return result, apiError(err, "repository "+owner+"/"+repo)
+}
+
+func (gw *GithubWrapper) CancelInvitation(owner string, repo string, invitationId int64) error {
+	_, err := gw.Repositories.DeleteInvitation(context.Background(), owner, repo, invitationId)
+	if err != nil {
+		return apiError(err, fmt.Sprintf("invitation %d to repository %s/%s", invitationId, owner, repo))
+	}
+	return nil
+}
+
+func (gw *GithubWrapper) RemoveCollaborator(owner string, repo, user string) error {
+	_, err := gw.Repositories.RemoveCollaborator(context.Background(), owner, repo, user)
+	if err != nil {
+		return apiError(err, fmt.Sprintf("repository %s/%s or user %s", owner, repo, user))
+	}
+	return nil
+}
+
+// GetAuthenticatedUser returns the login of the token owner and the OAuth scopes granted to the token.
+func (gw *GithubWrapper) GetAuthenticatedUser() (login string, scopes []string, err error) {
+	user, resp, err := gw.Users.Get(context.Background(), "")
+	if err != nil {
+		return "", nil, apiError(err, "the authenticated user")
+	}
+	if resp != nil {
+		for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return user.GetLogin(), scopes, nil
+}
+
+// CreateRepo creates repo under owner, an organization or the authenticated user. It does
+// nothing when the repository already exists, so running it again is safe.
+func (gw *GithubWrapper) CreateRepo(owner string, repo string, description string, private bool) (created bool, err error) {
+	resource := "repository " + owner + "/" + repo
+	_, _, err = gw.Repositories.Get(context.Background(), owner, repo)
+	if err == nil || !isNotFound(err) {
+		return false, apiError(err, resource)
+	}
+	login, _, err := gw.GetAuthenticatedUser()
+	if err != nil {
+		return false, err
+	}
+	org := owner
+	if strings.EqualFold(login, owner) {
+		// An empty organization creates it for the authenticated user
+		org = ""
+	}
+	_, _, err = gw.Repositories.Create(context.Background(), org, &github.Repository{
+		Name:        github.String(repo),
+		Description: github.String(description),
+		Private:     github.Bool(private),
+	})
+	if err != nil {
+		return false, apiError(err, resource)
+	}
+	return true, nil
+}
+
+// PushFile commits file to path in repo, creating or replacing it. It does nothing when
+// the file already has that content.
+func (gw *GithubWrapper) PushFile(owner string, repo string, path string, file FileChange) (changed bool, err error) {
+	resource := fmt.Sprintf("file %s of repository %s/%s", path, owner, repo)
+	var getOpts *github.RepositoryContentGetOptions
+	if file.Branch != "" {
+		getOpts = &github.RepositoryContentGetOptions{Ref: file.Branch}
+	}
+	existing, _, _, err := gw.Repositories.GetContents(context.Background(), owner, repo, path, getOpts)
+	if err != nil && !isNotFound(err) {
+		return false, apiError(err, resource)
+	}
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.String(file.Message),
+		Content: file.Content,
+	}
+	if file.Branch != "" {
+		opts.Branch = github.String(file.Branch)
+	}
+	if existing == nil {
+		_, _, err = gw.Repositories.CreateFile(context.Background(), owner, repo, path, opts)
+		return err == nil, apiError(err, resource)
+	}
+	if content, err := existing.GetContent(); err == nil && bytes.Equal([]byte(content), file.Content) {
+		return false, nil
+	}
+	opts.SHA = existing.SHA
+	_, _, err = gw.Repositories.UpdateFile(context.Background(), owner, repo, path, opts)
+	return err == nil, apiError(err, resource)
+}
+
+// SaveLabel creates label in repo, or updates the color and description of the label with
+// the same name. It does nothing when they are already the same.
+func (gw *GithubWrapper) SaveLabel(owner string, repo string, label Label) (changed bool, err error) {
+	resource := fmt.Sprintf("label %s of repository %s/%s", label.Name, owner, repo)
+	existing, _, err := gw.Issues.GetLabel(context.Background(), owner, repo, label.Name)
+	if err != nil && !isNotFound(err) {
+		return false, apiError(err, resource)
+	}
+	update := &github.Label{Name: github.String(label.Name), Description: github.String(label.Description)}
+	if label.Color != "" {
+		update.Color = github.String(label.Color)
+	}
+	if existing == nil {
+		_, _, err = gw.Issues.CreateLabel(context.Background(), owner, repo, update)
+		return err == nil, apiError(err, resource)
+	}
+	if (label.Color == "" || strings.EqualFold(existing.GetColor(), label.Color)) && existing.GetDescription() == label.Description {
+		return false, nil
+	}
+	_, _, err = gw.Issues.EditLabel(context.Background(), owner, repo, label.Name, update)
+	return err == nil, apiError(err, resource)
+}
+
+// isNotFound reports whether GitHub answered err with 404 Not Found.
+func isNotFound(err error) bool {
+	var responseErr *github.ErrorResponse
+	return errors.As(err, &responseErr) && responseErr.Response != nil && responseErr.Response.StatusCode == http.StatusNotFound
+}
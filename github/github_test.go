@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/google/go-github/v65/github"
@@ -10,9 +11,17 @@ import (
 )
 
 type MockGithubRepositories struct {
-	mockListByUser        func(ctx context.Context, owner string, opt *github.RepositoryListByUserOptions) ([]*github.Repository, *github.Response, error)
-	mockListCollaborators func(ctx context.Context, owner, repo string, opts *github.ListCollaboratorsOptions) ([]*github.User, *github.Response, error)
-	mockAddCollaborator   func(ctx context.Context, owner, repo, user string, opts *github.RepositoryAddCollaboratorOptions) (*github.CollaboratorInvitation, *github.Response, error)
+	mockListByUser         func(ctx context.Context, owner string, opt *github.RepositoryListByUserOptions) ([]*github.Repository, *github.Response, error)
+	mockListCollaborators  func(ctx context.Context, owner, repo string, opts *github.ListCollaboratorsOptions) ([]*github.User, *github.Response, error)
+	mockAddCollaborator    func(ctx context.Context, owner, repo, user string, opts *github.RepositoryAddCollaboratorOptions) (*github.CollaboratorInvitation, *github.Response, error)
+	mockRemoveCollaborator func(ctx context.Context, owner, repo, user string) (*github.Response, error)
+	mockListInvitations    func(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryInvitation, *github.Response, error)
+	mockDeleteInvitation   func(ctx context.Context, owner, repo string, invitationID int64) (*github.Response, error)
+	mockGet                func(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+	mockCreate             func(ctx context.Context, org string, repo *github.Repository) (*github.Repository, *github.Response, error)
+	mockGetContents        func(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error)
+	mockCreateFile         func(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+	mockUpdateFile         func(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
 }
 
 func (m *MockGithubRepositories) ListByUser(ctx context.Context, owner string, opt *github.RepositoryListByUserOptions) ([]*github.Repository, *github.Response, error) {
@@ -27,6 +36,64 @@ func (m *MockGithubRepositories) AddCollaborator(ctx context.Context, owner, rep
 	return m.mockAddCollaborator(ctx, owner, repo, user, opts)
 }
 
+func (m *MockGithubRepositories) RemoveCollaborator(ctx context.Context, owner, repo, user string) (*github.Response, error) {
+	return m.mockRemoveCollaborator(ctx, owner, repo, user)
+}
+
+func (m *MockGithubRepositories) ListInvitations(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryInvitation, *github.Response, error) {
+	return m.mockListInvitations(ctx, owner, repo, opts)
+}
+
+func (m *MockGithubRepositories) DeleteInvitation(ctx context.Context, owner, repo string, invitationID int64) (*github.Response, error) {
+	return m.mockDeleteInvitation(ctx, owner, repo, invitationID)
+}
+
+func (m *MockGithubRepositories) Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error) {
+	return m.mockGet(ctx, owner, repo)
+}
+
+func (m *MockGithubRepositories) Create(ctx context.Context, org string, repo *github.Repository) (*github.Repository, *github.Response, error) {
+	return m.mockCreate(ctx, org, repo)
+}
+
+func (m *MockGithubRepositories) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	return m.mockGetContents(ctx, owner, repo, path, opts)
+}
+
+func (m *MockGithubRepositories) CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	return m.mockCreateFile(ctx, owner, repo, path, opts)
+}
+
+func (m *MockGithubRepositories) UpdateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	return m.mockUpdateFile(ctx, owner, repo, path, opts)
+}
+
+type MockGithubIssues struct {
+	mockGetLabel    func(ctx context.Context, owner, repo, name string) (*github.Label, *github.Response, error)
+	mockCreateLabel func(ctx context.Context, owner, repo string, label *github.Label) (*github.Label, *github.Response, error)
+	mockEditLabel   func(ctx context.Context, owner, repo, name string, label *github.Label) (*github.Label, *github.Response, error)
+}
+
+func (m *MockGithubIssues) GetLabel(ctx context.Context, owner, repo, name string) (*github.Label, *github.Response, error) {
+	return m.mockGetLabel(ctx, owner, repo, name)
+}
+
+func (m *MockGithubIssues) CreateLabel(ctx context.Context, owner, repo string, label *github.Label) (*github.Label, *github.Response, error) {
+	return m.mockCreateLabel(ctx, owner, repo, label)
+}
+
+func (m *MockGithubIssues) EditLabel(ctx context.Context, owner, repo, name string, label *github.Label) (*github.Label, *github.Response, error) {
+	return m.mockEditLabel(ctx, owner, repo, name, label)
+}
+
+type MockGithubUsers struct {
+	mockGet func(ctx context.Context, user string) (*github.User, *github.Response, error)
+}
+
+func (m *MockGithubUsers) Get(ctx context.Context, user string) (*github.User, *github.Response, error) {
+	return m.mockGet(ctx, user)
+}
+
 func TestGetRepos(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -172,3 +239,227 @@ func TestInviteCollaborator(t *testing.T) {
 		})
 	}
 }
+
+func TestInvitations(t *testing.T) {
+	var deleted int64
+	var removed string
+	mockRepo := &MockGithubRepositories{
+		mockListInvitations: func(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryInvitation, *github.Response, error) {
+			return []*github.RepositoryInvitation{{ID: github.Int64(7), Invitee: &github.User{Login: github.String("alumno1")}}}, nil, nil
+		},
+		mockDeleteInvitation: func(ctx context.Context, owner, repo string, invitationID int64) (*github.Response, error) {
+			deleted = invitationID
+			return nil, nil
+		},
+		mockRemoveCollaborator: func(ctx context.Context, owner, repo, user string) (*github.Response, error) {
+			removed = user
+			return nil, errors.New("failed to remove collaborator")
+		},
+	}
+	gw := &GithubWrapper{Repositories: mockRepo}
+
+	invitations, err := gw.GetInvitationsByRepo("owner1", "repo1")
+	assert.NoError(t, err)
+	assert.Equal(t, []Invitation{{Id: 7, Login: "alumno1"}}, invitations)
+
+	assert.NoError(t, gw.CancelInvitation("owner1", "repo1", 7))
+	assert.Equal(t, int64(7), deleted)
+
+	assert.EqualError(t, gw.RemoveCollaborator("owner1", "repo1", "alumno2"), "failed to remove collaborator")
+	assert.Equal(t, "alumno2", removed)
+}
+
+func TestGetAuthenticatedUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		mockError  error
+		wantLogin  string
+		wantScopes []string
+		wantErr    bool
+	}{
+		{
+			name:       "user with scopes",
+			header:     "repo, read:org",
+			wantLogin:  "user1",
+			wantScopes: []string{"repo", "read:org"},
+		},
+		{
+			name:      "token without scopes",
+			header:    "",
+			wantLogin: "user1",
+		},
+		{
+			name:      "bad credentials",
+			mockError: errors.New("401 Bad credentials"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsers := &MockGithubUsers{
+				mockGet: func(ctx context.Context, user string) (*github.User, *github.Response, error) {
+					if tt.mockError != nil {
+						return nil, nil, tt.mockError
+					}
+					resp := &github.Response{Response: &http.Response{Header: http.Header{}}}
+					resp.Header.Set("X-OAuth-Scopes", tt.header)
+					return &github.User{Login: github.String("user1")}, resp, nil
+				},
+			}
+			gw := &GithubWrapper{Users: mockUsers}
+			login, scopes, err := gw.GetAuthenticatedUser()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantLogin, login)
+				assert.Equal(t, tt.wantScopes, scopes)
+			}
+		})
+	}
+}
+
+func notFoundError() error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}, Message: "Not Found"}
+}
+
+func TestCreateRepo(t *testing.T) {
+	tests := []struct {
+		name        string
+		owner       string
+		getError    error
+		wantOrg     string
+		wantCreated bool
+		wantErr     bool
+	}{
+		{name: "already exists", owner: "utn", wantCreated: false},
+		{name: "organization repository", owner: "utn", getError: notFoundError(), wantOrg: "utn", wantCreated: true},
+		{name: "user repository", owner: "Alice", getError: notFoundError(), wantOrg: "", wantCreated: true},
+		{name: "lookup fails", owner: "utn", getError: errors.New("boom"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createdIn *string
+			mockRepo := &MockGithubRepositories{
+				mockGet: func(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error) {
+					return &github.Repository{}, nil, tt.getError
+				},
+				mockCreate: func(ctx context.Context, org string, repo *github.Repository) (*github.Repository, *github.Response, error) {
+					createdIn = &org
+					assert.Equal(t, "tp1", repo.GetName())
+					assert.True(t, repo.GetPrivate())
+					return repo, nil, nil
+				},
+			}
+			mockUsers := &MockGithubUsers{
+				mockGet: func(ctx context.Context, user string) (*github.User, *github.Response, error) {
+					return &github.User{Login: github.String("alice")}, nil, nil
+				},
+			}
+			gw := &GithubWrapper{Repositories: mockRepo, Users: mockUsers}
+			created, err := gw.CreateRepo(tt.owner, "tp1", "Trabajo practico 1", true)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCreated, created)
+			if tt.wantCreated {
+				assert.Equal(t, tt.wantOrg, *createdIn)
+			} else {
+				assert.Nil(t, createdIn)
+			}
+		})
+	}
+}
+
+func TestPushFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    *github.RepositoryContent
+		getError    error
+		wantCall    string
+		wantChanged bool
+		wantErr     bool
+	}{
+		{name: "new file", getError: notFoundError(), wantCall: "create", wantChanged: true},
+		{name: "changed file", existing: &github.RepositoryContent{Content: github.String("old"), SHA: github.String("abc")}, wantCall: "update", wantChanged: true},
+		{name: "same content", existing: &github.RepositoryContent{Content: github.String("hello")}, wantChanged: false},
+		{name: "lookup fails", getError: errors.New("boom"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var call string
+			mockRepo := &MockGithubRepositories{
+				mockGetContents: func(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+					assert.Equal(t, "main", opts.Ref)
+					return tt.existing, nil, nil, tt.getError
+				},
+				mockCreateFile: func(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+					call = "create"
+					assert.Nil(t, opts.SHA)
+					return nil, nil, nil
+				},
+				mockUpdateFile: func(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+					call = "update"
+					assert.Equal(t, "abc", opts.GetSHA())
+					assert.Equal(t, "main", opts.GetBranch())
+					assert.Equal(t, []byte("hello"), opts.Content)
+					return nil, nil, nil
+				},
+			}
+			gw := &GithubWrapper{Repositories: mockRepo}
+			changed, err := gw.PushFile("utn", "tp1", "README.md", FileChange{Content: []byte("hello"), Message: "Add README", Branch: "main"})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.Equal(t, tt.wantCall, call)
+		})
+	}
+}
+
+func TestSaveLabel(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    *github.Label
+		getError    error
+		wantCall    string
+		wantChanged bool
+	}{
+		{name: "new label", getError: notFoundError(), wantCall: "create", wantChanged: true},
+		{name: "different color", existing: &github.Label{Color: github.String("ffffff"), Description: github.String("Delivered")}, wantCall: "edit", wantChanged: true},
+		{name: "same label", existing: &github.Label{Color: github.String("0E8A16"), Description: github.String("Delivered")}, wantChanged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var call string
+			mockIssues := &MockGithubIssues{
+				mockGetLabel: func(ctx context.Context, owner, repo, name string) (*github.Label, *github.Response, error) {
+					return tt.existing, nil, tt.getError
+				},
+				mockCreateLabel: func(ctx context.Context, owner, repo string, label *github.Label) (*github.Label, *github.Response, error) {
+					call = "create"
+					return label, nil, nil
+				},
+				mockEditLabel: func(ctx context.Context, owner, repo, name string, label *github.Label) (*github.Label, *github.Response, error) {
+					call = "edit"
+					assert.Equal(t, "0e8a16", label.GetColor())
+					return label, nil, nil
+				},
+			}
+			gw := &GithubWrapper{Issues: mockIssues}
+			changed, err := gw.SaveLabel("utn", "tp1", Label{Name: "delivered", Color: "0e8a16", Description: "Delivered"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.Equal(t, tt.wantCall, call)
+		})
+	}
+}
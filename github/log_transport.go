@@ -0,0 +1,57 @@
+package github
+
+import (
+	"github.com/ffumaneri/github-cli/common"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LogTransport is an http.RoundTripper logging, at debug level, every request sent to
+// GitHub with its status and how long it took.
+type LogTransport struct {
+	Base http.RoundTripper
+	// Logger writes the records, the default logger when nil
+	Logger *slog.Logger
+}
+
+func NewLogTransport(base http.RoundTripper) *LogTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &LogTransport{Base: base}
+}
+
+func (t *LogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := t.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !logger.Enabled(req.Context(), slog.LevelDebug) {
+		return t.Base.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	attrs := []any{"method", req.Method, "path", req.URL.Path}
+	if repo := RequestRepo(req); repo != "" {
+		attrs = append(attrs, common.LogRepo, repo)
+	}
+	attrs = append(attrs, common.LogDuration, time.Since(start))
+	if err != nil {
+		logger.Debug("github request failed", append(attrs, "error", err)...)
+	} else {
+		logger.Debug("github request", append(attrs, "status", resp.StatusCode)...)
+	}
+	return resp, err
+}
+
+// RequestRepo returns owner/name of the repository a REST request is about, empty when
+// its route is not below /repos.
+func RequestRepo(req *http.Request) string {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "repos" {
+		return ""
+	}
+	return parts[1] + "/" + parts[2]
+}
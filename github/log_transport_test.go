@@ -0,0 +1,41 @@
+package github
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+	var out bytes.Buffer
+	transport := NewLogTransport(nil)
+	transport.Logger = slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/repos/utn/tp1/collaborators")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	_, err = (&http.Client{Transport: &LogTransport{Base: failingTransport{}, Logger: transport.Logger}}).Get("https://api.github.com/user")
+	assert.Error(t, err)
+
+	assert.Contains(t, out.String(), `level=DEBUG msg="github request" method=GET path=/repos/utn/tp1/collaborators repo=utn/tp1 duration=`)
+	assert.Contains(t, out.String(), "status=304")
+	assert.Contains(t, out.String(), `msg="github request failed" method=GET path=/user duration=`)
+	assert.Contains(t, out.String(), `error="connection refused"`)
+
+	// Nothing is logged above debug
+	out.Reset()
+	transport.Logger = slog.New(slog.NewTextHandler(&out, nil))
+	resp, err = client.Get(server.URL + "/repos/utn/tp1")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, out.String())
+}
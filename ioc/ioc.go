@@ -1,6 +1,8 @@
 package ioc
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"github.com/ffumaneri/github-cli/common"
 	"github.com/ffumaneri/github-cli/common/viper"
@@ -12,74 +14,266 @@ import (
 	"github.com/tmc/langchaingo/documentloaders"
 	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/anthropic"
 	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/textsplitter"
 	"github.com/tmc/langchaingo/vectorstores"
-	"github.com/tmc/langchaingo/vectorstores/qdrant"
-	"log"
+	"golang.org/x/term"
+	"io"
+	"log/slog"
+	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Container defines an interface for initializing services and clients.
+// Services fail with an error when the configuration they need is missing or invalid.
 type Container interface {
-	NewGithubService() services.IGithubService
-	NewOllamaService() services.ILangChainService
+	NewGithubService() (services.IGithubService, error)
+	// NewGithubServiceWithConsumer returns a GitHub service sending its messages to consumer instead of stdout.
+	NewGithubServiceWithConsumer(consumer func(data string)) (services.IGithubService, error)
+	NewOllamaService() (services.ILangChainService, error)
+	NewAuthService() (services.IAuthService, error)
+	NewConfigService() services.IConfigService
+	NewPluginService() services.IPluginService
+	NewAuditService() (services.IAuditService, error)
+	NewCacheService() (services.ICacheService, error)
+	NewAliasService() services.IAliasService
+	NewBatchService() (services.IBatchService, error)
+	// ContextNames lists the contexts loaded in the vector store, which only needs its configuration.
+	ContextNames() ([]string, error)
+	// Close releases the connections held by the clients created so far.
+	Close() error
 }
 
-// AppContainer is a concrete implementation of Container.
-type AppContainer struct{}
+// AppContainer is a concrete implementation of Container. Clients are created on first
+// use and shared by every service, the zero value is ready to use.
+type AppContainer struct {
+	mu           sync.Mutex
+	transport    *http.Transport
+	githubClient *github.Client
+	owner        string
+	llm          llms.Model
+	embedder     embeddings.Embedder
+	stores       map[string]vectorstores.VectorStore
+}
+
+func (ioc *AppContainer) NewGithubService() (services.IGithubService, error) {
+	return ioc.NewGithubServiceWithConsumer(func(data string) {
+		fmt.Println(data)
+	})
+}
 
-func (ioc *AppContainer) NewGithubService() services.IGithubService {
-	ghClient, owner := ioc.getGithubClient()
+func (ioc *AppContainer) NewGithubServiceWithConsumer(consumer func(data string)) (services.IGithubService, error) {
+	ghClient, owner, err := ioc.getGithubClient()
+	if err != nil {
+		return nil, err
+	}
 	ghWrapper := github2.NewGithubWrapper(ghClient, owner)
-	return services.NewGithubService(owner, ghWrapper, func(data string) {
+	return services.NewGithubService(owner, ghWrapper, consumer), nil
+}
+
+func (ioc *AppContainer) NewAuthService() (services.IAuthService, error) {
+	store, err := common.NewFileCredentialsStore()
+	if err != nil {
+		return nil, err
+	}
+	return services.NewAuthService(store, func(options services.LoginOptions) github2.IDeviceFlow {
+		return github2.NewDeviceFlow(options.ClientId, options.DeviceCodeUrl, options.TokenUrl, options.Scopes, ioc.loggedHttpClient())
+	}, func(token string) github2.IGithubWrapper {
+		return github2.NewGithubWrapper(github.NewClient(ioc.loggedHttpClient()).WithAuthToken(token), "")
+	}, func(data string) {
+		fmt.Println(data)
+	}), nil
+}
+
+func (ioc *AppContainer) NewConfigService() services.IConfigService {
+	return services.NewConfigService(viper.ViperLoadConfig, viper.ViperUpdateConfig, prompt, services.ConfigProbes{
+		GithubUser: func(config *common.Config) (string, error) {
+			githubConfig, err := config.Github()
+			if err != nil {
+				return "", err
+			}
+			ghClient, owner, err := NewGithubClient(githubConfig, ioc.httpClient(), nil)
+			if err != nil {
+				return "", err
+			}
+			login, _, err := github2.NewGithubWrapper(ghClient, owner).GetAuthenticatedUser()
+			return login, err
+		},
+		Llm: func(config *common.Config) error {
+			switch config.Provider() {
+			case common.LlmProviderOllama:
+				return ollama2.PingOllama(withDefault(config.Ollama_Url, ollama2.OllamaUrl()), config.Ollama_Model)
+			case common.LlmProviderOpenAI:
+				return ollama2.PingOpenAI(config.Openai_Url, config.Openai_Api_Key, config.Openai_Model)
+			case common.LlmProviderAnthropic:
+				return ollama2.PingAnthropic(withDefault(config.Anthropic_Url, ollama2.DefaultAnthropicUrl),
+					withDefault(config.Anthropic_Api_Key, os.Getenv("ANTHROPIC_API_KEY")), config.Anthropic_Model)
+			}
+			return nil
+		},
+		Qdrant: func(config *common.Config) error {
+			return ollama2.PingQdrant(config.Qdrant_Url)
+		},
+	}, func(data string) {
 		fmt.Println(data)
 	})
 }
 
-func (ioc *AppContainer) NewOllamaService() services.ILangChainService {
-	llm := ioc.getLLMClient()
+func (ioc *AppContainer) NewPluginService() services.IPluginService {
+	return services.NewPluginService(common.FindPlugins, common.LookPlugin, ioc.pluginEnv, runPlugin, func(data string) {
+		fmt.Println(data)
+	})
+}
 
-	split := textsplitter.NewRecursiveCharacter()
-	split.ChunkSize = 300   // size of the chunk is number of characters
-	split.ChunkOverlap = 30 // overlap is the number of characters that the chunks overlap
+func (ioc *AppContainer) NewAuditService() (services.IAuditService, error) {
+	path, err := common.AuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	return services.NewAuditService(common.NewAuditLog(path).Entries, func(data string) {
+		fmt.Println(data)
+	}), nil
+}
 
-	ollamaWrapper := ollama2.NewLangChainWrapper(llm, &common.FS{}, func(llm2 llms.Model) embeddings.Embedder {
-		ollamaEmbeder, err := embeddings.NewEmbedder(llm2.(*ollama.LLM))
-		if err != nil {
-			log.Fatal(err)
+func (ioc *AppContainer) NewCacheService() (services.ICacheService, error) {
+	dir, err := common.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return services.NewCacheService(dir, os.RemoveAll, func(data string) {
+		fmt.Println(data)
+	}), nil
+}
+
+func (ioc *AppContainer) NewAliasService() services.IAliasService {
+	return services.NewAliasService(viper.ViperLoadConfig, viper.ViperUpdateConfig, runShell, func(data string) {
+		fmt.Println(data)
+	})
+}
+
+func (ioc *AppContainer) NewBatchService() (services.IBatchService, error) {
+	// The batch prints a line per step, the messages of steps running at the same time
+	// would interleave with them
+	ghService, err := ioc.NewGithubServiceWithConsumer(func(data string) {})
+	if err != nil {
+		return nil, err
+	}
+	return services.NewBatchService(ghService, os.ReadFile, func(data string) {
+		fmt.Println(data)
+	}), nil
+}
+
+// pluginEnv exposes the resolved owner, token and profile to plugins, as the variables
+// github-cli itself reads, so a plugin calling github-cli gets the same configuration.
+func (ioc *AppContainer) pluginEnv() ([]string, error) {
+	config, err := common.NewConfig(viper.ViperLoadConfig)
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	add := func(key, value string) {
+		if value != "" {
+			env = append(env, common.EnvVarPrefix+key+"="+value)
 		}
-		return ollamaEmbeder
-	}, func(embeder embeddings.Embedder, collectionName string) vectorstores.VectorStore {
-		config, err := common.NewConfig(viper.ViperLoadConfig)
+	}
+	add("CONFIG", common.ExplicitConfigFile())
+	add("PROFILE", config.ProfileName())
+	add("OWNER", config.Owner)
+	// Plugins that do not call GitHub work without a token
+	if token, err := ioc.githubToken(&config.GithubConfig); err == nil {
+		add("TOKEN", token)
+	}
+	return env, nil
+}
+
+// githubToken returns the token used to call the API, an installation token for GitHub Apps.
+func (ioc *AppContainer) githubToken(config *common.GithubConfig) (string, error) {
+	if !config.UsesGithubApp() {
+		return config.ResolveToken()
+	}
+	transport, err := github2.NewAppTransportFromFile(ioc.httpClient().Transport, config.App_Id, config.App_Installation_Id, config.App_Private_Key)
+	if err != nil {
+		return "", err
+	}
+	return transport.Token()
+}
+
+func runPlugin(path string, args []string, env []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.Run()
+}
+
+// runShell runs the script of a shell alias with sh, connected to the standard input and
+// output of the CLI.
+func runShell(name, script string, args []string) error {
+	cmd := exec.Command("sh", append([]string{"-c", script, name}, args...)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// prompt asks for a value on the terminal, secrets are read without echo when stdin is a terminal.
+func prompt(label, defaultValue string, secret bool) (string, error) {
+	if defaultValue != "" {
+		fmt.Printf("%s [%s]: ", label, defaultValue)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	var answer string
+	if secret && term.IsTerminal(int(os.Stdin.Fd())) {
+		bytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
 		if err != nil {
-			panic("error getting config")
+			return "", err
 		}
-		// Create a new Qdrant vector store.
-		quadrantUrl, err := url.Parse(config.Qdrant_Url)
-		if err != nil {
-			log.Fatal(err)
+		answer = string(bytes)
+	} else {
+		line, err := stdinReader.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			return "", err
 		}
+		answer = line
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
 
-		store, err := qdrant.New(
-			qdrant.WithURL(*quadrantUrl),
-			qdrant.WithCollectionName(collectionName),
-			qdrant.WithEmbedder(embeder),
-		)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return store
-	}, func() textsplitter.TextSplitter {
-		split := textsplitter.NewRecursiveCharacter()
-		split.ChunkSize = 300   // size of the chunk is number of characters
-		split.ChunkOverlap = 30 // overlap is the number of characters that the chunks overlap
-		return split
+func (ioc *AppContainer) NewOllamaService() (services.ILangChainService, error) {
+	llm, err := ioc.getLLMClient()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := common.NewConfig(viper.ViperLoadConfig)
+	if err != nil {
+		return nil, err
+	}
+	chunkSizes, err := config.ChunkSizes()
+	if err != nil {
+		return nil, err
+	}
+
+	ollamaWrapper := ollama2.NewLangChainWrapper(llm, &common.FS{}, ioc.getEmbedder, ioc.getStore, func(path string) textsplitter.TextSplitter {
+		language := ollama2.LanguageOf(path)
+		return ollama2.NewCodeSplitter(language, chunkSizes[language])
 	}, func(filePath string, size int64) (documentloaders.Loader, *os.File) {
 		f, err := os.Open(filePath)
 		if err != nil {
-			fmt.Println("Error opening file: ", err)
+			slog.Warn("error opening file", common.LogFile, filePath, "error", err)
 			return nil, nil
 		}
 
@@ -87,44 +281,349 @@ func (ioc *AppContainer) NewOllamaService() services.ILangChainService {
 
 		return p, f
 	}, func(model llms.Model, retriever vectorstores.Retriever) chains.Chain {
-		return chains.NewRetrievalQAFromLLM(model, retriever)
-	})
+		retrievalQA := chains.NewRetrievalQAFromLLM(model, retriever)
+		// Answers list the chunks they come from
+		retrievalQA.ReturnSourceDocuments = true
+		return retrievalQA
+	}, contextNames, manifestPath, gitCommit)
 	return services.NewLangChainService(ollamaWrapper, func(chunk []byte) {
 		fmt.Print(string(chunk))
-	})
+	}), nil
 }
-func NewGithubClient(config *common.Config) (*github.Client, string, error) {
+
+// Close releases the idle connections of the clients and forgets them, so the next
+// service creates new ones.
+func (ioc *AppContainer) Close() error {
+	ioc.mu.Lock()
+	defer ioc.mu.Unlock()
+	if ioc.transport != nil {
+		ioc.transport.CloseIdleConnections()
+	}
+	// The Qdrant store always uses the default client
+	http.DefaultClient.CloseIdleConnections()
+	ioc.transport = nil
+	ioc.githubClient = nil
+	ioc.owner = ""
+	ioc.llm = nil
+	ioc.embedder = nil
+	ioc.stores = nil
+	return nil
+}
+
+// NewGithubClient creates the client of the configured owner. GET responses are cached
+// and revalidated with conditional requests. The requests changing something are
+// recorded with audit when it is not nil, and with --dry-run they are logged instead of
+// sent. Every request sent is logged at debug level.
+func NewGithubClient(config *common.GithubConfig, httpClient *http.Client, audit func(entry common.AuditEntry)) (*github.Client, string, error) {
+	// Only the requests actually sent are logged, not the ones answered from the cache
+	logged := *httpClient
+	logged.Transport = github2.NewLogTransport(httpClient.Transport)
+	httpClient = &logged
+	if config.UsesGithubApp() {
+		// Authenticate as a GitHub App installation, tokens are refreshed by the transport
+		transport, err := github2.NewAppTransportFromFile(httpClient.Transport, config.App_Id, config.App_Installation_Id, config.App_Private_Key)
+		if err != nil {
+			return nil, "", err
+		}
+		// Installation tokens are requested below the guards, they are neither recorded nor skipped
+		appClient, err := cached(&http.Client{Transport: transport}, fmt.Sprintf("app %d installation %d", config.App_Id, config.App_Installation_Id))
+		if err != nil {
+			return nil, "", err
+		}
+		return github.NewClient(guardWrites(appClient, audit)), config.Owner, nil
+	}
+	token, err := config.ResolveToken()
+	if err != nil {
+		return nil, "", err
+	}
 	// Create Github client
-	client := github.NewClient(nil).WithAuthToken(config.Token)
+	cachedClient, err := cached(httpClient, token)
+	if err != nil {
+		return nil, "", err
+	}
+	client := github.NewClient(guardWrites(cachedClient, audit)).WithAuthToken(token)
 	return client, config.Owner, nil
 }
 
-func (ioc *AppContainer) getGithubClient() (*github.Client, string) {
+// cached returns a copy of httpClient reusing the GitHub responses stored for
+// credentials, unless --no-cache is set or there is no cache directory. With --offline
+// only the stored responses are used, each one logged with its date.
+func cached(httpClient *http.Client, credentials string) (*http.Client, error) {
+	if common.NoCache {
+		return httpClient, nil
+	}
+	dir, err := common.CacheDir()
+	if err != nil && common.Offline {
+		return nil, common.WrapError(common.KindValidation, err, "offline mode needs a cache directory")
+	}
+	if err != nil {
+		return httpClient, nil
+	}
+	transport := github2.NewCacheTransport(httpClient.Transport, filepath.Join(dir, common.HttpCacheDirName), credentials)
+	if common.Offline {
+		transport.Offline = func(req *http.Request, asOf time.Time) {
+			slog.Info("offline, answered from the cache", "path", req.URL.Path, common.LogRepo, github2.RequestRepo(req),
+				"as_of", asOf.Local().Format(time.DateTime))
+		}
+	}
+	cachedClient := *httpClient
+	cachedClient.Transport = transport
+	return &cachedClient, nil
+}
+
+// guardWrites returns a copy of httpClient recording the requests changing something
+// with audit and, with --dry-run, logging them instead of sending them. Dry runs change
+// nothing, so they are not recorded.
+func guardWrites(httpClient *http.Client, audit func(entry common.AuditEntry)) *http.Client {
+	guarded := *httpClient
+	if audit != nil {
+		guarded.Transport = github2.NewAuditTransport(guarded.Transport, audit)
+	}
+	if common.DryRun {
+		guarded.Transport = github2.NewDryRunTransport(guarded.Transport, func(req *http.Request, body string) {
+			slog.Info("dry run, not sent", "method", req.Method, "path", req.URL.RequestURI(), common.LogRepo, github2.RequestRepo(req), "body", body)
+		})
+	}
+	return &guarded
+}
+
+// auditRecorder appends the entries to the audit log of the user with the profile and
+// command line. Failing to write is reported but does not fail the command, the change
+// on GitHub was already made.
+func auditRecorder(profile string) func(entry common.AuditEntry) {
+	command := strings.Join(append([]string{common.AppName}, os.Args[1:]...), " ")
+	return func(entry common.AuditEntry) {
+		entry.Profile, entry.Command = profile, command
+		path, err := common.AuditLogPath()
+		if err == nil {
+			err = common.NewAuditLog(path).Append(entry)
+		}
+		if err != nil {
+			slog.Warn("cannot write the audit log", common.LogRepo, entry.Repo, "error", err)
+		}
+	}
+}
+
+// httpClient returns a client sharing the container transport, so Close can release its connections.
+func (ioc *AppContainer) httpClient() *http.Client {
+	ioc.mu.Lock()
+	defer ioc.mu.Unlock()
+	return ioc.httpClientLocked()
+}
+
+// loggedHttpClient returns httpClient logging every request at debug level.
+func (ioc *AppContainer) loggedHttpClient() *http.Client {
+	httpClient := ioc.httpClient()
+	httpClient.Transport = github2.NewLogTransport(httpClient.Transport)
+	return httpClient
+}
+
+// httpClientLocked is httpClient for the callers already holding ioc.mu.
+func (ioc *AppContainer) httpClientLocked() *http.Client {
+	if ioc.transport == nil {
+		ioc.transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	return &http.Client{Transport: ioc.transport}
+}
+
+// getGithubClient returns the GitHub client, created once even when batch steps ask for
+// it concurrently.
+func (ioc *AppContainer) getGithubClient() (*github.Client, string, error) {
+	ioc.mu.Lock()
+	defer ioc.mu.Unlock()
+	if ioc.githubClient != nil {
+		return ioc.githubClient, ioc.owner, nil
+	}
 	config, err := common.NewConfig(viper.ViperLoadConfig)
 	if err != nil {
-		panic("error getting config")
+		return nil, "", err
+	}
+	githubConfig, err := config.Github()
+	if err != nil {
+		return nil, "", err
 	}
-	ghClient, owner, err := NewGithubClient(config)
+	ghClient, owner, err := NewGithubClient(githubConfig, ioc.httpClientLocked(), auditRecorder(config.ProfileName()))
 	if err != nil {
-		panic("error getting config")
+		return nil, "", err
 	}
-	return ghClient, owner
+	ioc.githubClient, ioc.owner = ghClient, owner
+	return ghClient, owner, nil
 }
-func NewOllamaClient(config *common.Config) (llm *ollama.LLM, ok bool) {
-	llm, err := ollama.New(ollama.WithModel(config.Ollama_Model))
+
+// NewLlmClient creates the model of the provider selected with llm_provider.
+func NewLlmClient(config *common.LlmConfig, httpClient *http.Client) (llm llms.Model, err error) {
+	switch config.Provider() {
+	case common.LlmProviderOpenAI:
+		options := []openai.Option{openai.WithBaseURL(config.Openai_Url), openai.WithModel(config.Openai_Model), openai.WithHTTPClient(httpClient)}
+		if config.Openai_Embedding_Model != "" {
+			options = append(options, openai.WithEmbeddingModel(config.Openai_Embedding_Model))
+		}
+		if apiKey := withDefault(config.Openai_Api_Key, os.Getenv("OPENAI_API_KEY")); apiKey != "" {
+			options = append(options, openai.WithToken(apiKey))
+		} else {
+			// Local servers like llama.cpp accept any key, but the client requires one
+			options = append(options, openai.WithToken("none"))
+		}
+		llm, err = openai.New(options...)
+	case common.LlmProviderAnthropic:
+		options := []anthropic.Option{anthropic.WithModel(config.Anthropic_Model), anthropic.WithHTTPClient(httpClient)}
+		if config.Anthropic_Url != "" {
+			options = append(options, anthropic.WithBaseURL(config.Anthropic_Url))
+		}
+		if config.Anthropic_Api_Key != "" {
+			options = append(options, anthropic.WithToken(config.Anthropic_Api_Key))
+		}
+		llm, err = anthropic.New(options...)
+	default:
+		options := []ollama.Option{ollama.WithModel(config.Ollama_Model), ollama.WithHTTPClient(httpClient)}
+		if config.Ollama_Url != "" {
+			options = append(options, ollama.WithServerURL(config.Ollama_Url))
+		}
+		llm, err = ollama.New(options...)
+	}
 	if err != nil {
-		panic(fmt.Errorf("error getting ollama client with model %s: %w", config.Ollama_Model, err))
+		return nil, common.Errorf(common.KindAiBackend, "error getting %s client with model %s: %w", config.Provider(), config.Model(), err)
 	}
-	return llm, true
+	return llm, nil
 }
-func (ioc *AppContainer) getLLMClient() *ollama.LLM {
+
+func (ioc *AppContainer) getLLMClient() (llms.Model, error) {
+	ioc.mu.Lock()
+	defer ioc.mu.Unlock()
+	if ioc.llm != nil {
+		return ioc.llm, nil
+	}
 	config, err := common.NewConfig(viper.ViperLoadConfig)
 	if err != nil {
-		panic("error getting config")
+		return nil, err
+	}
+	llmConfig, err := config.Llm()
+	if err != nil {
+		return nil, err
+	}
+	llm, err := NewLlmClient(llmConfig, ioc.httpClientLocked())
+	if err != nil {
+		return nil, err
+	}
+	ioc.llm = llm
+	return llm, nil
+}
+
+func (ioc *AppContainer) getEmbedder(llm llms.Model) (embeddings.Embedder, error) {
+	ioc.mu.Lock()
+	defer ioc.mu.Unlock()
+	if ioc.embedder != nil {
+		return ioc.embedder, nil
 	}
-	llmClient, ok := NewOllamaClient(config)
+	// Anthropic style endpoints do not create embeddings
+	client, ok := llm.(embeddings.EmbedderClient)
 	if !ok {
-		panic("error getting config")
+		return nil, common.NewError(common.KindValidation, "the configured llm_provider cannot create embeddings, use ollama or openai for contexts")
+	}
+	embedder, err := embeddings.NewEmbedder(client)
+	if err != nil {
+		return nil, err
+	}
+	ioc.embedder = embedder
+	return embedder, nil
+}
+
+// getStore returns the vector store of the collection, created once per collection.
+func (ioc *AppContainer) getStore(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
+	config, err := common.NewConfig(viper.ViperLoadConfig)
+	if err != nil {
+		return nil, err
+	}
+	vectorStoreConfig, err := config.VectorStore()
+	if err != nil {
+		return nil, err
+	}
+
+	ioc.mu.Lock()
+	defer ioc.mu.Unlock()
+	if store, ok := ioc.stores[collectionName]; ok {
+		return store, nil
+	}
+	store, err := NewVectorStore(vectorStoreConfig, embedder, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if ioc.stores == nil {
+		ioc.stores = map[string]vectorstores.VectorStore{}
+	}
+	ioc.stores[collectionName] = store
+	return store, nil
+}
+
+func (ioc *AppContainer) ContextNames() ([]string, error) {
+	return contextNames()
+}
+
+// contextNames lists the collections of the configured vector store.
+func contextNames() ([]string, error) {
+	config, err := common.NewConfig(viper.ViperLoadConfig)
+	if err != nil {
+		return nil, err
+	}
+	vectorStoreConfig, err := config.VectorStore()
+	if err != nil {
+		return nil, err
+	}
+	if vectorStoreConfig.Kind() == common.VectorStoreLocal {
+		dir, err := vectorStoreConfig.LocalStoreDir()
+		if err != nil {
+			return nil, err
+		}
+		return ollama2.LocalCollections(dir)
+	}
+	return ollama2.QdrantCollections(vectorStoreConfig.Qdrant_Url)
+}
+
+// NewVectorStore creates the store selected with vector_store for the collection.
+func NewVectorStore(config *common.VectorStoreConfig, embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
+	if config.Kind() == common.VectorStoreLocal {
+		dir, err := config.LocalStoreDir()
+		if err != nil {
+			return nil, err
+		}
+		return ollama2.NewLocalStore(dir, collectionName, embedder)
+	}
+	quadrantUrl, err := url.Parse(config.Qdrant_Url)
+	if err != nil {
+		return nil, common.Errorf(common.KindValidation, "invalid qdrant_url: %w", err)
+	}
+	return ollama2.NewQdrantStore(*quadrantUrl, collectionName, embedder)
+}
+
+// gitCommit returns the HEAD of the git repository of dir, empty when dir is not in one.
+func gitCommit(dir string) string {
+	output, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// manifestPath returns the file keeping what was loaded in the collection.
+func manifestPath(collectionName string) (string, error) {
+	config, err := common.NewConfig(viper.ViperLoadConfig)
+	if err != nil {
+		return "", err
+	}
+	vectorStoreConfig, err := config.VectorStore()
+	if err != nil {
+		return "", err
+	}
+	dir, err := vectorStoreConfig.ManifestDir()
+	if err != nil {
+		return "", err
+	}
+	return ollama2.ManifestPath(dir, collectionName), nil
+}
+
+func withDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
 	}
-	return llmClient
+	return value
 }
@@ -1,9 +1,17 @@
 package ioc
 
 import (
+	"context"
+	"github.com/ffumaneri/github-cli/common"
+	ollama2 "github.com/ffumaneri/github-cli/lang_chain"
 	"github.com/ffumaneri/github-cli/services"
+	"github.com/google/go-github/v65/github"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/tmc/langchaingo/embeddings"
+	"net/http"
+	"os"
+	"os/exec"
 	"testing"
 )
 
@@ -62,3 +70,122 @@ func TestMockContainer(t *testing.T) {
 		mockContainer.AssertExpectations(t)
 	})
 }
+
+func TestAppContainer_LazyClients(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GITHUB_CLI_OWNER", "utn")
+	t.Setenv("GITHUB_CLI_TOKEN", "token")
+	// Keep any project config file away from the test
+	wd, _ := os.Getwd()
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+	container := &AppContainer{}
+
+	_, err := container.NewGithubService()
+	assert.NoError(t, err)
+	client := container.githubClient
+	_, err = container.NewGithubService()
+	assert.NoError(t, err)
+	assert.Same(t, client, container.githubClient)
+
+	// Batch steps create their services concurrently
+	container = &AppContainer{}
+	clients := make(chan *github.Client, 10)
+	for i := 0; i < cap(clients); i++ {
+		go func() {
+			client, _, err := container.getGithubClient()
+			assert.NoError(t, err)
+			clients <- client
+		}()
+	}
+	client = <-clients
+	for i := 1; i < cap(clients); i++ {
+		assert.Same(t, client, <-clients)
+	}
+
+	// Only the AI commands need an Ollama model
+	_, err = container.NewOllamaService()
+	var missing *common.MissingKeyError
+	if assert.ErrorAs(t, err, &missing) {
+		assert.Equal(t, "ollama_model", missing.Key)
+	}
+
+	assert.NoError(t, container.Close())
+	assert.Nil(t, container.githubClient)
+	assert.Nil(t, container.transport)
+}
+
+func TestNewLlmClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     common.LlmConfig
+		embeddings bool
+	}{
+		{"Ollama", common.LlmConfig{Ollama_Model: "llama3.2", Ollama_Url: "http://gpu-box:11434"}, true},
+		{"OpenAI compatible", common.LlmConfig{Llm_Provider: "openai", Openai_Url: "http://localhost:8080/v1", Openai_Model: "qwen2.5-coder"}, true},
+		{"Anthropic", common.LlmConfig{Llm_Provider: "anthropic", Anthropic_Model: "claude", Anthropic_Api_Key: "key"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container := &AppContainer{}
+			llm, err := NewLlmClient(&tt.config, container.httpClient())
+			assert.NoError(t, err)
+
+			_, err = container.getEmbedder(llm)
+			assert.Equal(t, tt.embeddings, err == nil)
+		})
+	}
+}
+
+type embedderClient struct{}
+
+func (embedderClient) CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+
+func TestNewVectorStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewVectorStore(&common.VectorStoreConfig{Vector_Store_Dir: dir}, nil, "utn")
+	assert.NoError(t, err)
+	assert.IsType(t, &ollama2.LocalStore{}, store)
+
+	embedder, _ := embeddings.NewEmbedder(embedderClient{})
+	store, err = NewVectorStore(&common.VectorStoreConfig{Qdrant_Url: "http://localhost:6333"}, embedder, "utn")
+	assert.NoError(t, err)
+	assert.IsType(t, &ollama2.QdrantStore{}, store)
+}
+
+func TestNewGithubClient_DryRun(t *testing.T) {
+	defer func() { common.DryRun = false }()
+	common.DryRun = true
+	httpClient := (&AppContainer{}).httpClient()
+
+	var audited []common.AuditEntry
+	client, _, err := NewGithubClient(&common.GithubConfig{Owner: "utn", Token: "token"}, httpClient, func(entry common.AuditEntry) {
+		audited = append(audited, entry)
+	})
+	assert.NoError(t, err)
+	// The invitation is not sent, so it succeeds without reaching GitHub
+	_, _, err = client.Repositories.AddCollaborator(context.Background(), "utn", "api", "alice", nil)
+	assert.NoError(t, err)
+	// Nothing was done, so nothing is recorded
+	assert.Empty(t, audited)
+	// The shared client is left as is
+	assert.IsType(t, &http.Transport{}, httpClient.Transport)
+}
+
+func TestGitCommit(t *testing.T) {
+	assert.Equal(t, "", gitCommit(t.TempDir()))
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=utn", "-c", "user.email=utn@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(output))
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	assert.Regexp(t, `^[0-9a-f]{40}$`, gitCommit(dir))
+}
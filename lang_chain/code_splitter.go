@@ -0,0 +1,243 @@
+package lang_chain
+
+import (
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/tmc/langchaingo/textsplitter"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var languageExtensions = map[string]string{
+	".go":   common.LanguageGo,
+	".py":   common.LanguagePython,
+	".js":   common.LanguageJavaScript,
+	".jsx":  common.LanguageJavaScript,
+	".mjs":  common.LanguageJavaScript,
+	".cjs":  common.LanguageJavaScript,
+	".ts":   common.LanguageTypeScript,
+	".tsx":  common.LanguageTypeScript,
+	".java": common.LanguageJava,
+}
+
+// LanguageOf returns the language of the file at path by its extension,
+// common.LanguageDefault when it has no splitter of its own.
+func LanguageOf(path string) string {
+	if language, ok := languageExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return language
+	}
+	return common.LanguageDefault
+}
+
+// declaration is a top level declaration of a text, or a member of a class, starting
+// at the line of its doc comment.
+type declaration struct {
+	start, end int // offsets in the text
+	name       string
+}
+
+// boundaryRule finds where the declarations of a language start by looking at lines.
+type boundaryRule struct {
+	// declaration matches the lines starting a declaration, its first non empty group is
+	// the name of the declaration
+	declaration *regexp.Regexp
+	// attached matches the lines kept with the declaration below them, comments and annotations
+	attached *regexp.Regexp
+}
+
+var jsRule = boundaryRule{
+	declaration: regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?(?:function\*?|class|abstract\s+class|const|let|var|interface|type|enum|namespace|declare)\b\s*([\w$]*)|^module\.exports\b`),
+	attached:    regexp.MustCompile(`^\s*(//|/\*|\*)|^@`),
+}
+
+var boundaryRules = map[string]boundaryRule{
+	// Methods are indented once inside their class
+	common.LanguagePython: {
+		declaration: regexp.MustCompile(`^(?:\t| {0,4})(?:async\s+def|def|class)\s+(\w*)`),
+		attached:    regexp.MustCompile(`^\s*(#|@)`),
+	},
+	common.LanguageJavaScript: jsRule,
+	common.LanguageTypeScript: jsRule,
+	// Members are indented once inside their class
+	common.LanguageJava: {
+		declaration: regexp.MustCompile(`^(?:\t| {0,4})(?:public|protected|private|static|final|abstract|synchronized|class|interface|enum|record)\b(?:.*\b(?:class|interface|enum|record)\s+(\w+)|[^=(]*?(\w+)\s*\()?`),
+		attached:    regexp.MustCompile(`^\s*(//|/\*|\*|@)`),
+	},
+}
+
+// declarationsOf returns the declarations of text, false when language has no rules or
+// the text cannot be read in it.
+func declarationsOf(language, text string) ([]declaration, bool) {
+	if language == common.LanguageGo {
+		return goDeclarations(text)
+	}
+	if rule, ok := boundaryRules[language]; ok {
+		return rule.declarations(text), true
+	}
+	return nil, false
+}
+
+// NewCodeSplitter returns the splitter of the files in language, which keeps declarations
+// whole when they fit in a chunk. Languages it does not know are split by characters.
+func NewCodeSplitter(language string, size common.ChunkSize) textsplitter.TextSplitter {
+	characters := textsplitter.NewRecursiveCharacter(
+		textsplitter.WithChunkSize(size.Size),
+		textsplitter.WithChunkOverlap(size.Overlap),
+	)
+	if _, ok := boundaryRules[language]; !ok && language != common.LanguageGo {
+		return characters
+	}
+	return &CodeSplitter{language: language, size: size.Size, characters: characters}
+}
+
+// CodeSplitter cuts a text at the start of its declarations, merging the consecutive
+// ones that fit in a chunk. Declarations larger than a chunk are cut by characters.
+type CodeSplitter struct {
+	language   string
+	size       int
+	characters textsplitter.TextSplitter
+}
+
+func (s *CodeSplitter) SplitText(text string) ([]string, error) {
+	declarations, ok := declarationsOf(s.language, text)
+	if !ok {
+		return s.characters.SplitText(text)
+	}
+	starts := []int{0}
+	for _, declaration := range declarations {
+		if declaration.start > starts[len(starts)-1] {
+			starts = append(starts, declaration.start)
+		}
+	}
+	var chunks []string
+	// The chunk being built is text[chunkStart:chunkEnd]
+	chunkStart, chunkEnd := 0, 0
+	flush := func() {
+		if chunk := strings.TrimSpace(text[chunkStart:chunkEnd]); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		chunkStart = chunkEnd
+	}
+	for i, start := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		unit := text[start:end]
+		switch {
+		case utf8.RuneCountInString(strings.TrimSpace(unit)) > s.size:
+			flush()
+			parts, err := s.characters.SplitText(unit)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, parts...)
+			chunkStart = end
+		case utf8.RuneCountInString(strings.TrimSpace(text[chunkStart:end])) > s.size:
+			flush()
+		}
+		chunkEnd = end
+	}
+	flush()
+	return chunks, nil
+}
+
+// goDeclarations returns the top level declarations including their doc comments, false
+// when the file does not parse. Methods are named after their receiver, e.g. Store.Get.
+func goDeclarations(text string) ([]declaration, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", text, parser.ParseComments)
+	if err != nil {
+		return nil, false
+	}
+	var declarations []declaration
+	for _, decl := range file.Decls {
+		pos := decl.Pos()
+		var name string
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				pos = decl.Doc.Pos()
+			}
+			name = decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				name = receiverName(decl.Recv.List[0].Type) + "." + name
+			}
+		case *ast.GenDecl:
+			if decl.Doc != nil {
+				pos = decl.Doc.Pos()
+			}
+			if len(decl.Specs) > 0 {
+				switch spec := decl.Specs[0].(type) {
+				case *ast.TypeSpec:
+					name = spec.Name.Name
+				case *ast.ValueSpec:
+					name = spec.Names[0].Name
+				}
+			}
+		}
+		declarations = append(declarations, declaration{
+			start: lineStart(text, fset.Position(pos).Offset),
+			end:   fset.Position(decl.End()).Offset,
+			name:  name,
+		})
+	}
+	return declarations, true
+}
+
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// declarations starts a declaration at every line matching the rule, or at the comments
+// and annotations right above it. A declaration ends where the next one starts.
+func (rule boundaryRule) declarations(text string) []declaration {
+	lines := strings.SplitAfter(text, "\n")
+	var declarations []declaration
+	offset := 0
+	offsets := make([]int, len(lines))
+	for i, line := range lines {
+		offsets[i] = offset
+		offset += len(line)
+	}
+	for i, line := range lines {
+		match := rule.declaration.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		first := i
+		for first > 0 && rule.attached.MatchString(lines[first-1]) {
+			first--
+		}
+		if len(declarations) > 0 {
+			declarations[len(declarations)-1].end = offsets[first]
+		}
+		var name string
+		for _, group := range match[1:] {
+			if group != "" {
+				name = group
+				break
+			}
+		}
+		declarations = append(declarations, declaration{start: offsets[first], end: len(text), name: name})
+	}
+	return declarations
+}
+
+func lineStart(text string, offset int) int {
+	return strings.LastIndex(text[:offset], "\n") + 1
+}
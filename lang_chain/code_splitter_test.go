@@ -0,0 +1,131 @@
+package lang_chain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+func TestLanguageOf(t *testing.T) {
+	assert.Equal(t, common.LanguageGo, LanguageOf("/src/main.go"))
+	assert.Equal(t, common.LanguageTypeScript, LanguageOf("web/App.TSX"))
+	assert.Equal(t, common.LanguageJava, LanguageOf("Main.java"))
+	assert.Equal(t, common.LanguageDefault, LanguageOf("README.md"))
+	assert.Equal(t, common.LanguageDefault, LanguageOf("Makefile"))
+}
+
+const goSource = `package main
+
+import "fmt"
+
+// Greeter says hello.
+type Greeter struct {
+	Name string
+}
+
+// Greet prints the greeting,
+// in two lines of doc.
+func (g Greeter) Greet() {
+	fmt.Println("hello", g.Name)
+}
+
+func main() {
+	Greeter{Name: "utn"}.Greet()
+}
+`
+
+func TestCodeSplitter(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		size     int
+		text     string
+		expected []string
+	}{
+		{
+			name:     "Go by declaration",
+			language: common.LanguageGo,
+			size:     120,
+			text:     goSource,
+			expected: []string{
+				"package main\n\nimport \"fmt\"\n\n// Greeter says hello.\ntype Greeter struct {\n\tName string\n}",
+				"// Greet prints the greeting,\n// in two lines of doc.\nfunc (g Greeter) Greet() {\n\tfmt.Println(\"hello\", g.Name)\n}",
+				"func main() {\n\tGreeter{Name: \"utn\"}.Greet()\n}",
+			},
+		},
+		{
+			name:     "Go declarations fitting in a chunk are merged",
+			language: common.LanguageGo,
+			size:     1000,
+			text:     goSource,
+			expected: []string{strings.TrimSpace(goSource)},
+		},
+		{
+			name:     "Python",
+			language: common.LanguagePython,
+			size:     70,
+			text:     "import os\n\n# Reads the config.\n@cache\ndef config():\n    return os.environ\n\nclass Tool:\n    def run(self):\n        pass\n",
+			expected: []string{
+				"import os",
+				"# Reads the config.\n@cache\ndef config():\n    return os.environ",
+				"class Tool:\n    def run(self):\n        pass",
+			},
+		},
+		{
+			name:     "TypeScript",
+			language: common.LanguageTypeScript,
+			size:     70,
+			text:     "import x from 'x'\n\n/**\n * Adds.\n */\nexport function add(a: number) {\n  return a + 1\n}\nexport const b = 2\n",
+			expected: []string{
+				"import x from 'x'",
+				"/**\n * Adds.\n */\nexport function add(a: number) {\n  return a + 1\n}",
+				"export const b = 2",
+			},
+		},
+		{
+			name:     "Java",
+			language: common.LanguageJava,
+			size:     75,
+			text:     "package utn;\n\npublic class Tool {\n    // Runs it.\n    @Override\n    public void run() {\n        go();\n    }\n}\n",
+			expected: []string{
+				"package utn;\n\npublic class Tool {",
+				"// Runs it.\n    @Override\n    public void run() {\n        go();\n    }\n}",
+			},
+		},
+		{
+			name:     "Go that does not parse is split by characters",
+			language: common.LanguageGo,
+			size:     20,
+			text:     "package main\n\nfunc broken( {\n}\n",
+			expected: []string{"package main", "func broken( {\n}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := NewCodeSplitter(tt.language, common.ChunkSize{Size: tt.size}).SplitText(tt.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, chunks)
+		})
+	}
+}
+
+func TestCodeSplitter_LargeDeclaration(t *testing.T) {
+	body := strings.Repeat("\tfmt.Println(\"hello\")\n", 10)
+	text := "package main\n\nfunc small() {}\n\nfunc large() {\n" + body + "}\n\nfunc last() {}\n"
+	chunks, err := NewCodeSplitter(common.LanguageGo, common.ChunkSize{Size: 100, Overlap: 22}).SplitText(text)
+	assert.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc small() {}", chunks[0])
+	assert.Equal(t, "func last() {}", chunks[len(chunks)-1])
+	for _, chunk := range chunks[1 : len(chunks)-1] {
+		assert.LessOrEqual(t, len(chunk), 100)
+		assert.NotContains(t, chunk, "small")
+	}
+	// Cut pieces share the overlap
+	lines := strings.Split(chunks[1], "\n")
+	assert.Contains(t, chunks[2], strings.TrimSpace(lines[len(lines)-1]))
+
+	assert.IsType(t, textsplitter.RecursiveCharacter{}, NewCodeSplitter(common.LanguageDefault, common.ChunkSize{Size: 300, Overlap: 30}))
+}
@@ -0,0 +1,156 @@
+package lang_chain
+
+import (
+	"encoding/json"
+	"github.com/ffumaneri/github-cli/common"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DefaultOllamaUrl = "http://127.0.0.1:11434"
+
+var healthClient = &http.Client{Timeout: 5 * time.Second}
+
+// OllamaUrl returns the Ollama server address, honoring $OLLAMA_HOST like the ollama client does.
+func OllamaUrl() string {
+	if host := os.Getenv("OLLAMA_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		return strings.TrimRight(host, "/")
+	}
+	return DefaultOllamaUrl
+}
+
+// PingOllama checks the Ollama server at baseUrl answers and has model available.
+func PingOllama(baseUrl, model string) error {
+	resp, err := healthClient.Get(strings.TrimRight(baseUrl, "/") + "/api/tags")
+	if err != nil {
+		return common.Errorf(common.KindNetwork, "cannot reach Ollama at %s: %w", baseUrl, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return common.Errorf(common.KindAiBackend, "unexpected response from Ollama at %s: %s", baseUrl, resp.Status)
+	}
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return common.Errorf(common.KindAiBackend, "unexpected response from Ollama at %s: %w", baseUrl, err)
+	}
+	for _, m := range tags.Models {
+		if m.Name == model || m.Name == model+":latest" {
+			return nil
+		}
+	}
+	return common.Errorf(common.KindAiBackend, "model %s is not available in Ollama at %s", model, baseUrl)
+}
+
+// PingOpenAI checks the OpenAI compatible server at baseUrl answers and lists model.
+// Servers that do not list models, like some llama.cpp builds, only need to answer.
+func PingOpenAI(baseUrl, apiKey, model string) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(baseUrl, "/")+"/models", nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := healthClient.Do(req)
+	if err != nil {
+		return common.Errorf(common.KindNetwork, "cannot reach %s: %w", baseUrl, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return common.Errorf(common.KindAiBackend, "unexpected response from %s: %s", baseUrl, resp.Status)
+	}
+	var models struct {
+		Data []struct {
+			Id string `json:"id"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&models); err != nil || len(models.Data) == 0 {
+		return nil
+	}
+	for _, m := range models.Data {
+		if m.Id == model {
+			return nil
+		}
+	}
+	return common.Errorf(common.KindAiBackend, "model %s is not served at %s", model, baseUrl)
+}
+
+// DefaultAnthropicUrl is where the Anthropic client sends its requests when anthropic_url is not set.
+const DefaultAnthropicUrl = "https://api.anthropic.com/v1"
+
+// PingAnthropic checks the Anthropic API at baseUrl accepts apiKey and serves model, which
+// may be an alias.
+func PingAnthropic(baseUrl, apiKey, model string) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(baseUrl, "/")+"/models/"+url.PathEscape(model), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-api-key", apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	resp, err := healthClient.Do(req)
+	if err != nil {
+		return common.Errorf(common.KindNetwork, "cannot reach %s: %w", baseUrl, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return common.Errorf(common.KindAuth, "%s rejected the API key: %s", baseUrl, resp.Status)
+	case http.StatusNotFound:
+		return common.Errorf(common.KindAiBackend, "model %s is not served at %s", model, baseUrl)
+	}
+	return common.Errorf(common.KindAiBackend, "unexpected response from %s: %s", baseUrl, resp.Status)
+}
+
+// PingQdrant checks the Qdrant server at qdrantUrl answers.
+func PingQdrant(qdrantUrl string) error {
+	resp, err := healthClient.Get(strings.TrimRight(qdrantUrl, "/") + "/collections")
+	if err != nil {
+		return common.Errorf(common.KindNetwork, "cannot reach Qdrant at %s: %w", qdrantUrl, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return common.Errorf(common.KindAiBackend, "unexpected response from Qdrant at %s: %s", qdrantUrl, resp.Status)
+	}
+	return nil
+}
+
+// QdrantCollections returns the names of the collections in the Qdrant server at qdrantUrl.
+func QdrantCollections(qdrantUrl string) ([]string, error) {
+	resp, err := healthClient.Get(strings.TrimRight(qdrantUrl, "/") + "/collections")
+	if err != nil {
+		return nil, common.Errorf(common.KindNetwork, "cannot reach Qdrant at %s: %w", qdrantUrl, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.Errorf(common.KindAiBackend, "unexpected response from Qdrant at %s: %s", qdrantUrl, resp.Status)
+	}
+	var collections struct {
+		Result struct {
+			Collections []struct {
+				Name string `json:"name"`
+			} `json:"collections"`
+		} `json:"result"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&collections); err != nil {
+		return nil, common.Errorf(common.KindAiBackend, "unexpected response from Qdrant at %s: %w", qdrantUrl, err)
+	}
+	names := make([]string, 0, len(collections.Result.Collections))
+	for _, c := range collections.Result.Collections {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
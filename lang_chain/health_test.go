@@ -0,0 +1,89 @@
+package lang_chain
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPingOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/tags", r.URL.Path)
+		_, _ = fmt.Fprint(w, `{"models":[{"name":"llama3.2:latest"},{"name":"nomic-embed-text:v1.5"}]}`)
+	}))
+	defer server.Close()
+
+	assert.NoError(t, PingOllama(server.URL, "llama3.2"))
+	assert.NoError(t, PingOllama(server.URL, "nomic-embed-text:v1.5"))
+	assert.ErrorContains(t, PingOllama(server.URL, "mistral"), "not available")
+	assert.ErrorContains(t, PingOllama("http://127.0.0.1:1", "llama3.2"), "cannot reach")
+}
+
+func TestPingOpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		_, _ = fmt.Fprint(w, `{"data":[{"id":"qwen2.5-coder"}]}`)
+	}))
+	defer server.Close()
+
+	assert.NoError(t, PingOpenAI(server.URL+"/v1", "key", "qwen2.5-coder"))
+	assert.ErrorContains(t, PingOpenAI(server.URL+"/v1/", "key", "mistral"), "not served")
+	assert.ErrorContains(t, PingOpenAI("http://127.0.0.1:1", "", "mistral"), "cannot reach")
+}
+
+func TestPingAnthropic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("x-api-key") != "key":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v1/models/claude-sonnet-4-5":
+			assert.Equal(t, "2023-06-01", r.Header.Get("anthropic-version"))
+			_, _ = fmt.Fprint(w, `{"id":"claude-sonnet-4-5-20250929","type":"model"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, PingAnthropic(server.URL+"/v1", "key", "claude-sonnet-4-5"))
+	assert.ErrorContains(t, PingAnthropic(server.URL+"/v1/", "key", "mistral"), "not served")
+	assert.ErrorContains(t, PingAnthropic(server.URL+"/v1", "wrong", "claude-sonnet-4-5"), "rejected the API key")
+	assert.ErrorContains(t, PingAnthropic("http://127.0.0.1:1", "key", "claude-sonnet-4-5"), "cannot reach")
+}
+
+func TestPingQdrant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/collections" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, PingQdrant(server.URL))
+	assert.Error(t, PingQdrant(server.URL+"/wrong"))
+}
+
+func TestQdrantCollections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/collections", r.URL.Path)
+		_, _ = fmt.Fprint(w, `{"result":{"collections":[{"name":"tp2"},{"name":"tp1"}]},"status":"ok"}`)
+	}))
+	defer server.Close()
+
+	names, err := QdrantCollections(server.URL + "/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tp1", "tp2"}, names)
+	_, err = QdrantCollections("http://127.0.0.1:1")
+	assert.ErrorContains(t, err, "cannot reach")
+}
+
+func TestOllamaUrl(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "")
+	assert.Equal(t, DefaultOllamaUrl, OllamaUrl())
+	t.Setenv("OLLAMA_HOST", "gpu-box:11434")
+	assert.Equal(t, "http://gpu-box:11434", OllamaUrl())
+}
@@ -11,27 +11,64 @@ import (
 	"github.com/tmc/langchaingo/schema"
 	"github.com/tmc/langchaingo/textsplitter"
 	"github.com/tmc/langchaingo/vectorstores"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 )
 
-func NewLangChainWrapper(llm llms.Model, fs common.IFS, embedder func(llm llms.Model) embeddings.Embedder, store func(embedder embeddings.Embedder, collectionName string) vectorstores.VectorStore, splitter func() textsplitter.TextSplitter, documentLoader func(filePath string, size int64) (documentloaders.Loader, *os.File), retrievalQA func(model llms.Model, retriever vectorstores.Retriever) chains.Chain) *LangChainWrapper {
-	return &LangChainWrapper{llm, fs, embedder, store, splitter, documentLoader, retrievalQA}
+func NewLangChainWrapper(llm llms.Model, fs common.IFS, embedder func(llm llms.Model) (embeddings.Embedder, error), store func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error), splitter func(path string) textsplitter.TextSplitter, documentLoader func(filePath string, size int64) (documentloaders.Loader, *os.File), retrievalQA func(model llms.Model, retriever vectorstores.Retriever) chains.Chain, contexts func() ([]string, error), manifestPath func(collectionName string) (string, error), commit func(dir string) string) *LangChainWrapper {
+	return &LangChainWrapper{llm, fs, embedder, store, splitter, documentLoader, retrievalQA, contexts, manifestPath, commit}
+}
+
+// LoadOptions select what LoadSourceCode loads.
+type LoadOptions struct {
+	// Full drops everything in the collection and loads every file again
+	Full bool
+	// Include and Exclude are globs, see common.WalkFilter
+	Include []string
+	Exclude []string
+	// MaxFileSize skips the files larger than it, in bytes, when not zero
+	MaxFileSize int64
+}
+
+// LoadStats counts the files of a load by what happened to them.
+type LoadStats struct {
+	Added     int
+	Changed   int
+	Removed   int
+	Unchanged int
+	Failed    int
+	// Skipped counts the files not loaded by reason, a skipped directory counting once
+	Skipped map[string]int
 }
 
 type ILangChainWrapper interface {
 	AskLlm(prompt string, streamingFunc func(ctx context.Context, chunk []byte) error) (err error)
-	LoadSourceCode(name, directory string) (err error)
-	AskWithContext(contextName, searchQuery string) (err error)
+	// LoadSourceCode embeds the text files of directory that are new or changed since the
+	// last load of the collection name, and removes the chunks of the changed and removed
+	// ones. The files ignored by .gitignore and .github-cli-ignore are left out. When it
+	// fails after looking at files, stats counts the ones it got through.
+	LoadSourceCode(name, directory string, options LoadOptions) (stats LoadStats, err error)
+	// AskWithContext answers with the chunks of the context most similar to searchQuery,
+	// among the ones having the metadata values of filters.
+	AskWithContext(contextName, searchQuery string, filters map[string]string) (err error)
+	ContextNames() ([]string, error)
 }
 type LangChainWrapper struct {
 	llm            llms.Model
 	fs             common.IFS
-	embedder       func(llm llms.Model) embeddings.Embedder
-	store          func(embedder embeddings.Embedder, collectionName string) vectorstores.VectorStore
-	splitter       func() textsplitter.TextSplitter
+	embedder       func(llm llms.Model) (embeddings.Embedder, error)
+	store          func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error)
+	splitter       func(path string) textsplitter.TextSplitter
 	documentLoader func(filePath string, size int64) (documentloaders.Loader, *os.File)
 	retrievalQA    func(llm llms.Model, retriever vectorstores.Retriever) chains.Chain
+	contexts       func() ([]string, error)
+	manifestPath   func(collectionName string) (string, error)
+	// commit returns the HEAD of the git repository of dir, empty when there is none
+	commit func(dir string) string
 }
 
 func (o *LangChainWrapper) AskLlm(prompt string, streamingFunc func(ctx context.Context, chunk []byte) error) (err error) {
@@ -42,34 +79,204 @@ func (o *LangChainWrapper) AskLlm(prompt string, streamingFunc func(ctx context.
 		llms.WithStreamingFunc(streamingFunc),
 	)
 	_ = completion
-	return
+	return backendError(err)
 }
 
-func (o *LangChainWrapper) LoadSourceCode(name, directory string) (err error) {
-	err = o.fs.WalkDir(directory, func(path string, size int64) {
-		docs, err := o.processDocument(path, size)
-		if err != nil {
-			log.Fatal(err)
+func (o *LangChainWrapper) LoadSourceCode(name, directory string, options LoadOptions) (stats LoadStats, err error) {
+	root, err := filepath.Abs(directory)
+	if err != nil {
+		return
+	}
+	store, err := o.contextStore(name)
+	if err != nil {
+		return
+	}
+	remover, ok := store.(DocumentRemover)
+	if !ok {
+		return stats, fmt.Errorf("the vector store of %s cannot remove documents", name)
+	}
+	manifestPath, err := o.manifestPath(name)
+	if err != nil {
+		return
+	}
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		return stats, common.WithKind(common.KindValidation, err)
+	}
+	// Without a manifest the chunks in the collection are unknown, so it starts over
+	if options.Full || manifest == nil {
+		if err = remover.RemoveAllDocuments(context.Background()); err != nil {
+			return stats, backendError(err)
 		}
-		e := o.embedder(o.llm)
-		store := o.store(e, name)
-		_, err = store.AddDocuments(context.Background(), docs)
-		if err != nil {
-			log.Fatal(err)
+		manifest = &Manifest{Files: map[string]ManifestFile{}}
+		if err = manifest.Save(manifestPath); err != nil {
+			return
 		}
-	})
-	return
+	}
+	load := &sourceLoad{wrapper: o, store: store, remover: remover, root: root, maxFileSize: options.MaxFileSize,
+		manifest: manifest, manifestPath: manifestPath, seen: map[string]bool{}}
+	if o.commit != nil {
+		load.commit = o.commit(root)
+	}
+	err = o.fs.WalkDir(root, &common.WalkFilter{
+		IgnoreFiles: []string{".gitignore", common.IgnoreFileName},
+		Include:     options.Include,
+		Exclude:     options.Exclude,
+		Skipped:     load.skip,
+	}, load.file)
+	// Only a complete walk tells which files are gone, the chunks added so far are already
+	// in the manifest
+	if err == nil {
+		load.removeUnseen(root)
+	}
+	if saveErr := manifest.Save(manifestPath); err == nil {
+		err = saveErr
+	}
+	if err == nil {
+		err = backendError(load.err)
+	}
+	return load.stats, err
+}
+
+// sourceLoad is the state of a load shared by the files visited concurrently.
+type sourceLoad struct {
+	wrapper     *LangChainWrapper
+	store       vectorstores.VectorStore
+	remover     DocumentRemover
+	root        string
+	commit      string
+	maxFileSize int64
+	mu          sync.Mutex
+	manifest    *Manifest
+	// manifestPath is saved after each file, so an interrupted load knows the chunks it added
+	manifestPath string
+	seen         map[string]bool
+	stats        LoadStats
+	// err is the first error, reported once the walk ends
+	err error
+}
+
+func (load *sourceLoad) file(path string, size int64) {
+	if load.maxFileSize > 0 && size > load.maxFileSize {
+		load.skip(path, common.SkipTooLarge)
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		load.fail(path, err)
+		return
+	}
+	if common.IsBinary(content) {
+		load.skip(path, common.SkipBinary)
+		return
+	}
+	hash := contentHash(content)
+	load.mu.Lock()
+	load.seen[path] = true
+	previous, loaded := load.manifest.Files[path]
+	if loaded && previous.Hash == hash {
+		load.stats.Unchanged++
+		load.mu.Unlock()
+		return
+	}
+	load.mu.Unlock()
+
+	docs, err := load.wrapper.processDocument(path, size)
+	var ids []string
+	if err == nil && len(docs) > 0 {
+		rel, _ := filepath.Rel(load.root, path)
+		annotateChunks(docs, string(content), filepath.ToSlash(rel), load.commit)
+		ids, err = load.store.AddDocuments(context.Background(), docs)
+	}
+	if err != nil {
+		load.fail(path, err)
+		return
+	}
+	// The new chunks are in, the old ones are dropped; when that fails they are kept in the
+	// manifest to be removed by the next load
+	entry := ManifestFile{Hash: hash, Chunks: ids}
+	if err = load.remover.RemoveDocuments(context.Background(), previous.Chunks); err != nil {
+		entry = ManifestFile{Chunks: append(ids, previous.Chunks...)}
+		load.fail(path, err)
+	}
+	load.mu.Lock()
+	defer load.mu.Unlock()
+	load.manifest.Files[path] = entry
+	// The manifest is saved again once the walk ends, which reports the error
+	if saveErr := load.manifest.Save(load.manifestPath); saveErr != nil {
+		slog.Warn("cannot save manifest", common.LogFile, load.manifestPath, "error", saveErr)
+	}
+	if err != nil {
+		return
+	}
+	if loaded {
+		load.stats.Changed++
+	} else {
+		load.stats.Added++
+	}
+}
+
+// removeUnseen removes the chunks of the files under root that were not found.
+func (load *sourceLoad) removeUnseen(root string) {
+	for path, entry := range load.manifest.Files {
+		if load.seen[path] || !underDir(path, root) {
+			continue
+		}
+		if err := load.remover.RemoveDocuments(context.Background(), entry.Chunks); err != nil {
+			load.fail(path, err)
+			continue
+		}
+		delete(load.manifest.Files, path)
+		load.stats.Removed++
+	}
+}
+
+// skip counts a file or directory not loaded by reason. It is not marked as seen, so
+// removeUnseen drops the chunks of the files that were loaded before.
+func (load *sourceLoad) skip(path, reason string) {
+	slog.Debug("document skipped", common.LogFile, path, "reason", reason)
+	load.mu.Lock()
+	defer load.mu.Unlock()
+	if load.stats.Skipped == nil {
+		load.stats.Skipped = map[string]int{}
+	}
+	load.stats.Skipped[reason]++
 }
-func (o *LangChainWrapper) AskWithContext(contextName, searchQuery string) (err error) {
+
+func (load *sourceLoad) fail(path string, err error) {
+	slog.Warn("cannot load document", common.LogFile, path, "error", err)
+	load.mu.Lock()
+	defer load.mu.Unlock()
+	load.stats.Failed++
+	if load.err == nil {
+		load.err = fmt.Errorf("error loading %s: %w", path, err)
+	}
+}
+
+func underDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (o *LangChainWrapper) AskWithContext(contextName, searchQuery string, filters map[string]string) (err error) {
 	optionsVector := []vectorstores.Option{
 		vectorstores.WithScoreThreshold(0.80), // use for precision, when you want to get only the most relevant documents
 		//vectorstores.WithNameSpace(""),            // use for set a namespace in the storage
-		//vectorstores.WithFilters(map[string]interface{}{"language": "en"}), // use for filter the documents
 		//vectorstores.WithEmbedder(embedder), // use when you want add documents or doing similarity search
 		//vectorstores.WithDeduplicater(vectorstores.NewSimpleDeduplicater()), //  This is useful to prevent wasting time on creating an embedding
 	}
+	metadataFilters, err := chunkFilters(filters)
+	if err != nil {
+		return
+	}
+	if metadataFilters != nil {
+		optionsVector = append(optionsVector, vectorstores.WithFilters(metadataFilters))
+	}
 
-	store := o.store(o.embedder(o.llm), contextName)
+	store, err := o.contextStore(contextName)
+	if err != nil {
+		return
+	}
 	retriever := vectorstores.ToRetriever(store, 10, optionsVector...)
 	//search
 	//resDocs, err := retriever.GetRelevantDocuments(context.Background(), searchQuery)
@@ -87,33 +294,70 @@ func (o *LangChainWrapper) AskWithContext(contextName, searchQuery string) (err
 	values["query"] = searchQuery
 	call, err := retrievalQA.Call(context.Background(), values)
 	if err != nil {
-		return
+		return backendError(err)
 	}
+	sources, _ := call[sourceDocumentsKey].([]schema.Document)
+	delete(call, sourceDocumentsKey)
 	for key, values := range call {
 		fmt.Printf("Key: %s, Values: %v\n", key, values)
 	}
+	if len(sources) > 0 {
+		fmt.Println("Sources:")
+		for _, doc := range sources {
+			fmt.Println("  " + ChunkSource(doc))
+		}
+	}
 	return
 }
 
+// sourceDocumentsKey holds the chunks an answer is based on, when the chain returns them.
+const sourceDocumentsKey = "source_documents"
+
+// ContextNames returns the names of the contexts loaded in the vector store.
+func (o *LangChainWrapper) ContextNames() ([]string, error) {
+	return o.contexts()
+}
+
+// backendError classifies the errors of the model and the vector store, failing to reach
+// them is a network error.
+func backendError(err error) error {
+	if common.IsNetworkError(err) {
+		return common.WithKind(common.KindNetwork, err)
+	}
+	return common.WithKind(common.KindAiBackend, err)
+}
+
+// contextStore returns the vector store holding the documents of the context name.
+func (o *LangChainWrapper) contextStore(name string) (vectorstores.VectorStore, error) {
+	embedder, err := o.embedder(o.llm)
+	if err != nil {
+		return nil, err
+	}
+	return o.store(embedder, name)
+}
+
 func (o *LangChainWrapper) processDocument(path string, size int64) ([]schema.Document, error) {
-	split := o.splitter()
+	start := time.Now()
+	split := o.splitter(path)
 	p, f := o.documentLoader(path, size)
+	if p == nil {
+		return nil, fmt.Errorf("cannot read %s", path)
+	}
 	defer func(f *os.File) {
 		if f == nil {
 			return
 		}
 		err := f.Close()
 		if err != nil {
-			fmt.Println("Error closing file: ", err)
+			slog.Warn("cannot close file", common.LogFile, path, "error", err)
 			return
 		}
 	}(f)
 
 	docs, err := p.LoadAndSplit(context.Background(), split)
 	if err != nil {
-		fmt.Println("Error loading document: ", err)
 		return nil, err
 	}
-	log.Println("Documents loaded: ", len(docs))
+	slog.Debug("document loaded", common.LogFile, path, "chunks", len(docs), common.LogDuration, time.Since(start))
 	return docs, nil
 }
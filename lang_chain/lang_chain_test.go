@@ -3,6 +3,7 @@ package lang_chain
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/ffumaneri/github-cli/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -13,7 +14,9 @@ import (
 	"github.com/tmc/langchaingo/schema"
 	"github.com/tmc/langchaingo/textsplitter"
 	"github.com/tmc/langchaingo/vectorstores"
+	"maps"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -70,6 +73,7 @@ func (m *MockLoader) LoadAndSplit(ctx context.Context, splitter textsplitter.Tex
 type MockVectorStore struct {
 	MockAddDocuments     func(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error)
 	MockSimilaritySearch func(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error)
+	MockRemoveDocuments  func(ctx context.Context, ids []string) error
 }
 type MockEmbedder struct {
 	EmbedDocumentsFunc func(ctx context.Context, texts []string) ([][]float32, error)
@@ -108,11 +112,23 @@ func (m *MockVectorStore) SimilaritySearch(ctx context.Context, query string, nu
 	return nil, errors.New("MockSimilaritySearch not implemented")
 }
 
+// RemoveDocuments calls the mock implementation, removing nothing by default.
+func (m *MockVectorStore) RemoveDocuments(ctx context.Context, ids []string) error {
+	if m.MockRemoveDocuments != nil {
+		return m.MockRemoveDocuments(ctx, ids)
+	}
+	return nil
+}
+
+func (m *MockVectorStore) RemoveAllDocuments(ctx context.Context) error {
+	return nil
+}
+
 type mockFS struct {
 	walkDirFn func(path string, callback common.WalkDirCallback) error
 }
 
-func (m *mockFS) WalkDir(path string, callback common.WalkDirCallback) error {
+func (m *mockFS) WalkDir(path string, _ *common.WalkFilter, callback common.WalkDirCallback) error {
 	return m.walkDirFn(path, callback)
 }
 
@@ -175,7 +191,7 @@ func TestLangChainWrapper_AskLlm(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			wrapper := NewLangChainWrapper(tt.mockLLM, nil, nil, nil, nil, nil, nil)
+			wrapper := NewLangChainWrapper(tt.mockLLM, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 			err := wrapper.AskLlm(tt.prompt, func(ctx context.Context, chunk []byte) error {
 				return nil
 			})
@@ -183,26 +199,32 @@ func TestLangChainWrapper_AskLlm(t *testing.T) {
 				assert.NoError(t, err)
 			} else {
 				assert.EqualError(t, err, tt.expected.Error())
+				assert.Equal(t, common.KindAiBackend, common.KindOf(err))
 			}
 		})
 	}
 }
 
 func TestLangChainWrapper_LoadSourceCode(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.go")
+	assert.NoError(t, os.WriteFile(file, []byte("package main"), 0600))
+	manifests := t.TempDir()
 	tests := []struct {
 		name     string
 		mockFS   *mockFS
 		expected error
+		stats    LoadStats
 	}{
 		{
 			name: "success",
 			mockFS: &mockFS{
 				walkDirFn: func(path string, callback common.WalkDirCallback) error {
-					callback("path", 1024)
+					callback(file, 1024)
 					return nil
 				},
 			},
 			expected: nil,
+			stats:    LoadStats{Added: 1},
 		},
 		{
 			name: "error",
@@ -213,6 +235,17 @@ func TestLangChainWrapper_LoadSourceCode(t *testing.T) {
 			},
 			expected: errors.New("walk error"),
 		},
+		{
+			name: "partial",
+			mockFS: &mockFS{
+				walkDirFn: func(path string, callback common.WalkDirCallback) error {
+					callback(file, 1024)
+					return errors.New("walk error")
+				},
+			},
+			expected: errors.New("walk error"),
+			stats:    LoadStats{Added: 1},
+		},
 	}
 
 	for _, tt := range tests {
@@ -221,40 +254,116 @@ func TestLangChainWrapper_LoadSourceCode(t *testing.T) {
 				CallFunc: func(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
 					return "result", nil
 				}}
-			wrapper := NewLangChainWrapper(llm, tt.mockFS, func(llm llms.Model) embeddings.Embedder {
+			wrapper := NewLangChainWrapper(llm, tt.mockFS, func(llm llms.Model) (embeddings.Embedder, error) {
 				return &MockEmbedder{func(ctx context.Context, texts []string) ([][]float32, error) {
 					return [][]float32{}, nil
 				}, func(ctx context.Context, text string) ([]float32, error) {
 					return []float32{}, nil
-				}}
-			}, func(embedder embeddings.Embedder, collectionName string) vectorstores.VectorStore {
+				}}, nil
+			}, func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
 				return &MockVectorStore{MockAddDocuments: func(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error) {
 					return []string{}, nil
 				}, MockSimilaritySearch: func(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error) {
 					return []schema.Document{}, nil
-				}}
-			}, func() textsplitter.TextSplitter {
+				}}, nil
+			}, func(path string) textsplitter.TextSplitter {
 				return &MockTextSplitter{}
 			}, func(filePath string, size int64) (documentloaders.Loader, *os.File) {
 				l := &MockLoader{}
 				l.On("LoadAndSplit", mock.Anything, mock.Anything).Return([]schema.Document{}, nil)
 				return l, nil
+			}, nil, nil, func(collectionName string) (string, error) {
+				return ManifestPath(manifests, collectionName), nil
 			}, nil)
-			err := wrapper.LoadSourceCode("name", "directory")
+			stats, err := wrapper.LoadSourceCode("name", "directory", LoadOptions{Full: true})
 			if tt.expected == nil {
 				assert.NoError(t, err)
 			} else {
 				assert.EqualError(t, err, tt.expected.Error())
 			}
+			assert.Equal(t, tt.stats, stats)
 		})
 	}
 }
 
+func TestLangChainWrapper_LoadSourceCode_Interrupted(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		file := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(file, []byte("package "+name[:1]), 0600))
+		files = append(files, file)
+	}
+	manifestPath := ManifestPath(t.TempDir(), "name")
+	// chunks are the paths of the chunks in the store by id
+	chunks := map[string]string{}
+	var interrupted []byte
+	var stored map[string]string
+	store := &MockVectorStore{
+		MockAddDocuments: func(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error) {
+			path := docs[0].Metadata[MetadataPath].(string)
+			if path == "b.go" && interrupted == nil {
+				// The store and the manifest are what an interrupted load would have left
+				interrupted, _ = os.ReadFile(manifestPath)
+				stored = maps.Clone(chunks)
+				return nil, errors.New("embedder unavailable")
+			}
+			var ids []string
+			for range docs {
+				id := fmt.Sprintf("id-%d", len(chunks))
+				chunks[id] = path
+				ids = append(ids, id)
+			}
+			return ids, nil
+		},
+		MockRemoveDocuments: func(ctx context.Context, ids []string) error {
+			for _, id := range ids {
+				delete(chunks, id)
+			}
+			return nil
+		},
+	}
+	wrapper := NewLangChainWrapper(&mockLLM{}, &mockFS{
+		walkDirFn: func(path string, callback common.WalkDirCallback) error {
+			for _, file := range files {
+				callback(file, 1024)
+			}
+			return nil
+		},
+	}, func(llm llms.Model) (embeddings.Embedder, error) {
+		return &MockEmbedder{}, nil
+	}, func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
+		return store, nil
+	}, func(path string) textsplitter.TextSplitter {
+		return &MockTextSplitter{}
+	}, func(filePath string, size int64) (documentloaders.Loader, *os.File) {
+		l := &MockLoader{}
+		l.On("LoadAndSplit", mock.Anything, mock.Anything).Return([]schema.Document{{PageContent: filePath}}, nil)
+		return l, nil
+	}, nil, nil, func(collectionName string) (string, error) {
+		return manifestPath, nil
+	}, nil)
+
+	_, err := wrapper.LoadSourceCode("name", dir, LoadOptions{Full: true})
+	assert.Error(t, err)
+	assert.NoError(t, os.WriteFile(manifestPath, interrupted, 0600))
+	chunks = stored
+
+	stats, err := wrapper.LoadSourceCode("name", dir, LoadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, LoadStats{Added: 2, Unchanged: 1}, stats)
+	var paths []string
+	for _, path := range chunks {
+		paths = append(paths, path)
+	}
+	assert.ElementsMatch(t, []string{"a.go", "b.go", "c.go"}, paths)
+}
+
 func TestLangChainWrapper_AskWithContext(t *testing.T) {
 	tests := []struct {
 		name      string
 		mockLLM   *mockLLM
-		mockStore func(embedder embeddings.Embedder, collectionName string) vectorstores.VectorStore
+		mockStore func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error)
 		query     string
 		expected  error
 	}{
@@ -265,8 +374,8 @@ func TestLangChainWrapper_AskWithContext(t *testing.T) {
 					return "result", nil
 				},
 			},
-			mockStore: func(embedder embeddings.Embedder, collectionName string) vectorstores.VectorStore {
-				return nil
+			mockStore: func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
+				return nil, nil
 			},
 			query:    "test query",
 			expected: nil,
@@ -278,8 +387,8 @@ func TestLangChainWrapper_AskWithContext(t *testing.T) {
 					return "", errors.New("llm error")
 				},
 			},
-			mockStore: func(embedder embeddings.Embedder, collectionName string) vectorstores.VectorStore {
-				return nil
+			mockStore: func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
+				return nil, nil
 			},
 			query:    "test query",
 			expected: nil,
@@ -292,19 +401,19 @@ func TestLangChainWrapper_AskWithContext(t *testing.T) {
 				CallFunc: func(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
 					return "result", nil
 				}}
-			wrapper := NewLangChainWrapper(llm, nil, func(llm llms.Model) embeddings.Embedder {
+			wrapper := NewLangChainWrapper(llm, nil, func(llm llms.Model) (embeddings.Embedder, error) {
 				return &MockEmbedder{func(ctx context.Context, texts []string) ([][]float32, error) {
 					return [][]float32{}, nil
 				}, func(ctx context.Context, text string) ([]float32, error) {
 					return []float32{}, nil
-				}}
-			}, func(embedder embeddings.Embedder, collectionName string) vectorstores.VectorStore {
+				}}, nil
+			}, func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
 				return &MockVectorStore{MockAddDocuments: func(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error) {
 					return []string{}, nil
 				}, MockSimilaritySearch: func(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error) {
 					return []schema.Document{}, nil
-				}}
-			}, func() textsplitter.TextSplitter {
+				}}, nil
+			}, func(path string) textsplitter.TextSplitter {
 				return &MockTextSplitter{}
 			}, func(filePath string, size int64) (documentloaders.Loader, *os.File) {
 				l := &MockLoader{}
@@ -314,8 +423,8 @@ func TestLangChainWrapper_AskWithContext(t *testing.T) {
 				m := &MockChain{}
 				m.On("Call", mock.Anything, mock.Anything, mock.Anything).Return(map[string]any{}, nil)
 				return m
-			})
-			err := wrapper.AskWithContext("contextName", tt.query)
+			}, nil, nil, nil)
+			err := wrapper.AskWithContext("contextName", tt.query, nil)
 			if tt.expected == nil {
 				assert.NoError(t, err)
 			} else {
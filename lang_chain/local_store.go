@@ -0,0 +1,342 @@
+package lang_chain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidScoreThreshold = errors.New("score threshold must be between 0 and 1")
+	ErrInvalidFilters        = errors.New("filters must be a map[string]any of metadata values")
+	ErrMissingEmbedder       = errors.New("the local store needs an embedder")
+)
+
+var unsafeCollectionChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// collectionFileName is the file name of collectionName without extension. The characters
+// not allowed in file names are escaped as %XX, so different names never share a file.
+func collectionFileName(collectionName string) string {
+	return unsafeCollectionChars.ReplaceAllStringFunc(collectionName, func(unsafe string) string {
+		var escaped strings.Builder
+		for i := 0; i < len(unsafe); i++ {
+			fmt.Fprintf(&escaped, "%%%02X", unsafe[i])
+		}
+		return escaped.String()
+	})
+}
+
+// LocalStore is a vectorstores.VectorStore kept in a JSON lines file per collection,
+// so contexts work without running a vector database. Every search compares the query
+// with all the documents of the collection, which is fine for a few repositories.
+type LocalStore struct {
+	path     string
+	embedder embeddings.Embedder
+
+	mu      sync.Mutex
+	loaded  bool
+	entries []localEntry
+}
+
+type localEntry struct {
+	Id        string         `json:"id"`
+	Namespace string         `json:"namespace,omitempty"`
+	Content   string         `json:"content"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	Vector    []float32      `json:"vector"`
+}
+
+var _ vectorstores.VectorStore = &LocalStore{}
+var _ DocumentRemover = &LocalStore{}
+
+// NewLocalStore returns the store of collectionName, kept in a file under dir.
+func NewLocalStore(dir, collectionName string, embedder embeddings.Embedder) (*LocalStore, error) {
+	if collectionName == "" {
+		return nil, errors.New("collection name is required")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating the local store directory: %w", err)
+	}
+	return &LocalStore{path: filepath.Join(dir, collectionFileName(collectionName)+".jsonl"), embedder: embedder}, nil
+}
+
+// LocalCollections returns the names of the collections kept under dir.
+func LocalCollections(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the local store directory: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// AddDocuments embeds docs and appends them to the collection file.
+func (s *LocalStore) AddDocuments(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error) {
+	opts := s.options(options)
+	if opts.Deduplicater != nil {
+		unique := make([]schema.Document, 0, len(docs))
+		for _, doc := range docs {
+			if !opts.Deduplicater(ctx, doc) {
+				unique = append(unique, doc)
+			}
+		}
+		docs = unique
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	if opts.Embedder == nil {
+		return nil, ErrMissingEmbedder
+	}
+
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.PageContent
+	}
+	vectors, err := opts.Embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(docs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(docs), len(vectors))
+	}
+
+	entries := make([]localEntry, len(docs))
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = newId()
+		entries[i] = localEntry{Id: ids[i], Namespace: opts.NameSpace, Content: doc.PageContent, Metadata: doc.Metadata, Vector: vectors[i]}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = s.load(); err != nil {
+		return nil, err
+	}
+	if err = s.append(entries); err != nil {
+		return nil, err
+	}
+	s.entries = append(s.entries, entries...)
+	return ids, nil
+}
+
+// RemoveDocuments removes the documents with ids from the collection file.
+func (s *LocalStore) RemoveDocuments(_ context.Context, ids []string) error {
+	removed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		removed[id] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	kept := make([]localEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if !removed[entry.Id] {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(s.entries) {
+		return nil
+	}
+	if err := s.rewrite(kept); err != nil {
+		return err
+	}
+	s.entries = kept
+	return nil
+}
+
+// RemoveAllDocuments removes the collection file.
+func (s *LocalStore) RemoveAllDocuments(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	s.entries, s.loaded = nil, true
+	return nil
+}
+
+// SimilaritySearch returns the numDocuments documents closest to query by cosine similarity.
+// Filters are matched against the metadata of the documents, every key must be equal.
+func (s *LocalStore) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error) {
+	opts := s.options(options)
+	if opts.ScoreThreshold < 0 || opts.ScoreThreshold > 1 {
+		return nil, ErrInvalidScoreThreshold
+	}
+	filters, ok := opts.Filters.(map[string]any)
+	if opts.Filters != nil && !ok {
+		return nil, ErrInvalidFilters
+	}
+	if opts.Embedder == nil {
+		return nil, ErrMissingEmbedder
+	}
+	vector, err := opts.Embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = s.load(); err != nil {
+		return nil, err
+	}
+	var docs []schema.Document
+	for _, entry := range s.entries {
+		if entry.Namespace != opts.NameSpace || !matches(entry.Metadata, filters) {
+			continue
+		}
+		score := cosineSimilarity(vector, entry.Vector)
+		if score < opts.ScoreThreshold {
+			continue
+		}
+		docs = append(docs, schema.Document{PageContent: entry.Content, Metadata: entry.Metadata, Score: score})
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Score > docs[j].Score
+	})
+	if numDocuments >= 0 && len(docs) > numDocuments {
+		docs = docs[:numDocuments]
+	}
+	return docs, nil
+}
+
+func (s *LocalStore) options(options []vectorstores.Option) vectorstores.Options {
+	opts := vectorstores.Options{Embedder: s.embedder}
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+// load reads the collection file the first time it is needed, a missing file is an empty collection.
+func (s *LocalStore) load() error {
+	if s.loaded {
+		return nil
+	}
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// Vectors of large embedding models do not fit in the default line size
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry localEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("error reading %s line %d: %w", s.path, line, err)
+		}
+		s.entries = append(s.entries, entry)
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("error reading %s: %w", s.path, err)
+	}
+	s.loaded = true
+	return nil
+}
+
+func (s *LocalStore) append(entries []localEntry) error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(f)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if err = encoder.Encode(entry); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite replaces the collection file with entries.
+func (s *LocalStore) rewrite(entries []localEntry) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return common.WriteFileAtomic(s.path, data.Bytes())
+}
+
+// matches reports whether metadata has every filter value. Values are compared by their
+// text, as numbers read back from the file are always float64.
+func matches(metadata map[string]any, filters map[string]any) bool {
+	for key, expected := range filters {
+		value, ok := metadata[key]
+		if !ok || fmt.Sprint(value) != fmt.Sprint(expected) {
+			return false
+		}
+	}
+	return true
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+func newId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,155 @@
+package lang_chain
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+)
+
+// keywordEmbedder embeds a text as the count of a few keywords, so similarities are predictable.
+func keywordEmbedder() *MockEmbedder {
+	embed := func(text string) []float32 {
+		var vector []float32
+		for _, keyword := range []string{"go", "python", "rust"} {
+			vector = append(vector, float32(strings.Count(text, keyword)))
+		}
+		return vector
+	}
+	return &MockEmbedder{
+		EmbedDocumentsFunc: func(ctx context.Context, texts []string) ([][]float32, error) {
+			var vectors [][]float32
+			for _, text := range texts {
+				vectors = append(vectors, embed(text))
+			}
+			return vectors, nil
+		},
+		EmbedQueryFunc: func(ctx context.Context, text string) ([]float32, error) {
+			return embed(text), nil
+		},
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir, "utn/tp-1", keywordEmbedder())
+	assert.NoError(t, err)
+
+	ids, err := store.AddDocuments(context.Background(), []schema.Document{
+		{PageContent: "go go", Metadata: map[string]any{"path": "main.go", "line": 1}},
+		{PageContent: "go python", Metadata: map[string]any{"path": "tool.py", "line": 10}},
+		{PageContent: "rust", Metadata: map[string]any{"path": "lib.rs", "line": 1}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+	assert.FileExists(t, filepath.Join(dir, "utn%2Ftp-1.jsonl"))
+
+	tests := []struct {
+		name          string
+		numDocuments  int
+		options       []vectorstores.Option
+		expectedPaths []string
+		expectedError error
+	}{
+		{"Most similar first", 3, nil, []string{"main.go", "tool.py", "lib.rs"}, nil},
+		{"Limited", 1, nil, []string{"main.go"}, nil},
+		{"Score threshold", 3, []vectorstores.Option{vectorstores.WithScoreThreshold(0.5)}, []string{"main.go", "tool.py"}, nil},
+		{"Filters", 3, []vectorstores.Option{vectorstores.WithFilters(map[string]any{"line": 1})}, []string{"main.go", "lib.rs"}, nil},
+		{"Other namespace", 3, []vectorstores.Option{vectorstores.WithNameSpace("other")}, nil, nil},
+		{"Invalid score threshold", 3, []vectorstores.Option{vectorstores.WithScoreThreshold(2)}, nil, ErrInvalidScoreThreshold},
+		{"Invalid filters", 3, []vectorstores.Option{vectorstores.WithFilters("path = main.go")}, nil, ErrInvalidFilters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A new store reads the documents back from the collection file
+			reopened, err := NewLocalStore(dir, "utn/tp-1", keywordEmbedder())
+			assert.NoError(t, err)
+
+			docs, err := reopened.SimilaritySearch(context.Background(), "go", tt.numDocuments, tt.options...)
+
+			assert.Equal(t, tt.expectedError, err)
+			var paths []string
+			for _, doc := range docs {
+				paths = append(paths, doc.Metadata["path"].(string))
+			}
+			assert.Equal(t, tt.expectedPaths, paths)
+		})
+	}
+}
+
+func TestLocalStore_Collections(t *testing.T) {
+	dir := t.TempDir()
+	first, _ := NewLocalStore(dir, "first", keywordEmbedder())
+	second, _ := NewLocalStore(dir, "second", keywordEmbedder())
+
+	_, err := first.AddDocuments(context.Background(), []schema.Document{{PageContent: "go"}})
+	assert.NoError(t, err)
+
+	docs, err := second.SimilaritySearch(context.Background(), "go", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, docs)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+
+	names, err := LocalCollections(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first"}, names)
+	names, err = LocalCollections(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestLocalStore_CollectionNames(t *testing.T) {
+	dir := t.TempDir()
+	embedder := &MockEmbedder{EmbedDocumentsFunc: func(ctx context.Context, texts []string) ([][]float32, error) {
+		return [][]float32{{1, 0}}, nil
+	}}
+	// Names differing only in characters not allowed in file names are kept apart
+	names := []string{"a/b", "a_b", "a b", "a%2Fb", "tp1-código"}
+	for _, name := range names {
+		store, err := NewLocalStore(dir, name, embedder)
+		assert.NoError(t, err)
+		_, err = store.AddDocuments(context.Background(), []schema.Document{{PageContent: name}})
+		assert.NoError(t, err)
+	}
+
+	listed, err := LocalCollections(dir)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, names, listed)
+	assert.NotEqual(t, ManifestPath(dir, "a/b"), ManifestPath(dir, "a_b"))
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1, cosineSimilarity([]float32{1, 2}, []float32{2, 4}), 0.0001)
+	assert.InDelta(t, 0, cosineSimilarity([]float32{1, 0}, []float32{0, 1}), 0.0001)
+	assert.Equal(t, float32(0), cosineSimilarity([]float32{1}, []float32{1, 2}))
+	assert.Equal(t, float32(0), cosineSimilarity([]float32{0, 0}, []float32{1, 2}))
+}
+
+func TestLocalStore_RemoveDocuments(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "tp", keywordEmbedder())
+	assert.NoError(t, err)
+	ids, err := store.AddDocuments(context.Background(), []schema.Document{{PageContent: "go"}, {PageContent: "rust"}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.RemoveDocuments(context.Background(), ids[:1]))
+	// A new store reads what was written
+	store, err = NewLocalStore(filepath.Dir(store.path), "tp", keywordEmbedder())
+	assert.NoError(t, err)
+	docs, err := store.SimilaritySearch(context.Background(), "go rust", 10)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 1)
+	assert.Equal(t, "rust", docs[0].PageContent)
+
+	assert.NoError(t, store.RemoveAllDocuments(context.Background()))
+	docs, err = store.SimilaritySearch(context.Background(), "go rust", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, docs)
+}
@@ -0,0 +1,71 @@
+package lang_chain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"os"
+	"path/filepath"
+)
+
+// DocumentRemover is implemented by the stores that can remove documents, which loads
+// need to drop the chunks of the files that changed or were removed.
+type DocumentRemover interface {
+	RemoveDocuments(ctx context.Context, ids []string) error
+	// RemoveAllDocuments empties the collection.
+	RemoveAllDocuments(ctx context.Context) error
+}
+
+// Manifest records the files loaded in a collection, with a hash of their content and the
+// ids of their chunks in the store, so a load only embeds the files that changed.
+type Manifest struct {
+	// Files are keyed by absolute path
+	Files map[string]ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	// Hash is the SHA-256 of the content, empty when the file must be loaded again
+	Hash   string   `json:"hash"`
+	Chunks []string `json:"chunks"`
+}
+
+// ManifestPath returns the file keeping the manifest of collectionName under dir.
+func ManifestPath(dir, collectionName string) string {
+	return filepath.Join(dir, collectionFileName(collectionName)+".json")
+}
+
+// LoadManifest reads the manifest at path, nil when the collection was never loaded with one.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("error reading %s, load with --full to rebuild it: %w", path, err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]ManifestFile{}
+	}
+	return &manifest, nil
+}
+
+func (manifest *Manifest) Save(path string) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return common.WriteFileAtomic(path, data)
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,161 @@
+package lang_chain
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/documentloaders"
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/textsplitter"
+	"github.com/tmc/langchaingo/vectorstores"
+)
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := ManifestPath(dir, "utn/tp-1")
+	assert.Equal(t, filepath.Join(dir, "utn%2Ftp-1.json"), path)
+
+	manifest, err := LoadManifest(path)
+	assert.NoError(t, err)
+	assert.Nil(t, manifest)
+
+	manifest = &Manifest{Files: map[string]ManifestFile{"/src/main.go": {Hash: "abc", Chunks: []string{"1", "2"}}}}
+	assert.NoError(t, manifest.Save(path))
+	loaded, err := LoadManifest(path)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest, loaded)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+	_, err = LoadManifest(path)
+	assert.ErrorContains(t, err, "load with --full")
+}
+
+// localWrapper loads in a local store with the real file system, loader and splitter,
+// keeping the texts embedded.
+func localWrapper(t *testing.T) (wrapper *LangChainWrapper, contents func() []string, embeddedTexts func() []string) {
+	storeDir, manifests := t.TempDir(), t.TempDir()
+	var store *LocalStore
+	var mu sync.Mutex
+	var embedded []string
+	wrapper = NewLangChainWrapper(&mockLLM{}, &common.FS{}, func(llm llms.Model) (embeddings.Embedder, error) {
+		embedder := keywordEmbedder()
+		embedDocuments := embedder.EmbedDocumentsFunc
+		embedder.EmbedDocumentsFunc = func(ctx context.Context, texts []string) ([][]float32, error) {
+			mu.Lock()
+			embedded = append(embedded, texts...)
+			mu.Unlock()
+			return embedDocuments(ctx, texts)
+		}
+		return embedder, nil
+	}, func(embedder embeddings.Embedder, collectionName string) (vectorstores.VectorStore, error) {
+		if store == nil {
+			store, _ = NewLocalStore(storeDir, collectionName, embedder)
+		}
+		return store, nil
+	}, func(path string) textsplitter.TextSplitter {
+		return textsplitter.NewRecursiveCharacter()
+	}, func(filePath string, size int64) (documentloaders.Loader, *os.File) {
+		f, err := os.Open(filePath)
+		assert.NoError(t, err)
+		return documentloaders.NewText(f), f
+	}, nil, nil, func(collectionName string) (string, error) {
+		return ManifestPath(manifests, collectionName), nil
+	}, func(dir string) string {
+		return "4b825dc"
+	})
+	contents = func() []string {
+		docs, err := store.SimilaritySearch(context.Background(), "go python rust", 10)
+		assert.NoError(t, err)
+		var contents []string
+		for _, doc := range docs {
+			contents = append(contents, doc.PageContent)
+		}
+		return contents
+	}
+	embeddedTexts = func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		texts := embedded
+		embedded = nil
+		return texts
+	}
+	return
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+}
+
+func TestLangChainWrapper_LoadSourceCode_Incremental(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"main.go": "go go", "tool.py": "python", "lib.rs": "rust"})
+	wrapper, contents, embedded := localWrapper(t)
+
+	stats, err := wrapper.LoadSourceCode("tp", src, LoadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, LoadStats{Added: 3}, stats)
+	assert.ElementsMatch(t, []string{"go go", "python", "rust"}, embedded())
+
+	// Only the changed and new files are embedded again
+	writeFiles(t, src, map[string]string{"main.go": "go go go", "notes.go": "go"})
+	assert.NoError(t, os.Remove(filepath.Join(src, "lib.rs")))
+	stats, err = wrapper.LoadSourceCode("tp", src, LoadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, LoadStats{Added: 1, Changed: 1, Removed: 1, Unchanged: 1}, stats)
+	assert.ElementsMatch(t, []string{"go go go", "go"}, embedded())
+	assert.ElementsMatch(t, []string{"go go go", "python", "go"}, contents())
+
+	stats, err = wrapper.LoadSourceCode("tp", src, LoadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, LoadStats{Unchanged: 3}, stats)
+	assert.Empty(t, embedded())
+
+	stats, err = wrapper.LoadSourceCode("tp", src, LoadOptions{Full: true})
+	assert.NoError(t, err)
+	assert.Equal(t, LoadStats{Added: 3}, stats)
+	assert.ElementsMatch(t, []string{"go go go", "python", "go"}, contents())
+}
+
+func TestLangChainWrapper_LoadSourceCode_Skipped(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"main.go":                "go",
+		"go.sum":                 "go go",
+		"build.log":              "go",
+		".gitignore":             "*.log\nnode_modules/\n",
+		".git/HEAD":              "go",
+		"node_modules/a/x.go":    "go",
+		"web/.github-cli-ignore": "dist\n!keep.go\n*_gen.go\n",
+		"web/dist/app.go":        "go",
+		"web/api_gen.go":         "go",
+		"web/api.go":             "go go",
+		"web/big.go":             strings.Repeat("go ", 400),
+		"web/logo.png":           "\x89PNG\x00\x00go",
+		"vendor/lib.go":          "go",
+	})
+	wrapper, contents, _ := localWrapper(t)
+
+	stats, err := wrapper.LoadSourceCode("tp", src, LoadOptions{Include: []string{"*.go", "/.gitignore", "web/*.png"}, Exclude: []string{"vendor"}, MaxFileSize: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		common.SkipIgnored:  5, // .git, build.log, node_modules, web/dist, web/api_gen.go
+		common.SkipExcluded: 3, // go.sum, web/.github-cli-ignore, vendor
+		common.SkipTooLarge: 1,
+		common.SkipBinary:   1,
+	}, stats.Skipped)
+	assert.Equal(t, 3, stats.Added)
+	assert.ElementsMatch(t, []string{"go", "go go", "*.log\nnode_modules/"}, contents())
+
+	_, err = wrapper.LoadSourceCode("tp", src, LoadOptions{Include: []string{"[a-"}})
+	assert.Equal(t, common.KindValidation, common.KindOf(err))
+}
@@ -0,0 +1,138 @@
+package lang_chain
+
+import (
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/tmc/langchaingo/schema"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metadata of the chunks of loaded files, questions can be limited to the chunks having
+// some values of them
+const (
+	MetadataPath      = "path"       // relative to the loaded directory, with slashes
+	MetadataStartLine = "start_line" // first line of the chunk, from 1
+	MetadataEndLine   = "end_line"
+	MetadataLanguage  = "language"
+	MetadataSymbol    = "symbol" // declaration enclosing the chunk, when known
+	// MetadataCommit is the HEAD of the git repository of the loaded directory when the
+	// chunk was loaded, when there is one
+	MetadataCommit = "commit"
+)
+
+var metadataKeys = []string{MetadataPath, MetadataStartLine, MetadataEndLine, MetadataLanguage, MetadataSymbol, MetadataCommit}
+
+// annotateChunks sets the metadata of docs, the chunks of the file at path relative to
+// the loaded directory, whose content is text.
+func annotateChunks(docs []schema.Document, text, path, commit string) {
+	language := LanguageOf(path)
+	declarations, _ := declarationsOf(language, text)
+	// Chunks come in order, overlapping chunks start after the previous one
+	from := 0
+	for i := range docs {
+		metadata := make(map[string]any, len(docs[i].Metadata)+len(metadataKeys))
+		for key, value := range docs[i].Metadata {
+			metadata[key] = value
+		}
+		metadata[MetadataPath] = path
+		metadata[MetadataLanguage] = language
+		if commit != "" {
+			metadata[MetadataCommit] = commit
+		}
+		chunk := docs[i].PageContent
+		if start := locateChunk(text, chunk, from); start >= 0 {
+			from = start + 1
+			startLine := 1 + strings.Count(text[:start], "\n")
+			metadata[MetadataStartLine] = startLine
+			metadata[MetadataEndLine] = startLine + strings.Count(chunk, "\n")
+			if symbol := enclosingSymbol(declarations, start, start+len(chunk)); symbol != "" {
+				metadata[MetadataSymbol] = symbol
+			}
+		}
+		docs[i].Metadata = metadata
+	}
+}
+
+// locateChunk returns the offset of chunk in text from offset from, looking for its first
+// line when the splitter changed the chunk, -1 when it is not found.
+func locateChunk(text, chunk string, from int) int {
+	from = min(from, len(text))
+	if index := strings.Index(text[from:], chunk); index >= 0 {
+		return from + index
+	}
+	firstLine, _, _ := strings.Cut(chunk, "\n")
+	if firstLine == "" {
+		return -1
+	}
+	if index := strings.Index(text[from:], firstLine); index >= 0 {
+		return from + index
+	}
+	return -1
+}
+
+// enclosingSymbol returns the name of the declaration the chunk from start to end is in,
+// or of the first declaration in it.
+func enclosingSymbol(declarations []declaration, start, end int) string {
+	for _, declaration := range declarations {
+		if declaration.name != "" && declaration.start <= start && start < declaration.end {
+			return declaration.name
+		}
+	}
+	for _, declaration := range declarations {
+		if declaration.name != "" && declaration.start >= start && declaration.start < end {
+			return declaration.name
+		}
+	}
+	return ""
+}
+
+// chunkFilters converts the metadata values questions are limited to, line numbers must
+// be numbers.
+func chunkFilters(filters map[string]string) (map[string]any, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	converted := make(map[string]any, len(filters))
+	for key, value := range filters {
+		switch key {
+		case MetadataStartLine, MetadataEndLine:
+			line, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, common.Errorf(common.KindValidation, "invalid filter %s=%s, lines are numbers", key, value)
+			}
+			converted[key] = line
+		case MetadataPath, MetadataLanguage, MetadataSymbol, MetadataCommit:
+			converted[key] = value
+		default:
+			return nil, common.Errorf(common.KindValidation, "unknown filter %q, use %s", key, strings.Join(metadataKeys, ", "))
+		}
+	}
+	return converted, nil
+}
+
+// ChunkSource describes where a chunk comes from, e.g. cmd/calls.go:10-24 (AskLlm).
+func ChunkSource(doc schema.Document) string {
+	source := fmt.Sprint(doc.Metadata[MetadataPath])
+	if doc.Metadata[MetadataPath] == nil {
+		source = "unknown"
+	}
+	if start, ok := doc.Metadata[MetadataStartLine]; ok {
+		source += fmt.Sprintf(":%v-%v", start, doc.Metadata[MetadataEndLine])
+	}
+	if symbol, ok := doc.Metadata[MetadataSymbol]; ok {
+		source += fmt.Sprintf(" (%v)", symbol)
+	}
+	return source
+}
+
+// sortedKeys returns the keys of filters in order, so requests built from them are stable.
+func sortedKeys(filters map[string]any) []string {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package lang_chain
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+)
+
+func TestAnnotateChunks(t *testing.T) {
+	chunks, err := NewCodeSplitter(common.LanguageGo, common.ChunkSize{Size: 120}).SplitText(goSource)
+	assert.NoError(t, err)
+	docs := make([]schema.Document, len(chunks))
+	for i, chunk := range chunks {
+		docs[i] = schema.Document{PageContent: chunk}
+	}
+
+	annotateChunks(docs, goSource, "cmd/main.go", "4b825dc")
+	assert.Equal(t, map[string]any{"path": "cmd/main.go", "language": "go", "commit": "4b825dc", "start_line": 1, "end_line": 8, "symbol": "Greeter"}, docs[0].Metadata)
+	assert.Equal(t, map[string]any{"path": "cmd/main.go", "language": "go", "commit": "4b825dc", "start_line": 10, "end_line": 14, "symbol": "Greeter.Greet"}, docs[1].Metadata)
+	assert.Equal(t, map[string]any{"path": "cmd/main.go", "language": "go", "commit": "4b825dc", "start_line": 16, "end_line": 18, "symbol": "main"}, docs[2].Metadata)
+	assert.Equal(t, "cmd/main.go:10-14 (Greeter.Greet)", ChunkSource(docs[1]))
+
+	// Chunks the splitter changed are found by their first line, lines not found are left out
+	docs = []schema.Document{{PageContent: "hello\nchanged"}, {PageContent: "missing"}}
+	annotateChunks(docs, "intro\nhello\nworld\n", "README.md", "")
+	assert.Equal(t, map[string]any{"path": "README.md", "language": "default", "start_line": 2, "end_line": 3}, docs[0].Metadata)
+	assert.Equal(t, map[string]any{"path": "README.md", "language": "default"}, docs[1].Metadata)
+	assert.Equal(t, "README.md", ChunkSource(docs[1]))
+}
+
+func TestDeclarationNames(t *testing.T) {
+	tests := []struct {
+		language string
+		text     string
+		expected []string
+	}{
+		{common.LanguagePython, "import os\n\nclass Tool:\n    async def run(self):\n        pass\n", []string{"Tool", "run"}},
+		{common.LanguageTypeScript, "export default async function load() {}\nexport const b = 2\nmodule.exports = {}\n", []string{"load", "b", ""}},
+		{common.LanguageJava, "public class Tool {\n    private int count;\n    public static <T> List<T> run(String name) {\n    }\n}\n", []string{"Tool", "", "run"}},
+		{common.LanguageGo, "package p\n\nimport \"fmt\"\n\nvar x, y = 1, 2\n\nfunc (s *Store[K]) Get() {}\n", []string{"", "x", "Store.Get"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			declarations, ok := declarationsOf(tt.language, tt.text)
+			assert.True(t, ok)
+			var names []string
+			for _, declaration := range declarations {
+				names = append(names, declaration.name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestChunkFilters(t *testing.T) {
+	filters, err := chunkFilters(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, filters)
+
+	filters, err = chunkFilters(map[string]string{"path": "cmd/calls.go", "start_line": "10"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"path": "cmd/calls.go", "start_line": 10}, filters)
+
+	_, err = chunkFilters(map[string]string{"end_line": "last"})
+	assert.ErrorContains(t, err, "lines are numbers")
+	_, err = chunkFilters(map[string]string{"author": "alice"})
+	assert.ErrorContains(t, err, `unknown filter "author", use path, start_line, end_line, language, symbol, commit`)
+	assert.Equal(t, common.KindValidation, common.KindOf(err))
+}
+
+func TestLangChainWrapper_LoadSourceCode_Metadata(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"cmd/main.go": "package main\n\nfunc main() {\n\tgo run()\n}\n",
+		"tool.py":     "def tool():\n    return 'python'\n",
+	})
+	wrapper, _, _ := localWrapper(t)
+	_, err := wrapper.LoadSourceCode("tp", src, LoadOptions{})
+	assert.NoError(t, err)
+
+	store, err := wrapper.contextStore("tp")
+	assert.NoError(t, err)
+	filters, err := chunkFilters(map[string]string{"symbol": "main", "start_line": "1"})
+	assert.NoError(t, err)
+	// Read back from the file, like a new process would
+	store, err = NewLocalStore(filepath.Dir(store.(*LocalStore).path), "tp", keywordEmbedder())
+	assert.NoError(t, err)
+	docs, err := store.SimilaritySearch(context.Background(), "go python", 10, vectorstores.WithFilters(filters))
+	assert.NoError(t, err)
+	if assert.Len(t, docs, 1) {
+		assert.Equal(t, "cmd/main.go:1-5 (main)", ChunkSource(docs[0]))
+		assert.Equal(t, "4b825dc", docs[0].Metadata[MetadataCommit])
+		assert.Equal(t, common.LanguageGo, docs[0].Metadata[MetadataLanguage])
+	}
+}
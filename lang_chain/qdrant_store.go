@@ -0,0 +1,101 @@
+package lang_chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+	"github.com/tmc/langchaingo/vectorstores/qdrant"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// QdrantStore is the langchaingo Qdrant store with the removal of documents it lacks.
+type QdrantStore struct {
+	qdrant.Store
+	url        string
+	collection string
+	client     *http.Client
+}
+
+var _ vectorstores.VectorStore = &QdrantStore{}
+var _ DocumentRemover = &QdrantStore{}
+
+func NewQdrantStore(qdrantUrl url.URL, collectionName string, embedder embeddings.Embedder) (*QdrantStore, error) {
+	store, err := qdrant.New(
+		qdrant.WithURL(qdrantUrl),
+		qdrant.WithCollectionName(collectionName),
+		qdrant.WithEmbedder(embedder),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// The langchaingo store always uses the default client
+	return &QdrantStore{Store: store, url: qdrantUrl.String(), collection: collectionName, client: http.DefaultClient}, nil
+}
+
+// SimilaritySearch searches like qdrant.Store, taking the filters as a map of metadata
+// values every chunk found must have, like the local store.
+func (s *QdrantStore) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error) {
+	var opts vectorstores.Options
+	for _, option := range options {
+		option(&opts)
+	}
+	if filters, ok := opts.Filters.(map[string]any); ok {
+		options = append(options, vectorstores.WithFilters(qdrantFilter(filters)))
+	}
+	return s.Store.SimilaritySearch(ctx, query, numDocuments, options...)
+}
+
+// qdrantFilter matches the points whose payload has every value of filters.
+func qdrantFilter(filters map[string]any) map[string]any {
+	must := make([]map[string]any, 0, len(filters))
+	for _, key := range sortedKeys(filters) {
+		must = append(must, map[string]any{"key": key, "match": map[string]any{"value": filters[key]}})
+	}
+	return map[string]any{"must": must}
+}
+
+// RemoveDocuments removes the points with ids, the ids AddDocuments returned.
+func (s *QdrantStore) RemoveDocuments(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.deletePoints(ctx, map[string]any{"points": ids})
+}
+
+// RemoveAllDocuments removes every point of the collection, keeping the collection.
+func (s *QdrantStore) RemoveAllDocuments(ctx context.Context) error {
+	// An empty filter matches every point
+	return s.deletePoints(ctx, map[string]any{"filter": map[string]any{}})
+}
+
+func (s *QdrantStore) deletePoints(ctx context.Context, selector map[string]any) error {
+	body, err := json.Marshal(selector)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/collections/%s/points/delete?wait=true", strings.TrimRight(s.url, "/"), url.PathEscape(s.collection))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		// Nothing was loaded in the collection yet
+		return nil
+	}
+	return fmt.Errorf("error removing documents from Qdrant collection %s: %s", s.collection, resp.Status)
+}
@@ -0,0 +1,61 @@
+package lang_chain
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/vectorstores"
+)
+
+func TestQdrantStore_RemoveDocuments(t *testing.T) {
+	var requests []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	serverUrl, _ := url.Parse(server.URL)
+	store, err := NewQdrantStore(*serverUrl, "tp", keywordEmbedder())
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.RemoveDocuments(context.Background(), nil))
+	assert.NoError(t, store.RemoveDocuments(context.Background(), []string{"a", "b"}))
+	assert.NoError(t, store.RemoveAllDocuments(context.Background()))
+	assert.Equal(t, []string{
+		`POST /collections/tp/points/delete?wait=true {"points":["a","b"]}`,
+		`POST /collections/tp/points/delete?wait=true {"filter":{}}`,
+	}, requests)
+
+	// The collection is created by the first load
+	status = http.StatusNotFound
+	assert.NoError(t, store.RemoveAllDocuments(context.Background()))
+	status = http.StatusInternalServerError
+	assert.ErrorContains(t, store.RemoveAllDocuments(context.Background()), "500 Internal Server Error")
+}
+
+func TestQdrantStore_SimilaritySearch(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		_, _ = w.Write([]byte(`{"result":[{"id":"a","score":0.9,"payload":{"content":"func main() {}","path":"main.go","start_line":3,"end_line":3}}]}`))
+	}))
+	defer server.Close()
+	serverUrl, _ := url.Parse(server.URL)
+	store, err := NewQdrantStore(*serverUrl, "tp", keywordEmbedder())
+	assert.NoError(t, err)
+
+	docs, err := store.SimilaritySearch(context.Background(), "go", 5, vectorstores.WithFilters(map[string]any{"path": "main.go", "start_line": 3}))
+	assert.NoError(t, err)
+	assert.Contains(t, body, `"filter":{"must":[{"key":"path","match":{"value":"main.go"}},{"key":"start_line","match":{"value":3}}]}`)
+	if assert.Len(t, docs, 1) {
+		assert.Equal(t, "main.go:3-3", ChunkSource(docs[0]))
+	}
+}
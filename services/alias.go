@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+)
+
+type IAliasService interface {
+	List() error
+	Set(name, expansion string, shell bool) error
+	Delete(name string) error
+	Names() ([]string, error)
+	// Expand returns the expansion of the alias name called with args, nil when name is
+	// not an alias.
+	Expand(name string, args []string) (*AliasExpansion, error)
+	// RunShell runs the script of a shell alias, its exit code is kept in the returned error.
+	RunShell(expansion *AliasExpansion) error
+}
+
+// AliasExpansion is what an alias call stands for: the arguments replacing it, or the
+// script run by the shell with Args as $1, $2...
+type AliasExpansion struct {
+	Args   []string
+	Shell  bool
+	Script string
+	Name   string
+}
+
+// ShellRunner runs script with sh, name as $0 and args as the positional parameters.
+type ShellRunner func(name, script string, args []string) error
+
+func NewAliasService(configLoader common.ConfigLoader, configUpdater common.ConfigUpdater, shellRunner ShellRunner, consumer func(data string)) *AliasService {
+	return &AliasService{
+		configLoader:  configLoader,
+		configUpdater: configUpdater,
+		shellRunner:   shellRunner,
+		consumerFunc:  consumer,
+	}
+}
+
+type AliasService struct {
+	configLoader  common.ConfigLoader
+	configUpdater common.ConfigUpdater
+	shellRunner   ShellRunner
+	consumerFunc  func(data string)
+}
+
+// List prints the aliases with what they expand to, shell aliases start with !.
+func (service *AliasService) List() (err error) {
+	aliases, err := common.LoadAliases(service.configLoader)
+	if err != nil {
+		return
+	}
+	if len(aliases) == 0 {
+		service.consumerFunc("No aliases defined, add one with `alias set`")
+		return
+	}
+	for _, alias := range aliases {
+		service.consumerFunc(fmt.Sprintf("%s\t%s", alias.Name, alias.Expansion))
+	}
+	return
+}
+
+func (service *AliasService) Set(name, expansion string, shell bool) (err error) {
+	if shell {
+		expansion = common.ShellAliasPrefix + expansion
+	}
+	err = common.SetAlias(service.configUpdater, common.Alias{Name: name, Expansion: expansion})
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Alias %s set to %s", name, expansion))
+	return
+}
+
+func (service *AliasService) Delete(name string) (err error) {
+	err = common.DeleteAlias(service.configUpdater, name)
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Alias %s deleted", name))
+	return
+}
+
+// Names returns the names of the aliases, sorted.
+func (service *AliasService) Names() ([]string, error) {
+	aliases, err := common.LoadAliases(service.configLoader)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(aliases))
+	for i, alias := range aliases {
+		names[i] = alias.Name
+	}
+	return names, nil
+}
+
+func (service *AliasService) Expand(name string, args []string) (*AliasExpansion, error) {
+	alias, err := common.FindAlias(service.configLoader, name)
+	if err != nil || alias == nil {
+		return nil, err
+	}
+	if alias.Shell() {
+		return &AliasExpansion{Args: args, Shell: true, Script: alias.Script(), Name: name}, nil
+	}
+	expanded, err := alias.Expand(args)
+	if err != nil {
+		return nil, err
+	}
+	return &AliasExpansion{Args: expanded, Name: name}, nil
+}
+
+func (service *AliasService) RunShell(expansion *AliasExpansion) error {
+	return service.shellRunner(expansion.Name, expansion.Script, expansion.Args)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasService(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(common.ConfigEnvVar, "")
+	stored := map[string]any{}
+	loader := func(path string, config interface{}) error {
+		aliases := map[string]string{}
+		values, _ := stored["aliases"].(map[string]any)
+		for name, expansion := range values {
+			aliases[name] = expansion.(string)
+		}
+		config.(*common.FileConfig).Aliases = aliases
+		return nil
+	}
+	updater := func(path string, update func(values map[string]any) error) error {
+		return update(stored)
+	}
+	var ran []string
+	runner := func(name, script string, args []string) error {
+		ran = append([]string{name, script}, args...)
+		return nil
+	}
+	var output []string
+	service := NewAliasService(loader, updater, runner, func(data string) { output = append(output, data) })
+
+	assert.NoError(t, service.List())
+	assert.NoError(t, service.Set("invite-tp", "repository invite -r tp-$1 -c $2", false))
+	assert.NoError(t, service.Set("tps", "github-cli repository list | grep tp-", true))
+	assert.NoError(t, service.List())
+	assert.Equal(t, []string{
+		"No aliases defined, add one with `alias set`",
+		"Alias invite-tp set to repository invite -r tp-$1 -c $2",
+		"Alias tps set to !github-cli repository list | grep tp-",
+		"invite-tp\trepository invite -r tp-$1 -c $2",
+		"tps\t!github-cli repository list | grep tp-",
+	}, output)
+
+	names, err := service.Names()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"invite-tp", "tps"}, names)
+
+	expansion, err := service.Expand("invite-tp", []string{"3", "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, &AliasExpansion{Args: []string{"repository", "invite", "-r", "tp-3", "-c", "alice"}, Name: "invite-tp"}, expansion)
+
+	expansion, err = service.Expand("tps", []string{"-v"})
+	assert.NoError(t, err)
+	assert.True(t, expansion.Shell)
+	assert.NoError(t, service.RunShell(expansion))
+	assert.Equal(t, []string{"tps", "github-cli repository list | grep tp-", "-v"}, ran)
+
+	expansion, err = service.Expand("grades", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, expansion)
+
+	output = nil
+	assert.NoError(t, service.Delete("tps"))
+	assert.Equal(t, []string{"Alias tps deleted"}, output)
+	assert.Error(t, service.Delete("tps"))
+}
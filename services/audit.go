@@ -0,0 +1,83 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Audit output formats
+const (
+	AuditFormatText = "text"
+	AuditFormatJson = "json"
+)
+
+type IAuditService interface {
+	Show(filter common.AuditFilter, format string) error
+}
+
+// AuditReader returns the audit entries selected by filter, oldest first.
+type AuditReader func(filter common.AuditFilter) ([]common.AuditEntry, error)
+
+func NewAuditService(reader AuditReader, consumer func(data string)) *AuditService {
+	return &AuditService{
+		reader:       reader,
+		consumerFunc: consumer,
+	}
+}
+
+type AuditService struct {
+	reader       AuditReader
+	consumerFunc func(data string)
+}
+
+// Show prints the entries selected by filter as a table or as a JSON array.
+func (service *AuditService) Show(filter common.AuditFilter, format string) (err error) {
+	if format != AuditFormatText && format != AuditFormatJson {
+		return common.Errorf(common.KindValidation, "invalid output %q, use %s or %s", format, AuditFormatText, AuditFormatJson)
+	}
+	entries, err := service.reader(filter)
+	if err != nil {
+		return
+	}
+	if format == AuditFormatJson {
+		if entries == nil {
+			entries = []common.AuditEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		service.consumerFunc(string(data))
+		return nil
+	}
+	if len(entries) == 0 {
+		service.consumerFunc("No audit entries found")
+		return
+	}
+	var table strings.Builder
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "TIME\tPROFILE\tACTION\tREPO\tUSER\tSTATUS\tERROR")
+	for _, entry := range entries {
+		status := "-"
+		if entry.Status != 0 {
+			status = strconv.Itoa(entry.Status)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Time.Local().Format(time.DateTime),
+			orDash(entry.Profile), entry.Action, orDash(entry.Repo), orDash(entry.User), status, entry.Error)
+	}
+	writer.Flush()
+	service.consumerFunc(strings.TrimSuffix(table.String(), "\n"))
+	return
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
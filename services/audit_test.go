@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditService_Show(t *testing.T) {
+	entry := common.AuditEntry{Time: time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local), Profile: "utn", Command: "github-cli repository invite -r tp1 -c alice",
+		Action: "invite", Repo: "utn/tp1", User: "alice", Method: "PUT", Path: "/repos/utn/tp1/collaborators/alice", Status: 201}
+	tests := []struct {
+		name           string
+		entries        []common.AuditEntry
+		format         string
+		expectedOutput []string
+	}{
+		{"Text", []common.AuditEntry{entry, {Time: entry.Time, Action: "post user", Error: "connection refused"}}, "text", []string{
+			"TIME                 PROFILE  ACTION     REPO     USER   STATUS  ERROR\n" +
+				"2024-03-01 10:00:00  utn      invite     utn/tp1  alice  201     \n" +
+				"2024-03-01 10:00:00  -        post user  -        -      -       connection refused"}},
+		{"Text without entries", nil, "text", []string{"No audit entries found"}},
+		{"Json", []common.AuditEntry{entry}, "json", []string{`[
+  {
+    "time": "` + entry.Time.Format(time.RFC3339) + `",
+    "profile": "utn",
+    "command": "github-cli repository invite -r tp1 -c alice",
+    "action": "invite",
+    "repo": "utn/tp1",
+    "user": "alice",
+    "method": "PUT",
+    "path": "/repos/utn/tp1/collaborators/alice",
+    "status": 201
+  }
+]`}},
+		{"Json without entries", nil, "json", []string{"[]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output []string
+			filter := common.AuditFilter{Repo: "tp1"}
+			service := NewAuditService(func(f common.AuditFilter) ([]common.AuditEntry, error) {
+				assert.Equal(t, filter, f)
+				return tt.entries, nil
+			}, func(data string) { output = append(output, data) })
+
+			err := service.Show(filter, tt.format)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, output)
+		})
+	}
+}
+
+func TestAuditService_ShowErrors(t *testing.T) {
+	service := NewAuditService(func(common.AuditFilter) ([]common.AuditEntry, error) {
+		return nil, errors.New("permission denied")
+	}, nil)
+
+	err := service.Show(common.AuditFilter{}, "yaml")
+	assert.EqualError(t, err, `invalid output "yaml", use text or json`)
+	assert.Equal(t, common.KindValidation, common.KindOf(err))
+
+	assert.EqualError(t, service.Show(common.AuditFilter{}, "text"), "permission denied")
+}
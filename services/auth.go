@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	github2 "github.com/ffumaneri/github-cli/github"
+	"strings"
+)
+
+type IAuthService interface {
+	Login(options LoginOptions) error
+	Status() error
+	Logout() error
+	Token() error
+}
+
+// LoginOptions configures the OAuth device flow used by Login.
+type LoginOptions struct {
+	ClientId      string
+	DeviceCodeUrl string
+	TokenUrl      string
+	Scopes        []string
+}
+
+var ErrNotLoggedIn = common.NewError(common.KindAuth, "not logged in, run `auth login` first")
+
+func NewAuthService(store common.ICredentialsStore, deviceFlow func(options LoginOptions) github2.IDeviceFlow, githubWrapper func(token string) github2.IGithubWrapper, consumer func(data string)) *AuthService {
+	return &AuthService{
+		store:         store,
+		deviceFlow:    deviceFlow,
+		githubWrapper: githubWrapper,
+		consumerFunc:  consumer,
+	}
+}
+
+type AuthService struct {
+	store         common.ICredentialsStore
+	deviceFlow    func(options LoginOptions) github2.IDeviceFlow
+	githubWrapper func(token string) github2.IGithubWrapper
+	consumerFunc  func(data string)
+}
+
+func (service *AuthService) Login(options LoginOptions) (err error) {
+	flow := service.deviceFlow(options)
+	code, err := flow.RequestCode()
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("First copy your one-time code: %s", code.UserCode))
+	service.consumerFunc(fmt.Sprintf("Then open %s in your browser and paste it", code.VerificationUri))
+
+	token, err := flow.PollToken(code)
+	if err != nil {
+		return
+	}
+	login, scopes, err := service.githubWrapper(token).GetAuthenticatedUser()
+	if err != nil {
+		return
+	}
+	err = service.store.Save(&common.Credentials{Token: token, Login: login, Scopes: strings.Join(scopes, ", ")})
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Logged in as %s", login))
+	return
+}
+
+func (service *AuthService) Status() (err error) {
+	credentials, err := service.storedCredentials()
+	if err != nil {
+		return
+	}
+	login, scopes, err := service.githubWrapper(credentials.Token).GetAuthenticatedUser()
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Logged in as %s", login))
+	if len(scopes) == 0 {
+		service.consumerFunc("Token scopes: none")
+	} else {
+		service.consumerFunc(fmt.Sprintf("Token scopes: %s", strings.Join(scopes, ", ")))
+	}
+	return
+}
+
+func (service *AuthService) Logout() (err error) {
+	credentials, err := service.storedCredentials()
+	if err != nil {
+		return
+	}
+	err = service.store.Delete()
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Logged out %s", credentials.Login))
+	return
+}
+
+func (service *AuthService) Token() (err error) {
+	credentials, err := service.storedCredentials()
+	if err != nil {
+		return
+	}
+	service.consumerFunc(credentials.Token)
+	return
+}
+
+func (service *AuthService) storedCredentials() (*common.Credentials, error) {
+	credentials, err := service.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil || credentials.Token == "" {
+		return nil, ErrNotLoggedIn
+	}
+	return credentials, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	github2 "github.com/ffumaneri/github-cli/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCredentialsStore struct {
+	mock.Mock
+}
+
+func (m *MockCredentialsStore) Load() (*common.Credentials, error) {
+	args := m.Called()
+	credentials, _ := args.Get(0).(*common.Credentials)
+	return credentials, args.Error(1)
+}
+
+func (m *MockCredentialsStore) Save(credentials *common.Credentials) error {
+	args := m.Called(credentials)
+	return args.Error(0)
+}
+
+func (m *MockCredentialsStore) Delete() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockDeviceFlow struct {
+	mock.Mock
+}
+
+func (m *MockDeviceFlow) RequestCode() (*github2.DeviceCode, error) {
+	args := m.Called()
+	code, _ := args.Get(0).(*github2.DeviceCode)
+	return code, args.Error(1)
+}
+
+func (m *MockDeviceFlow) PollToken(code *github2.DeviceCode) (string, error) {
+	args := m.Called(code)
+	return args.String(0), args.Error(1)
+}
+
+func newTestAuthService(store common.ICredentialsStore, flow github2.IDeviceFlow, wrapper github2.IGithubWrapper, output *[]string) *AuthService {
+	return NewAuthService(store, func(options LoginOptions) github2.IDeviceFlow {
+		return flow
+	}, func(token string) github2.IGithubWrapper {
+		return wrapper
+	}, func(data string) {
+		*output = append(*output, data)
+	})
+}
+
+func TestAuthService_Login(t *testing.T) {
+	tests := []struct {
+		name          string
+		pollError     error
+		expectedError error
+	}{
+		{"Success", nil, nil},
+		{"Access denied", errors.New("access_denied"), errors.New("access_denied")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := &github2.DeviceCode{UserCode: "ABCD-1234", VerificationUri: "https://github.com/login/device"}
+			flow := new(MockDeviceFlow)
+			flow.On("RequestCode").Return(code, nil)
+			flow.On("PollToken", code).Return("gho_token", tt.pollError)
+			wrapper := new(MockGithubWrapper)
+			wrapper.On("GetAuthenticatedUser").Return("user1", []string{"repo"}, nil)
+			store := new(MockCredentialsStore)
+			store.On("Save", &common.Credentials{Token: "gho_token", Login: "user1", Scopes: "repo"}).Return(nil)
+
+			var output []string
+			err := newTestAuthService(store, flow, wrapper, &output).Login(LoginOptions{ClientId: "id"})
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Contains(t, output[0], "ABCD-1234")
+			if err == nil {
+				assert.Equal(t, "Logged in as user1", output[len(output)-1])
+				store.AssertExpectations(t)
+			} else {
+				store.AssertNotCalled(t, "Save", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAuthService_Status(t *testing.T) {
+	tests := []struct {
+		name           string
+		credentials    *common.Credentials
+		expectedOutput []string
+		expectedError  error
+	}{
+		{"Logged in", &common.Credentials{Token: "gho_token"}, []string{"Logged in as user1", "Token scopes: repo, read:org"}, nil},
+		{"Not logged in", nil, nil, ErrNotLoggedIn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := new(MockCredentialsStore)
+			store.On("Load").Return(tt.credentials, nil)
+			wrapper := new(MockGithubWrapper)
+			wrapper.On("GetAuthenticatedUser").Return("user1", []string{"repo", "read:org"}, nil)
+
+			var output []string
+			err := newTestAuthService(store, nil, wrapper, &output).Status()
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expectedOutput, output)
+		})
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	store := new(MockCredentialsStore)
+	store.On("Load").Return(&common.Credentials{Token: "gho_token", Login: "user1"}, nil)
+	store.On("Delete").Return(nil)
+
+	var output []string
+	err := newTestAuthService(store, nil, nil, &output).Logout()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Logged out user1"}, output)
+	store.AssertExpectations(t)
+}
+
+func TestAuthService_Token(t *testing.T) {
+	store := new(MockCredentialsStore)
+	store.On("Load").Return(&common.Credentials{Token: "gho_token"}, nil)
+
+	var output []string
+	err := newTestAuthService(store, nil, nil, &output).Token()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gho_token"}, output)
+}
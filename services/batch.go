@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/ffumaneri/github-cli/concurrency"
+	github2 "github.com/ffumaneri/github-cli/github"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type IBatchService interface {
+	// Run runs the steps of the batch file at path, with vars overriding its variables and
+	// concurrency, when not zero, the concurrency of the file.
+	Run(path string, vars map[string]string, concurrency int) error
+}
+
+// FileReader returns the content of the file at path.
+type FileReader func(path string) ([]byte, error)
+
+func NewBatchService(githubService IGithubService, readFile FileReader, consumer func(data string)) *BatchService {
+	return &BatchService{
+		githubService: githubService,
+		readFile:      readFile,
+		consumerFunc:  consumer,
+	}
+}
+
+type BatchService struct {
+	githubService IGithubService
+	readFile      FileReader
+	consumerFunc  func(data string)
+}
+
+// Outcomes of a step
+const (
+	stepOk      = "ok"
+	stepFailed  = "failed"
+	stepIgnored = "ignored" // failed with continue_on_error
+	stepSkipped = "skipped"
+)
+
+type stepResult struct {
+	status string
+	err    error
+}
+
+// batchRun is the state shared by the steps running at the same time.
+type batchRun struct {
+	service *BatchService
+	steps   []common.BatchStep
+	results []stepResult
+	// stopped is set when a step without continue_on_error fails, the steps not started
+	// yet are skipped
+	stopped atomic.Bool
+	mu      sync.Mutex
+	done    int
+}
+
+func (service *BatchService) Run(path string, vars map[string]string, concurrency int) error {
+	if concurrency < 0 || concurrency > common.MaxBatchConcurrency {
+		return common.Errorf(common.KindValidation, "concurrency must be between 1 and %d, got %d", common.MaxBatchConcurrency, concurrency)
+	}
+	data, err := service.readFile(path)
+	if err != nil {
+		return common.WrapError(common.KindValidation, err, "cannot read the batch file "+path)
+	}
+	batch, err := common.ParseBatchFile(data, vars)
+	if err != nil {
+		return err
+	}
+	if err = service.readSources(batch.Steps, filepath.Dir(path)); err != nil {
+		return err
+	}
+	if concurrency != 0 {
+		batch.Concurrency = concurrency
+	}
+	run := &batchRun{service: service, steps: batch.Steps, results: make([]stepResult, len(batch.Steps))}
+	// A step with wait starts a new group, run once the previous one finished
+	for start := 0; start < len(batch.Steps); {
+		end := start + 1
+		for end < len(batch.Steps) && !batch.Steps[end].Wait {
+			end++
+		}
+		run.group(start, end, batch.Concurrency)
+		start = end
+	}
+	return run.report()
+}
+
+// readSources loads the content of the push-file steps taking it from a file, before
+// anything runs.
+func (service *BatchService) readSources(steps []common.BatchStep, dir string) error {
+	for i := range steps {
+		if steps[i].Source == "" {
+			continue
+		}
+		source := steps[i].Source
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(dir, source)
+		}
+		content, err := service.readFile(source)
+		if err != nil {
+			return common.WrapError(common.KindValidation, err, fmt.Sprintf("step %d: cannot read %s", i+1, source))
+		}
+		steps[i].Content = string(content)
+	}
+	return nil
+}
+
+// group runs the steps from start to end, at most limit at the same time, in order.
+func (run *batchRun) group(start, end int, limit int) {
+	pool := concurrency.NewWorkerPool(int16(min(limit, end-start)))
+	pool.Start()
+	defer pool.Stop()
+	go func() {
+		for i := start; i < end; i++ {
+			// The result is kept by step, the pool only signals that the task finished
+			pool.AddTask(concurrency.Executor{
+				Execute:      func() error { run.step(i); return nil },
+				ErrorHandler: func(error) {},
+			})
+		}
+	}()
+	for i := start; i < end; i++ {
+		<-pool.Results
+	}
+}
+
+func (run *batchRun) step(i int) {
+	step := run.steps[i]
+	if run.stopped.Load() {
+		run.results[i] = stepResult{status: stepSkipped}
+		return
+	}
+	start := time.Now()
+	err := run.service.runStep(step)
+	slog.Debug("batch step finished", "step", i+1, "op", step.Op, common.LogRepo, step.Repo, common.LogDuration, time.Since(start), "error", err)
+	result := stepResult{status: stepOk, err: err}
+	if err != nil {
+		result.status = stepIgnored
+		if !step.ContinueOnError {
+			result.status = stepFailed
+			run.stopped.Store(true)
+		}
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.results[i] = result
+	run.done++
+	line := fmt.Sprintf("[%d/%d] %-7s #%d %s", run.done, len(run.steps), result.status, i+1, step.Title())
+	if err != nil {
+		line += ": " + err.Error()
+	}
+	run.service.consumerFunc(line)
+}
+
+func (service *BatchService) runStep(step common.BatchStep) error {
+	switch step.Op {
+	case common.BatchOpInvite:
+		return service.githubService.InviteCollaboratorToRepo(step.Repo, step.User)
+	case common.BatchOpRemove:
+		return service.githubService.RemoveCollaboratorFromRepo(step.Repo, step.User)
+	case common.BatchOpCreateRepo:
+		return service.githubService.CreateRepo(step.Repo, step.Description, step.Private)
+	case common.BatchOpPushFile:
+		return service.githubService.PushFile(step.Repo, step.Path, github2.FileChange{
+			Content: []byte(step.Content),
+			Message: step.Message,
+			Branch:  step.Branch,
+		})
+	case common.BatchOpLabel:
+		return service.githubService.SaveLabel(step.Repo, github2.Label{Name: step.Label, Color: step.Color, Description: step.Description})
+	}
+	return common.Errorf(common.KindValidation, "unknown op %q", step.Op)
+}
+
+// report prints how many steps ended each way and the ones that did not succeed, and
+// fails when a step without continue_on_error failed.
+func (run *batchRun) report() error {
+	counts := map[string]int{}
+	var failures []string
+	var skipped []int
+	var firstErr error
+	for i, result := range run.results {
+		counts[result.status]++
+		switch result.status {
+		case stepFailed, stepIgnored:
+			failures = append(failures, fmt.Sprintf("  #%d %s (%s): %s", i+1, run.steps[i].Title(), result.status, result.err))
+			if result.status == stepFailed && firstErr == nil {
+				firstErr = result.err
+			}
+		case stepSkipped:
+			skipped = append(skipped, i+1)
+		}
+	}
+	lines := []string{fmt.Sprintf("Batch finished: %d ok, %d failed, %d ignored, %d skipped",
+		counts[stepOk], counts[stepFailed], counts[stepIgnored], counts[stepSkipped])}
+	if len(failures) > 0 {
+		lines = append(lines, "Failed steps:")
+		lines = append(lines, failures...)
+	}
+	if len(skipped) > 0 {
+		lines = append(lines, "Skipped steps: "+stepRanges(skipped))
+	}
+	run.service.consumerFunc(strings.Join(lines, "\n"))
+	if firstErr != nil {
+		return common.Errorf(common.KindOf(firstErr), "%d of %d steps failed", counts[stepFailed], len(run.steps))
+	}
+	return nil
+}
+
+// stepRanges lists step numbers compacting consecutive ones, e.g. #3, #7-#200.
+func stepRanges(steps []int) string {
+	var ranges []string
+	for i := 0; i < len(steps); {
+		j := i
+		for j+1 < len(steps) && steps[j+1] == steps[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprintf("#%d", steps[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("#%d-#%d", steps[i], steps[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	github2 "github.com/ffumaneri/github-cli/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func fakeFiles(files map[string]string) FileReader {
+	return func(path string) ([]byte, error) {
+		content, ok := files[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+}
+
+func TestBatchService_Run(t *testing.T) {
+	mockWrapper := new(MockGithubWrapper)
+	githubService := NewGithubService("utn", mockWrapper, func(string) {})
+	readFile := fakeFiles(map[string]string{
+		"setup/ops.yaml": `
+vars:
+  tp: tp1
+steps:
+  - op: create-repo
+    repo: ${tp}
+  - op: push-file
+    repo: ${tp}
+    path: README.md
+    source: templates/README.md
+  - op: invite
+    repo: ${tp}
+    user: ghost
+    continue_on_error: true
+  - op: invite
+    repo: ${tp}
+    user: bob
+  - op: label
+    repo: ${tp}
+    label: delivered
+  - op: remove
+    repo: ${tp}
+    user: carol
+`,
+		"setup/templates/README.md": "# TP",
+	})
+	notFound := common.NewError(common.KindNotFound, "repository utn/tp2 or user ghost not found")
+	mockWrapper.On("CreateRepo", "utn", "tp2", "", false).Return(true, nil)
+	mockWrapper.On("PushFile", "utn", "tp2", "README.md", github2.FileChange{Content: []byte("# TP"), Message: "Update README.md"}).Return(true, nil)
+	mockWrapper.On("InviteCollaborator", "utn", "tp2", "ghost").Return(notFound)
+	mockWrapper.On("InviteCollaborator", "utn", "tp2", "bob").Return(common.NewError(common.KindAuth, "not allowed"))
+	var output []string
+	service := NewBatchService(githubService, readFile, func(data string) { output = append(output, data) })
+
+	err := service.Run("setup/ops.yaml", map[string]string{"tp": "tp2"}, 0)
+
+	assert.EqualError(t, err, "1 of 6 steps failed")
+	assert.Equal(t, common.KindAuth, common.KindOf(err))
+	assert.Equal(t, []string{
+		"[1/6] ok      #1 create repo tp2",
+		"[2/6] ok      #2 push README.md to tp2",
+		"[3/6] ignored #3 invite ghost to tp2: repository utn/tp2 or user ghost not found",
+		"[4/6] failed  #4 invite bob to tp2: not allowed",
+		"Batch finished: 2 ok, 1 failed, 1 ignored, 2 skipped\n" +
+			"Failed steps:\n" +
+			"  #3 invite ghost to tp2 (ignored): repository utn/tp2 or user ghost not found\n" +
+			"  #4 invite bob to tp2 (failed): not allowed\n" +
+			"Skipped steps: #5-#6",
+	}, output)
+	mockWrapper.AssertExpectations(t)
+}
+
+func TestBatchService_RunWait(t *testing.T) {
+	mockWrapper := new(MockGithubWrapper)
+	githubService := NewGithubService("utn", mockWrapper, func(string) {})
+	readFile := fakeFiles(map[string]string{"ops.yaml": `
+concurrency: 3
+steps:
+  - op: create-repo
+    repo: tp1
+  - op: create-repo
+    repo: tp2
+  - op: create-repo
+    repo: tp3
+  - op: invite
+    repo: tp1
+    user: alice
+    wait: true
+`})
+	var mu sync.Mutex
+	var calls []string
+	record := func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, args.String(1))
+	}
+	mockWrapper.On("CreateRepo", "utn", mock.Anything, "", false).Run(record).Return(true, nil)
+	mockWrapper.On("InviteCollaborator", "utn", "tp1", "alice").Run(record).Return(nil)
+	var output []string
+	service := NewBatchService(githubService, readFile, func(data string) { output = append(output, data) })
+
+	assert.NoError(t, service.Run("ops.yaml", nil, 0))
+
+	assert.ElementsMatch(t, []string{"tp1", "tp2", "tp3"}, calls[:3])
+	assert.Equal(t, "tp1", calls[3])
+	assert.Equal(t, "Batch finished: 4 ok, 0 failed, 0 ignored, 0 skipped", output[len(output)-1])
+}
+
+func TestBatchService_RunInvalid(t *testing.T) {
+	service := NewBatchService(NewGithubService("utn", new(MockGithubWrapper), func(string) {}), fakeFiles(map[string]string{
+		"ops.yaml": "steps:\n  - op: push-file\n    repo: tp1\n    path: a\n    source: missing.md\n",
+	}), func(string) {})
+
+	err := service.Run("missing.yaml", nil, 0)
+	assert.ErrorContains(t, err, "cannot read the batch file missing.yaml")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	err = service.Run("ops.yaml", nil, 0)
+	assert.ErrorContains(t, err, "step 1: cannot read missing.md")
+	assert.Equal(t, common.KindValidation, common.KindOf(err))
+
+	err = service.Run("ops.yaml", nil, 30)
+	assert.EqualError(t, err, "concurrency must be between 1 and 20, got 30")
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"fmt"
+)
+
+type ICacheService interface {
+	Clear() error
+}
+
+func NewCacheService(dir string, remove func(path string) error, consumer func(data string)) *CacheService {
+	return &CacheService{
+		dir:          dir,
+		remove:       remove,
+		consumerFunc: consumer,
+	}
+}
+
+type CacheService struct {
+	dir          string
+	remove       func(path string) error
+	consumerFunc func(data string)
+}
+
+// Clear removes the cached GitHub responses and completion values.
+func (service *CacheService) Clear() (err error) {
+	err = service.remove(service.dir)
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Cache cleared: %s", service.dir))
+	return
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheService_Clear(t *testing.T) {
+	var removed []string
+	var output []string
+	service := NewCacheService("/home/utn/.cache/github-cli", func(path string) error {
+		removed = append(removed, path)
+		return nil
+	}, func(data string) { output = append(output, data) })
+
+	assert.NoError(t, service.Clear())
+	assert.Equal(t, []string{"/home/utn/.cache/github-cli"}, removed)
+	assert.Equal(t, []string{"Cache cleared: /home/utn/.cache/github-cli"}, output)
+
+	service = NewCacheService("/home/utn/.cache/github-cli", func(string) error {
+		return errors.New("permission denied")
+	}, nil)
+	assert.EqualError(t, service.Clear(), "permission denied")
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type IConfigService interface {
+	ListProfiles() error
+	UseProfile(name string) error
+	// ActiveProfile returns the profile in use, including the one stored with UseProfile.
+	ActiveProfile() (string, error)
+	Init() error
+	Get(key string) error
+	Set(key, value string) error
+	Unset(key string) error
+	List() error
+	Validate() error
+}
+
+// ConfigProbes checks the services referenced by the configuration are usable.
+type ConfigProbes struct {
+	GithubUser func(config *common.Config) (string, error)
+	Llm        func(config *common.Config) error
+	Qdrant     func(config *common.Config) error
+}
+
+// Prompter asks the user for a value, returning defaultValue when the answer is empty.
+type Prompter func(label, defaultValue string, secret bool) (string, error)
+
+var ErrInvalidConfig = common.NewError(common.KindValidation, "configuration is not valid")
+
+const (
+	DefaultOllamaModel = "llama3.2"
+	DefaultQdrantUrl   = "http://localhost:6333"
+)
+
+func NewConfigService(configLoader common.ConfigLoader, configUpdater common.ConfigUpdater, prompter Prompter, probes ConfigProbes, consumer func(data string)) *ConfigService {
+	return &ConfigService{
+		configLoader:  configLoader,
+		configUpdater: configUpdater,
+		prompter:      prompter,
+		probes:        probes,
+		consumerFunc:  consumer,
+	}
+}
+
+type ConfigService struct {
+	configLoader  common.ConfigLoader
+	configUpdater common.ConfigUpdater
+	prompter      Prompter
+	probes        ConfigProbes
+	consumerFunc  func(data string)
+}
+
+// ListProfiles prints the defined profiles, the active one is marked with an asterisk.
+func (service *ConfigService) ListProfiles() (err error) {
+	files, err := common.LoadConfigFiles(service.configLoader)
+	if err != nil {
+		return
+	}
+	active := common.ActiveProfile(files.Profile)
+	for _, name := range files.ProfileNames() {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		service.consumerFunc(fmt.Sprintf("%s %s", marker, name))
+	}
+	return
+}
+
+func (service *ConfigService) ActiveProfile() (string, error) {
+	files, err := common.LoadConfigFiles(service.configLoader)
+	if err != nil {
+		return "", err
+	}
+	return common.ActiveProfile(files.Profile), nil
+}
+
+func (service *ConfigService) UseProfile(name string) (err error) {
+	err = common.UseProfile(service.configLoader, service.configUpdater, name)
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Switched to profile %s", name))
+	return
+}
+
+// Init asks for every required value, using the current ones as defaults, and writes them.
+func (service *ConfigService) Init() (err error) {
+	current, err := common.ResolveConfig(service.configLoader)
+	if err != nil {
+		return
+	}
+	path, err := common.WritableConfigFile()
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("Writing configuration to %s", path))
+
+	values := map[string]string{}
+	type condition struct{ key, value string }
+	steps := []struct {
+		key, label, defaultValue string
+		secret                   bool
+		// when, if set, asks the step only when the answer to its key was its value, e.g.
+		// the model of the chosen provider
+		when condition
+	}{
+		{key: "owner", label: "GitHub user or organization", defaultValue: current.Owner},
+		{key: "token", label: "GitHub token (leave empty to use `auth login`)", secret: true},
+		{key: "llm_provider", label: "LLM provider (ollama, openai or anthropic)", defaultValue: current.Provider()},
+		{key: "ollama_model", label: "Ollama model", defaultValue: withDefault(current.Ollama_Model, DefaultOllamaModel),
+			when: condition{"llm_provider", common.LlmProviderOllama}},
+		{key: "openai_url", label: "OpenAI compatible URL", defaultValue: current.Openai_Url,
+			when: condition{"llm_provider", common.LlmProviderOpenAI}},
+		{key: "openai_model", label: "OpenAI compatible model", defaultValue: current.Openai_Model,
+			when: condition{"llm_provider", common.LlmProviderOpenAI}},
+		{key: "openai_api_key", label: "OpenAI API key (leave empty to use $OPENAI_API_KEY)", secret: true,
+			when: condition{"llm_provider", common.LlmProviderOpenAI}},
+		{key: "anthropic_model", label: "Anthropic model", defaultValue: current.Anthropic_Model,
+			when: condition{"llm_provider", common.LlmProviderAnthropic}},
+		{key: "anthropic_api_key", label: "Anthropic API key (leave empty to use $ANTHROPIC_API_KEY)", secret: true,
+			when: condition{"llm_provider", common.LlmProviderAnthropic}},
+		{key: "vector_store", label: "Vector store (local or qdrant)", defaultValue: current.Kind()},
+		{key: "qdrant_url", label: "Qdrant URL", defaultValue: withDefault(current.Qdrant_Url, DefaultQdrantUrl),
+			when: condition{"vector_store", common.VectorStoreQdrant}},
+	}
+	for _, step := range steps {
+		if step.when.key != "" && values[step.when.key] != step.when.value {
+			continue
+		}
+		value, err := service.prompter(step.label, step.defaultValue, step.secret)
+		if err != nil {
+			return err
+		}
+		if step.key == "llm_provider" {
+			value = strings.ToLower(value)
+		}
+		if value != "" {
+			values[step.key] = value
+		}
+	}
+	if values["owner"] == "" {
+		return common.NewError(common.KindValidation, "owner is required")
+	}
+	switch values["llm_provider"] {
+	case common.LlmProviderOllama, common.LlmProviderOpenAI, common.LlmProviderAnthropic:
+	default:
+		return common.Errorf(common.KindValidation, "invalid llm_provider %q, expected %s, %s or %s",
+			values["llm_provider"], common.LlmProviderOllama, common.LlmProviderOpenAI, common.LlmProviderAnthropic)
+	}
+	if err = checkUrl("openai_url", values["openai_url"]); err != nil {
+		return
+	}
+	if err = checkUrl("qdrant_url", values["qdrant_url"]); err != nil {
+		return
+	}
+
+	err = common.SetConfigValues(service.configUpdater, values)
+	if err != nil {
+		return
+	}
+	service.consumerFunc("Configuration saved, run `config validate` to check it")
+	return
+}
+
+// Get prints the resolved value of key, secrets are redacted.
+func (service *ConfigService) Get(key string) (err error) {
+	config, err := common.ResolveConfig(service.configLoader)
+	if err != nil {
+		return
+	}
+	value, err := config.Get(key)
+	if err != nil {
+		return
+	}
+	service.consumerFunc(value)
+	return
+}
+
+func (service *ConfigService) Set(key, value string) (err error) {
+	err = common.SetConfigValue(service.configUpdater, key, value)
+	if err != nil {
+		return
+	}
+	if common.IsSecretKey(key) {
+		value = common.Redact(value)
+	}
+	service.consumerFunc(fmt.Sprintf("%s set to %s", key, value))
+	return
+}
+
+func (service *ConfigService) Unset(key string) (err error) {
+	err = common.UnsetConfigValue(service.configUpdater, key)
+	if err != nil {
+		return
+	}
+	service.consumerFunc(fmt.Sprintf("%s unset", key))
+	return
+}
+
+// List prints every resolved key, secrets are redacted.
+func (service *ConfigService) List() (err error) {
+	config, err := common.ResolveConfig(service.configLoader)
+	if err != nil {
+		return
+	}
+	for _, key := range common.ConfigKeys() {
+		value, err := config.Get(key)
+		if err != nil {
+			return err
+		}
+		service.consumerFunc(fmt.Sprintf("%s=%s", key, value))
+	}
+	return
+}
+
+// Validate checks every value and the services they point to, printing how to fix each problem.
+func (service *ConfigService) Validate() (err error) {
+	config, err := common.ResolveConfig(service.configLoader)
+	if err != nil {
+		return
+	}
+	valid := true
+	report := func(check string, err error, hint string) {
+		if err == nil {
+			service.consumerFunc(fmt.Sprintf("ok    %s", check))
+			return
+		}
+		valid = false
+		service.consumerFunc(fmt.Sprintf("FAIL  %s: %s", check, err))
+		if hint != "" {
+			service.consumerFunc(fmt.Sprintf("      %s", hint))
+		}
+	}
+
+	fieldErrors := config.FieldErrors()
+	if config.Qdrant_Url != "" {
+		if err := checkUrl("qdrant_url", config.Qdrant_Url); err != nil {
+			fieldErrors = append(fieldErrors, err)
+		}
+	}
+	if config.UsesGithubApp() && config.App_Private_Key != "" {
+		if _, err := os.Stat(config.App_Private_Key); err != nil {
+			fieldErrors = append(fieldErrors, fmt.Errorf("app_private_key is not readable: %w", err))
+		}
+	}
+	for _, fieldError := range fieldErrors {
+		report("configuration", fieldError, "set it with `config set <key> <value>` or a GITHUB_CLI_<KEY> environment variable")
+	}
+	if len(fieldErrors) == 0 {
+		report("configuration", nil, "")
+	}
+
+	if config.HasTokenSource() || config.UsesGithubApp() {
+		login, err := service.probes.GithubUser(config)
+		check := "GitHub authentication"
+		if err == nil {
+			check = fmt.Sprintf("GitHub authentication as %s", login)
+		}
+		report(check, err, "check the token, token_env or token_command, or run `auth login` to get a new one")
+	}
+	if model := config.Model(); model != "" {
+		switch config.Provider() {
+		case common.LlmProviderOllama:
+			report(fmt.Sprintf("Ollama model %s", model), service.probes.Llm(config),
+				fmt.Sprintf("start Ollama with `ollama serve` and pull the model with `ollama pull %s`", model))
+		case common.LlmProviderOpenAI:
+			report(fmt.Sprintf("OpenAI compatible model %s at %s", model, config.Openai_Url), service.probes.Llm(config),
+				"start the server or fix openai_url, openai_model and openai_api_key")
+		case common.LlmProviderAnthropic:
+			report(fmt.Sprintf("Anthropic model %s", model), service.probes.Llm(config),
+				"check anthropic_url, anthropic_model and anthropic_api_key or $ANTHROPIC_API_KEY")
+		}
+	}
+	if config.Kind() == common.VectorStoreQdrant && config.Qdrant_Url != "" {
+		report(fmt.Sprintf("Qdrant at %s", config.Qdrant_Url), service.probes.Qdrant(config),
+			"start Qdrant, e.g. `docker run -p 6333:6333 qdrant/qdrant`, or fix qdrant_url")
+	}
+
+	if !valid {
+		return ErrInvalidConfig
+	}
+	return
+}
+
+func checkUrl(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return common.Errorf(common.KindValidation, "invalid configuration value %s: %q is not an absolute URL", key, value)
+	}
+	return nil
+}
+
+func withDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockConfigLoader(config common.Config) common.ConfigLoader {
+	return mockProfilesLoader(common.FileConfig{Config: config})
+}
+
+func mockProfilesLoader(profiles common.FileConfig) common.ConfigLoader {
+	return func(path string, config interface{}) error {
+		cfg, ok := config.(*common.FileConfig)
+		if !ok {
+			return errors.New("invalid config type")
+		}
+		*cfg = profiles
+		return nil
+	}
+}
+
+func TestConfigService_ListProfiles(t *testing.T) {
+	t.Setenv(common.ProfileEnvVar, "")
+	loader := mockProfilesLoader(common.FileConfig{
+		Profile: "utn",
+		Profiles: map[string]common.Config{
+			"work":     {},
+			"utn":      {},
+			"personal": {},
+		},
+	})
+	var output []string
+	service := NewConfigService(loader, nil, nil, ConfigProbes{}, func(data string) { output = append(output, data) })
+
+	err := service.ListProfiles()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"  personal", "* utn", "  work"}, output)
+
+	profile, err := service.ActiveProfile()
+	assert.NoError(t, err)
+	assert.Equal(t, "utn", profile)
+	t.Setenv(common.ProfileEnvVar, "work")
+	profile, _ = service.ActiveProfile()
+	assert.Equal(t, "work", profile)
+}
+
+func TestConfigService_UseProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tests := []struct {
+		name           string
+		profile        string
+		expectedOutput []string
+		expectedError  bool
+	}{
+		{"Success", "work", []string{"Switched to profile work"}, false},
+		{"Unknown profile", "personal", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := mockProfilesLoader(common.FileConfig{Profiles: map[string]common.Config{"work": {}}})
+			stored := map[string]any{}
+			updater := func(path string, update func(values map[string]any) error) error {
+				return update(stored)
+			}
+			var output []string
+			service := NewConfigService(loader, updater, nil, ConfigProbes{}, func(data string) { output = append(output, data) })
+
+			err := service.UseProfile(tt.profile)
+
+			assert.Equal(t, tt.expectedError, err != nil)
+			assert.Equal(t, tt.expectedOutput, output)
+			if !tt.expectedError {
+				assert.Equal(t, tt.profile, stored["profile"])
+			}
+		})
+	}
+}
+
+func TestConfigService_Init(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	answers := map[string]string{
+		"GitHub user or organization":    "acme",
+		"Ollama model":                   "",
+		"Vector store (local or qdrant)": "qdrant",
+		"Qdrant URL":                     "http://qdrant:6333",
+	}
+	prompter := func(label, defaultValue string, secret bool) (string, error) {
+		if answer, ok := answers[label]; ok && answer != "" {
+			return answer, nil
+		}
+		return defaultValue, nil
+	}
+	stored := map[string]any{}
+	updater := func(path string, update func(values map[string]any) error) error {
+		return update(stored)
+	}
+	var output []string
+	service := NewConfigService(mockConfigLoader(common.Config{}), updater, prompter, ConfigProbes{}, func(data string) { output = append(output, data) })
+
+	err := service.Init()
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"owner": "acme", "llm_provider": "ollama", "ollama_model": DefaultOllamaModel, "vector_store": "qdrant", "qdrant_url": "http://qdrant:6333"}, stored)
+
+	answers["Qdrant URL"] = "not a url"
+	assert.Error(t, service.Init())
+
+	// The local store needs no URL
+	answers["Vector store (local or qdrant)"] = ""
+	stored = map[string]any{}
+	assert.NoError(t, service.Init())
+	assert.Equal(t, map[string]any{"owner": "acme", "llm_provider": "ollama", "ollama_model": DefaultOllamaModel, "vector_store": "local"}, stored)
+
+	// Only the keys of the chosen provider are asked
+	answers["LLM provider (ollama, openai or anthropic)"] = "OpenAI"
+	answers["OpenAI compatible URL"] = "http://gpu-box:8080/v1"
+	answers["OpenAI compatible model"] = "qwen2.5-coder"
+	stored = map[string]any{}
+	assert.NoError(t, service.Init())
+	assert.Equal(t, map[string]any{"owner": "acme", "llm_provider": "openai", "openai_url": "http://gpu-box:8080/v1",
+		"openai_model": "qwen2.5-coder", "vector_store": "local"}, stored)
+
+	answers["LLM provider (ollama, openai or anthropic)"] = "anthropic"
+	answers["Anthropic model"] = "claude-sonnet-4-5"
+	stored = map[string]any{}
+	assert.NoError(t, service.Init())
+	assert.Equal(t, map[string]any{"owner": "acme", "llm_provider": "anthropic", "anthropic_model": "claude-sonnet-4-5", "vector_store": "local"}, stored)
+
+	answers["LLM provider (ollama, openai or anthropic)"] = "gemini"
+	err = service.Init()
+	assert.ErrorContains(t, err, `invalid llm_provider "gemini"`)
+	assert.Equal(t, common.KindValidation, common.KindOf(err))
+}
+
+func TestConfigService_GetAndList(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var output []string
+	loader := mockConfigLoader(common.Config{GithubConfig: common.GithubConfig{Owner: "acme", Token: "ghp_1234567890"}})
+	service := NewConfigService(loader, nil, nil, ConfigProbes{}, func(data string) { output = append(output, data) })
+
+	assert.NoError(t, service.Get("owner"))
+	assert.NoError(t, service.Get("token"))
+	assert.Equal(t, []string{"acme", "ghp_********"}, output)
+
+	output = nil
+	assert.NoError(t, service.List())
+	assert.Contains(t, output, "token=ghp_********")
+	assert.Contains(t, output, "owner=acme")
+	assert.NotContains(t, output, "token=ghp_1234567890")
+
+	assert.Error(t, service.Get("unknown"))
+}
+
+func TestConfigService_SetAndUnset(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stored := map[string]any{}
+	updater := func(path string, update func(values map[string]any) error) error {
+		return update(stored)
+	}
+	var output []string
+	service := NewConfigService(nil, updater, nil, ConfigProbes{}, func(data string) { output = append(output, data) })
+
+	assert.NoError(t, service.Set("token", "ghp_1234567890"))
+	assert.NoError(t, service.Unset("token"))
+	assert.Equal(t, []string{"token set to ghp_********", "token unset"}, output)
+	assert.Empty(t, stored)
+}
+
+func TestConfigService_Validate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tests := []struct {
+		name          string
+		config        common.Config
+		ollamaError   error
+		expectedLines []string
+		expectedError error
+	}{
+		{
+			name: "Valid",
+			config: common.Config{
+				GithubConfig:      common.GithubConfig{Owner: "acme", Token: "token"},
+				LlmConfig:         common.LlmConfig{Ollama_Model: "llama3.2"},
+				VectorStoreConfig: common.VectorStoreConfig{Qdrant_Url: "http://localhost:6333"},
+			},
+			expectedLines: []string{"ok    configuration", "ok    GitHub authentication as user1", "ok    Ollama model llama3.2", "ok    Qdrant at http://localhost:6333"},
+		},
+		{
+			name: "OpenAI compatible",
+			config: common.Config{
+				GithubConfig:      common.GithubConfig{Owner: "acme", Token: "token"},
+				LlmConfig:         common.LlmConfig{Llm_Provider: "openai", Openai_Url: "http://localhost:8080/v1", Openai_Model: "qwen2.5-coder"},
+				VectorStoreConfig: common.VectorStoreConfig{Qdrant_Url: "http://localhost:6333"},
+			},
+			expectedLines: []string{"ok    configuration", "ok    OpenAI compatible model qwen2.5-coder at http://localhost:8080/v1"},
+		},
+		{
+			name: "Anthropic",
+			config: common.Config{
+				GithubConfig: common.GithubConfig{Owner: "acme", Token: "token"},
+				LlmConfig:    common.LlmConfig{Llm_Provider: "anthropic", Anthropic_Model: "claude-sonnet-4-5"},
+			},
+			ollamaError: errors.New("https://api.anthropic.com/v1 rejected the API key: 401 Unauthorized"),
+			expectedLines: []string{
+				"FAIL  Anthropic model claude-sonnet-4-5: https://api.anthropic.com/v1 rejected the API key: 401 Unauthorized",
+				"      check anthropic_url, anthropic_model and anthropic_api_key or $ANTHROPIC_API_KEY",
+			},
+			expectedError: ErrInvalidConfig,
+		},
+		{
+			name: "Problems",
+			config: common.Config{
+				GithubConfig:      common.GithubConfig{Token: "token"},
+				LlmConfig:         common.LlmConfig{Ollama_Model: "llama3.2"},
+				VectorStoreConfig: common.VectorStoreConfig{Qdrant_Url: "localhost"},
+			},
+			ollamaError: errors.New("cannot reach Ollama"),
+			expectedLines: []string{
+				"FAIL  configuration: missing required configuration value owner for GitHub commands, searched: $GITHUB_CLI_OWNER",
+				"FAIL  configuration: invalid configuration value qdrant_url: \"localhost\" is not an absolute URL",
+				"FAIL  Ollama model llama3.2: cannot reach Ollama",
+				"      start Ollama with `ollama serve` and pull the model with `ollama pull llama3.2`",
+			},
+			expectedError: ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := ConfigProbes{
+				GithubUser: func(config *common.Config) (string, error) { return "user1", nil },
+				Llm:        func(config *common.Config) error { return tt.ollamaError },
+				Qdrant:     func(config *common.Config) error { return nil },
+			}
+			var output []string
+			service := NewConfigService(mockConfigLoader(tt.config), nil, nil, probes, func(data string) { output = append(output, data) })
+
+			err := service.Validate()
+
+			assert.Equal(t, tt.expectedError, err)
+			for _, line := range tt.expectedLines {
+				assert.Contains(t, strings.Join(output, "\n"), line)
+			}
+		})
+	}
+}
@@ -2,13 +2,25 @@ package services
 
 import (
 	"fmt"
+	"github.com/ffumaneri/github-cli/common"
 	github2 "github.com/ffumaneri/github-cli/github"
+	"strings"
 )
 
 type IGithubService interface {
 	ListRepos() error
 	ListCollaboratorsByRepo(repo string) error
 	InviteCollaboratorToRepo(repo, user string) error
+	RemoveCollaboratorFromRepo(repo, user string) error
+	InvitationsByRepo(repo string) ([]github2.Invitation, error)
+	CancelInvitationToRepo(repo string, invitation github2.Invitation) error
+	RepoNames() ([]string, error)
+	CollaboratorNames(repo string) ([]string, error)
+	// CreateRepo, PushFile and SaveLabel do nothing when the change is already there.
+	// With --offline every change fails with common.ErrOffline before reading anything.
+	CreateRepo(repo, description string, private bool) error
+	PushFile(repo, path string, file github2.FileChange) error
+	SaveLabel(repo string, label github2.Label) error
 }
 
 func NewGithubService(owner string, githubWrapper github2.IGithubWrapper, consumer func(data string)) *GithubService {
@@ -48,10 +60,122 @@ func (service *GithubService) ListCollaboratorsByRepo(repo string) (err error) {
 }
 
 func (service *GithubService) InviteCollaboratorToRepo(repo, user string) (err error) {
+	if common.Offline {
+		return common.ErrOffline
+	}
 	err = service.githubWrapper.InviteCollaborator(service.owner, repo, user)
 	if err != nil {
 		return
 	}
-	service.consumerFunc(fmt.Sprintf("Collaborator %s invited to %s\n", user, repo))
+	service.report(fmt.Sprintf("Collaborator %s invited to %s\n", user, repo), fmt.Sprintf("Would invite %s to %s", user, repo))
 	return
 }
+
+// RepoNames returns the names of the owner repositories, without the owner like --repo takes them.
+func (service *GithubService) RepoNames() ([]string, error) {
+	repos, err := service.githubWrapper.GetRepos(service.owner)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(repos))
+	for i, repo := range repos {
+		_, name, found := strings.Cut(repo, "/")
+		if !found {
+			name = repo
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+// CollaboratorNames returns the logins of the collaborators of repo.
+func (service *GithubService) CollaboratorNames(repo string) ([]string, error) {
+	return service.githubWrapper.GetCollaboratorsByRepo(service.owner, repo)
+}
+
+func (service *GithubService) RemoveCollaboratorFromRepo(repo, user string) (err error) {
+	if common.Offline {
+		return common.ErrOffline
+	}
+	err = service.githubWrapper.RemoveCollaborator(service.owner, repo, user)
+	if err != nil {
+		return
+	}
+	service.report(fmt.Sprintf("Collaborator %s removed from %s", user, repo), fmt.Sprintf("Would remove collaborator %s from %s", user, repo))
+	return
+}
+
+// InvitationsByRepo returns the invitations to repo that are still pending.
+func (service *GithubService) InvitationsByRepo(repo string) ([]github2.Invitation, error) {
+	return service.githubWrapper.GetInvitationsByRepo(service.owner, repo)
+}
+
+func (service *GithubService) CancelInvitationToRepo(repo string, invitation github2.Invitation) (err error) {
+	if common.Offline {
+		return common.ErrOffline
+	}
+	err = service.githubWrapper.CancelInvitation(service.owner, repo, invitation.Id)
+	if err != nil {
+		return
+	}
+	service.report(fmt.Sprintf("Invitation of %s to %s cancelled", invitation.Login, repo), fmt.Sprintf("Would cancel the invitation of %s to %s", invitation.Login, repo))
+	return
+}
+
+func (service *GithubService) CreateRepo(repo, description string, private bool) (err error) {
+	if common.Offline {
+		return common.ErrOffline
+	}
+	created, err := service.githubWrapper.CreateRepo(service.owner, repo, description, private)
+	if err != nil {
+		return
+	}
+	if created {
+		service.report(fmt.Sprintf("Repository %s created", repo), fmt.Sprintf("Would create repository %s", repo))
+	} else {
+		service.consumerFunc(fmt.Sprintf("Repository %s already exists", repo))
+	}
+	return
+}
+
+func (service *GithubService) PushFile(repo, path string, file github2.FileChange) (err error) {
+	if common.Offline {
+		return common.ErrOffline
+	}
+	changed, err := service.githubWrapper.PushFile(service.owner, repo, path, file)
+	if err != nil {
+		return
+	}
+	if changed {
+		service.report(fmt.Sprintf("File %s pushed to %s", path, repo), fmt.Sprintf("Would push file %s to %s", path, repo))
+	} else {
+		service.consumerFunc(fmt.Sprintf("File %s of %s is up to date", path, repo))
+	}
+	return
+}
+
+func (service *GithubService) SaveLabel(repo string, label github2.Label) (err error) {
+	if common.Offline {
+		return common.ErrOffline
+	}
+	changed, err := service.githubWrapper.SaveLabel(service.owner, repo, label)
+	if err != nil {
+		return
+	}
+	if changed {
+		service.report(fmt.Sprintf("Label %s saved in %s", label.Name, repo), fmt.Sprintf("Would save label %s in %s", label.Name, repo))
+	} else {
+		service.consumerFunc(fmt.Sprintf("Label %s of %s is up to date", label.Name, repo))
+	}
+	return
+}
+
+// report sends the message of a change that was made, or with --dry-run the message of the
+// change that would have been made, since the request was only logged.
+func (service *GithubService) report(done, planned string) {
+	if common.DryRun {
+		service.consumerFunc(planned)
+		return
+	}
+	service.consumerFunc(done)
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ffumaneri/github-cli/common"
+	github2 "github.com/ffumaneri/github-cli/github"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,6 +29,41 @@ func (m *MockGithubWrapper) InviteCollaborator(owner, repo, user string) error {
 	return args.Error(0)
 }
 
+func (m *MockGithubWrapper) GetInvitationsByRepo(owner, repo string) ([]github2.Invitation, error) {
+	args := m.Called(owner, repo)
+	return args.Get(0).([]github2.Invitation), args.Error(1)
+}
+
+func (m *MockGithubWrapper) CancelInvitation(owner, repo string, invitationId int64) error {
+	args := m.Called(owner, repo, invitationId)
+	return args.Error(0)
+}
+
+func (m *MockGithubWrapper) RemoveCollaborator(owner, repo, user string) error {
+	args := m.Called(owner, repo, user)
+	return args.Error(0)
+}
+
+func (m *MockGithubWrapper) GetAuthenticatedUser() (string, []string, error) {
+	args := m.Called()
+	return args.String(0), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockGithubWrapper) CreateRepo(owner, repo, description string, private bool) (bool, error) {
+	args := m.Called(owner, repo, description, private)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGithubWrapper) PushFile(owner, repo, path string, file github2.FileChange) (bool, error) {
+	args := m.Called(owner, repo, path, file)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGithubWrapper) SaveLabel(owner, repo string, label github2.Label) (bool, error) {
+	args := m.Called(owner, repo, label)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestGithubService_ListRepos(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -122,3 +159,117 @@ func TestGithubService_InviteCollaboratorToRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestGithubService_Names(t *testing.T) {
+	mockWrapper := new(MockGithubWrapper)
+	service := NewGithubService("owner", mockWrapper, nil)
+	mockWrapper.On("GetRepos", "owner").Return([]string{"owner/repo1", "owner/repo2"}, nil)
+	mockWrapper.On("GetCollaboratorsByRepo", "owner", "repo1").Return([]string(nil), errors.New("API error"))
+
+	repos, err := service.RepoNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"repo1", "repo2"}, repos)
+
+	_, err = service.CollaboratorNames("repo1")
+	assert.EqualError(t, err, "API error")
+	mockWrapper.AssertExpectations(t)
+}
+
+func TestGithubService_ManageCollaborators(t *testing.T) {
+	mockWrapper := new(MockGithubWrapper)
+	var output []string
+	service := NewGithubService("owner", mockWrapper, func(data string) { output = append(output, data) })
+	invitation := github2.Invitation{Id: 7, Login: "user2"}
+	mockWrapper.On("RemoveCollaborator", "owner", "repo1", "user1").Return(nil)
+	mockWrapper.On("GetInvitationsByRepo", "owner", "repo1").Return([]github2.Invitation{invitation}, nil)
+	mockWrapper.On("CancelInvitation", "owner", "repo1", int64(7)).Return(errors.New("API error"))
+
+	assert.NoError(t, service.RemoveCollaboratorFromRepo("repo1", "user1"))
+	invitations, err := service.InvitationsByRepo("repo1")
+	assert.NoError(t, err)
+	assert.Equal(t, []github2.Invitation{invitation}, invitations)
+	assert.EqualError(t, service.CancelInvitationToRepo("repo1", invitation), "API error")
+
+	assert.Equal(t, []string{"Collaborator user1 removed from repo1"}, output)
+	mockWrapper.AssertExpectations(t)
+}
+
+func TestGithubService_Setup(t *testing.T) {
+	mockWrapper := new(MockGithubWrapper)
+	var output []string
+	service := NewGithubService("owner", mockWrapper, func(data string) { output = append(output, data) })
+	file := github2.FileChange{Content: []byte("# TP1"), Message: "Add README"}
+	label := github2.Label{Name: "delivered", Color: "0e8a16"}
+	mockWrapper.On("CreateRepo", "owner", "tp1", "TP 1", true).Return(true, nil)
+	mockWrapper.On("CreateRepo", "owner", "tp2", "", false).Return(false, nil)
+	mockWrapper.On("PushFile", "owner", "tp1", "README.md", file).Return(false, nil)
+	mockWrapper.On("SaveLabel", "owner", "tp1", label).Return(false, errors.New("API error"))
+
+	assert.NoError(t, service.CreateRepo("tp1", "TP 1", true))
+	assert.NoError(t, service.CreateRepo("tp2", "", false))
+	assert.NoError(t, service.PushFile("tp1", "README.md", file))
+	assert.EqualError(t, service.SaveLabel("tp1", label), "API error")
+
+	assert.Equal(t, []string{"Repository tp1 created", "Repository tp2 already exists", "File README.md of tp1 is up to date"}, output)
+	mockWrapper.AssertExpectations(t)
+}
+
+func TestGithubService_DryRun(t *testing.T) {
+	common.DryRun = true
+	defer func() { common.DryRun = false }()
+	mockWrapper := new(MockGithubWrapper)
+	var output []string
+	service := NewGithubService("owner", mockWrapper, func(data string) { output = append(output, data) })
+	file := github2.FileChange{Content: []byte("# TP1"), Message: "Add README"}
+	label := github2.Label{Name: "delivered", Color: "0e8a16"}
+	invitation := github2.Invitation{Id: 7, Login: "user2"}
+	mockWrapper.On("InviteCollaborator", "owner", "tp1", "user1").Return(nil)
+	mockWrapper.On("RemoveCollaborator", "owner", "tp1", "user1").Return(nil)
+	mockWrapper.On("CancelInvitation", "owner", "tp1", int64(7)).Return(nil)
+	mockWrapper.On("CreateRepo", "owner", "tp1", "TP 1", true).Return(true, nil)
+	mockWrapper.On("CreateRepo", "owner", "tp2", "", false).Return(false, nil)
+	mockWrapper.On("PushFile", "owner", "tp1", "README.md", file).Return(true, nil)
+	mockWrapper.On("SaveLabel", "owner", "tp1", label).Return(true, nil)
+
+	assert.NoError(t, service.InviteCollaboratorToRepo("tp1", "user1"))
+	assert.NoError(t, service.RemoveCollaboratorFromRepo("tp1", "user1"))
+	assert.NoError(t, service.CancelInvitationToRepo("tp1", invitation))
+	assert.NoError(t, service.CreateRepo("tp1", "TP 1", true))
+	assert.NoError(t, service.CreateRepo("tp2", "", false))
+	assert.NoError(t, service.PushFile("tp1", "README.md", file))
+	assert.NoError(t, service.SaveLabel("tp1", label))
+
+	assert.Equal(t, []string{
+		"Would invite user1 to tp1",
+		"Would remove collaborator user1 from tp1",
+		"Would cancel the invitation of user2 to tp1",
+		"Would create repository tp1",
+		"Repository tp2 already exists",
+		"Would push file README.md to tp1",
+		"Would save label delivered in tp1",
+	}, output)
+	mockWrapper.AssertExpectations(t)
+}
+
+func TestGithubService_Offline(t *testing.T) {
+	common.Offline = true
+	defer func() { common.Offline = false }()
+	mockWrapper := new(MockGithubWrapper)
+	var output []string
+	service := NewGithubService("owner", mockWrapper, func(data string) { output = append(output, data) })
+
+	// No request is made, not even the reads checking whether the change is needed
+	for _, err := range []error{
+		service.InviteCollaboratorToRepo("tp1", "user1"),
+		service.RemoveCollaboratorFromRepo("tp1", "user1"),
+		service.CancelInvitationToRepo("tp1", github2.Invitation{Id: 7}),
+		service.CreateRepo("tp1", "TP 1", true),
+		service.PushFile("tp1", "README.md", github2.FileChange{Content: []byte("# TP1")}),
+		service.SaveLabel("tp1", github2.Label{Name: "delivered"}),
+	} {
+		assert.ErrorIs(t, err, common.ErrOffline)
+		assert.Equal(t, common.ExitValidation, common.ExitCode(err))
+	}
+	assert.Empty(t, output)
+	mockWrapper.AssertExpectations(t)
+}
@@ -2,13 +2,19 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"github.com/ffumaneri/github-cli/lang_chain"
+	"sort"
+	"strings"
 )
 
 type ILangChainService interface {
 	AskLlm(prompt string) error
-	LoadSourceCode(name, directory string) error
-	AskLlmWithContext(contextName, prompt string) (err error)
+	LoadSourceCode(name, directory string, options lang_chain.LoadOptions) error
+	// AskLlmWithContext answers prompt with the context, using only the chunks having the
+	// metadata values of filters, e.g. language=go.
+	AskLlmWithContext(contextName, prompt string, filters map[string]string) (err error)
+	ContextNames() ([]string, error)
 }
 
 func NewLangChainService(llmWrapper lang_chain.ILangChainWrapper, chunkConsumer func(chunk []byte)) *LangChainService {
@@ -27,11 +33,38 @@ func (service *LangChainService) AskLlm(prompt string) (err error) {
 	})
 	return
 }
-func (service *LangChainService) AskLlmWithContext(contextName, prompt string) (err error) {
-	err = service.llmWrapper.AskWithContext(contextName, prompt)
+func (service *LangChainService) AskLlmWithContext(contextName, prompt string, filters map[string]string) (err error) {
+	err = service.llmWrapper.AskWithContext(contextName, prompt, filters)
 	return
 }
-func (service *LangChainService) LoadSourceCode(name, directory string) (err error) {
-	err = service.llmWrapper.LoadSourceCode(name, directory)
+func (service *LangChainService) LoadSourceCode(name, directory string, options lang_chain.LoadOptions) (err error) {
+	stats, err := service.llmWrapper.LoadSourceCode(name, directory, options)
+	// What was done before an error is reported along with it
+	if err != nil && stats.Added+stats.Changed+stats.Removed+stats.Unchanged+stats.Failed+len(stats.Skipped) == 0 {
+		return
+	}
+	summary := fmt.Sprintf("Context %s loaded: %d added, %d changed, %d removed, %d unchanged",
+		name, stats.Added, stats.Changed, stats.Removed, stats.Unchanged)
+	if err != nil {
+		summary = fmt.Sprintf("Context %s partly loaded: %d added, %d changed, %d removed, %d unchanged, %d failed",
+			name, stats.Added, stats.Changed, stats.Removed, stats.Unchanged, stats.Failed)
+	}
+	service.ChunkConsumer([]byte(summary + "\n"))
+	if len(stats.Skipped) > 0 {
+		reasons := make([]string, 0, len(stats.Skipped))
+		for reason := range stats.Skipped {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		for i, reason := range reasons {
+			reasons[i] = fmt.Sprintf("%d %s", stats.Skipped[reason], reason)
+		}
+		service.ChunkConsumer([]byte("Skipped: " + strings.Join(reasons, ", ") + "\n"))
+	}
 	return
 }
+
+// ContextNames returns the names of the contexts created with LoadSourceCode.
+func (service *LangChainService) ContextNames() ([]string, error) {
+	return service.llmWrapper.ContextNames()
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"github.com/ffumaneri/github-cli/lang_chain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
@@ -21,15 +22,20 @@ func (m *MockLangChainWrapper) AskLlm(prompt string, streamingFunc func(ctx cont
 	return args.Error(0)
 }
 
-func (m *MockLangChainWrapper) LoadSourceCode(name, directory string) error {
-	args := m.Called(name, directory)
-	return args.Error(0)
+func (m *MockLangChainWrapper) LoadSourceCode(name, directory string, options lang_chain.LoadOptions) (lang_chain.LoadStats, error) {
+	args := m.Called(name, directory, options)
+	return args.Get(0).(lang_chain.LoadStats), args.Error(1)
 }
 
-func (m *MockLangChainWrapper) AskWithContext(contextName, searchQuery string) error {
-	args := m.Called(contextName, searchQuery)
+func (m *MockLangChainWrapper) AskWithContext(contextName, searchQuery string, filters map[string]string) error {
+	args := m.Called(contextName, searchQuery, filters)
 	return args.Error(0)
 }
+
+func (m *MockLangChainWrapper) ContextNames() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
 func TestLangChainService_AskLlm(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -87,6 +93,7 @@ func TestLangChainService_AskLlmWithContext(t *testing.T) {
 		name          string
 		contextName   string
 		prompt        string
+		filters       map[string]string
 		mockError     error
 		expectedError error
 	}{
@@ -95,6 +102,12 @@ func TestLangChainService_AskLlmWithContext(t *testing.T) {
 			contextName: "validContext",
 			prompt:      "What is the purpose of AI?",
 		},
+		{
+			name:        "success_with_filters",
+			contextName: "validContext",
+			prompt:      "How are users invited?",
+			filters:     map[string]string{"language": "go", "path": "cmd/calls.go"},
+		},
 		{
 			name:          "error_invalid_context",
 			contextName:   "invalidContext",
@@ -121,11 +134,11 @@ func TestLangChainService_AskLlmWithContext(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mockWrapper := &MockLangChainWrapper{}
-			mockWrapper.On("AskWithContext", test.contextName, test.prompt).Return(test.mockError)
+			mockWrapper.On("AskWithContext", test.contextName, test.prompt, test.filters).Return(test.mockError)
 
 			service := NewLangChainService(mockWrapper, nil)
 
-			err := service.AskLlmWithContext(test.contextName, test.prompt)
+			err := service.AskLlmWithContext(test.contextName, test.prompt, test.filters)
 
 			if test.expectedError != nil {
 				assert.EqualError(t, err, test.expectedError.Error())
@@ -141,13 +154,35 @@ func TestLangChainService_LoadSourceCode(t *testing.T) {
 		name          string
 		sourceName    string
 		directory     string
+		options       lang_chain.LoadOptions
+		stats         lang_chain.LoadStats
 		mockError     error
 		expectedError error
+		expectedOut   string
 	}{
 		{
-			name:       "success_valid_directory",
-			sourceName: "exampleSource",
-			directory:  "/valid/path",
+			name:        "success_valid_directory",
+			sourceName:  "exampleSource",
+			directory:   "/valid/path",
+			stats:       lang_chain.LoadStats{Added: 2, Changed: 1, Removed: 1, Unchanged: 5},
+			expectedOut: "Context exampleSource loaded: 2 added, 1 changed, 1 removed, 5 unchanged\n",
+		},
+		{
+			name:        "success_full",
+			sourceName:  "exampleSource",
+			directory:   "/valid/path",
+			options:     lang_chain.LoadOptions{Full: true, Include: []string{"*.go"}},
+			stats:       lang_chain.LoadStats{Added: 8, Skipped: map[string]int{"ignored": 3, "binary": 1}},
+			expectedOut: "Context exampleSource loaded: 8 added, 0 changed, 0 removed, 0 unchanged\nSkipped: 1 binary, 3 ignored\n",
+		},
+		{
+			name:          "error_after_some_files",
+			sourceName:    "exampleSource",
+			directory:     "/valid/path",
+			stats:         lang_chain.LoadStats{Added: 2, Failed: 1, Skipped: map[string]int{"too large": 1}},
+			mockError:     errors.New("error loading /valid/path/main.go: embedding failed"),
+			expectedError: errors.New("error loading /valid/path/main.go: embedding failed"),
+			expectedOut:   "Context exampleSource partly loaded: 2 added, 0 changed, 0 removed, 0 unchanged, 1 failed\nSkipped: 1 too large\n",
 		},
 		{
 			name:          "error_invalid_directory",
@@ -175,17 +210,31 @@ func TestLangChainService_LoadSourceCode(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mockWrapper := &MockLangChainWrapper{}
-			mockWrapper.On("LoadSourceCode", test.sourceName, test.directory).Return(test.mockError)
+			mockWrapper.On("LoadSourceCode", test.sourceName, test.directory, test.options).Return(test.stats, test.mockError)
 
-			service := NewLangChainService(mockWrapper, nil)
+			var out string
+			service := NewLangChainService(mockWrapper, func(chunk []byte) { out += string(chunk) })
 
-			err := service.LoadSourceCode(test.sourceName, test.directory)
+			err := service.LoadSourceCode(test.sourceName, test.directory, test.options)
 
 			if test.expectedError != nil {
 				assert.EqualError(t, err, test.expectedError.Error())
 			} else {
 				assert.NoError(t, err)
 			}
+			assert.Equal(t, test.expectedOut, out)
 		})
 	}
 }
+
+func TestLangChainService_ContextNames(t *testing.T) {
+	mockWrapper := &MockLangChainWrapper{}
+	mockWrapper.On("ContextNames").Return([]string{"tp1", "tp2"}, nil)
+	service := NewLangChainService(mockWrapper, nil)
+
+	names, err := service.ContextNames()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tp1", "tp2"}, names)
+	mockWrapper.AssertExpectations(t)
+}
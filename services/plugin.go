@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"github.com/ffumaneri/github-cli/common"
+)
+
+type IPluginService interface {
+	List() error
+	Run(name string, args []string) error
+}
+
+// PluginEnv returns the environment variables exposing the resolved configuration to plugins.
+type PluginEnv func() ([]string, error)
+
+// PluginRunner runs the executable at path with args and extra environment variables,
+// connected to the standard input and output of the CLI.
+type PluginRunner func(path string, args []string, env []string) error
+
+func NewPluginService(find func() []common.Plugin, look func(name string) (string, error), env PluginEnv, runner PluginRunner, consumer func(data string)) *PluginService {
+	return &PluginService{
+		find:         find,
+		look:         look,
+		env:          env,
+		runner:       runner,
+		consumerFunc: consumer,
+	}
+}
+
+type PluginService struct {
+	find         func() []common.Plugin
+	look         func(name string) (string, error)
+	env          PluginEnv
+	runner       PluginRunner
+	consumerFunc func(data string)
+}
+
+// List prints the plugins found on PATH with the executable that runs each one.
+func (service *PluginService) List() (err error) {
+	plugins := service.find()
+	if len(plugins) == 0 {
+		service.consumerFunc(fmt.Sprintf("No plugins found, add a %s<name> executable to your PATH", common.PluginPrefix))
+		return
+	}
+	for _, plugin := range plugins {
+		service.consumerFunc(fmt.Sprintf("%s\t%s", plugin.Name, plugin.Path))
+	}
+	return
+}
+
+// Run runs the plugin name passing args through.
+func (service *PluginService) Run(name string, args []string) (err error) {
+	path, err := service.look(name)
+	if err != nil {
+		return common.Errorf(common.KindValidation, "unknown command %q: no %s%s plugin on PATH", name, common.PluginPrefix, name)
+	}
+	env, err := service.env()
+	if err != nil {
+		return
+	}
+	return service.runner(path, args, env)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginService_List(t *testing.T) {
+	tests := []struct {
+		name           string
+		plugins        []common.Plugin
+		expectedOutput []string
+	}{
+		{"Plugins", []common.Plugin{{Name: "grades", Path: "/usr/local/bin/github-cli-grades"}}, []string{"grades\t/usr/local/bin/github-cli-grades"}},
+		{"No plugins", nil, []string{"No plugins found, add a github-cli-<name> executable to your PATH"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output []string
+			service := NewPluginService(func() []common.Plugin { return tt.plugins }, nil, nil, nil, func(data string) { output = append(output, data) })
+
+			err := service.List()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, output)
+		})
+	}
+}
+
+func TestPluginService_Run(t *testing.T) {
+	look := func(name string) (string, error) {
+		if name == "grades" {
+			return "/usr/local/bin/github-cli-grades", nil
+		}
+		return "", errors.New("not found")
+	}
+	env := func() ([]string, error) {
+		return []string{"GITHUB_CLI_OWNER=utn"}, nil
+	}
+	var ran []string
+	var ranEnv []string
+	runner := func(path string, args []string, env []string) error {
+		ran = append([]string{path}, args...)
+		ranEnv = env
+		return nil
+	}
+	service := NewPluginService(nil, look, env, runner, nil)
+
+	assert.NoError(t, service.Run("grades", []string{"--course", "k3001"}))
+	assert.Equal(t, []string{"/usr/local/bin/github-cli-grades", "--course", "k3001"}, ran)
+	assert.Equal(t, []string{"GITHUB_CLI_OWNER=utn"}, ranEnv)
+
+	assert.EqualError(t, service.Run("notes", nil), `unknown command "notes": no github-cli-notes plugin on PATH`)
+}
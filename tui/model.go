@@ -0,0 +1,378 @@
+package tui
+
+import (
+	"fmt"
+	github2 "github.com/ffumaneri/github-cli/github"
+	"github.com/ffumaneri/github-cli/services"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type screen int
+
+const (
+	reposScreen screen = iota
+	membersScreen
+)
+
+type dialog int
+
+const (
+	noDialog dialog = iota
+	inviteDialog
+	confirmDialog
+)
+
+// member is a collaborator of the open repository, or a user invited to it when
+// invitation is set.
+type member struct {
+	login      string
+	invitation *github2.Invitation
+}
+
+type reposLoadedMsg struct {
+	repos []string
+	err   error
+}
+
+type membersLoadedMsg struct {
+	repo    string
+	members []member
+	err     error
+}
+
+type actionDoneMsg struct {
+	repo   string
+	status string
+	err    error
+}
+
+// Model is the state of the UI: the repositories, the members of the open one and the
+// dialog shown on top of them.
+type Model struct {
+	service  services.IGithubService
+	messages *Messages
+
+	screen    screen
+	repos     []string
+	filter    string
+	filtering bool
+	repoIndex int
+
+	repo        string
+	members     []member
+	memberIndex int
+
+	dialog  dialog
+	input   string
+	prompt  string
+	confirm func() tea.Cmd
+
+	status  string
+	loading bool
+	height  int
+}
+
+func NewModel(service services.IGithubService, messages *Messages) Model {
+	return Model{service: service, messages: messages, loading: true}
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.loadRepos
+}
+
+func (m Model) loadRepos() tea.Msg {
+	repos, err := m.service.RepoNames()
+	return reposLoadedMsg{repos, err}
+}
+
+func (m Model) loadMembers(repo string) tea.Cmd {
+	return func() tea.Msg {
+		collaborators, err := m.service.CollaboratorNames(repo)
+		if err != nil {
+			return membersLoadedMsg{repo: repo, err: err}
+		}
+		invitations, err := m.service.InvitationsByRepo(repo)
+		if err != nil {
+			return membersLoadedMsg{repo: repo, err: err}
+		}
+		var members []member
+		for _, login := range collaborators {
+			members = append(members, member{login: login})
+		}
+		for i := range invitations {
+			members = append(members, member{login: invitations[i].Login, invitation: &invitations[i]})
+		}
+		return membersLoadedMsg{repo: repo, members: members}
+	}
+}
+
+// run runs action on repo in the background, reporting what the service printed.
+func (m Model) run(repo string, action func() error) tea.Cmd {
+	return func() tea.Msg {
+		err := action()
+		return actionDoneMsg{repo: repo, status: m.messages.Take(), err: err}
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case reposLoadedMsg:
+		m.loading = false
+		m.repos, m.status = msg.repos, errorStatus(msg.err)
+		m.repoIndex = 0
+	case membersLoadedMsg:
+		if msg.repo != m.repo {
+			return m, nil
+		}
+		m.loading, m.members = false, msg.members
+		// Keep the outcome of the action that triggered the reload
+		if msg.err != nil {
+			m.status = errorStatus(msg.err)
+		}
+		m.memberIndex = min(m.memberIndex, max(len(m.members)-1, 0))
+	case actionDoneMsg:
+		m.status = msg.status
+		if msg.err != nil {
+			m.status = errorStatus(msg.err)
+		}
+		// The user may have left the repository while the action ran
+		if m.screen != membersScreen || msg.repo != m.repo {
+			return m, nil
+		}
+		m.loading = true
+		return m, m.loadMembers(m.repo)
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		switch {
+		case m.dialog == inviteDialog:
+			return m.updateInvite(msg)
+		case m.dialog == confirmDialog:
+			return m.updateConfirm(msg)
+		case m.filtering:
+			return m.updateFilter(msg)
+		case m.screen == reposScreen:
+			return m.updateRepos(msg)
+		default:
+			return m.updateMembers(msg)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateRepos(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	repos := m.filteredRepos()
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "up", "k":
+		m.repoIndex = max(m.repoIndex-1, 0)
+	case "down", "j":
+		m.repoIndex = min(m.repoIndex+1, max(len(repos)-1, 0))
+	case "/":
+		m.filtering = true
+	case "esc":
+		m.filter, m.repoIndex = "", 0
+	case "r":
+		m.loading, m.status = true, ""
+		return m, m.loadRepos
+	case "enter":
+		if len(repos) == 0 {
+			return m, nil
+		}
+		m.screen, m.repo = membersScreen, repos[m.repoIndex]
+		m.members, m.memberIndex, m.status, m.loading = nil, 0, "", true
+		return m, m.loadMembers(m.repo)
+	}
+	return m, nil
+}
+
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering, m.filter = false, ""
+	case tea.KeyBackspace:
+		m.filter = dropLastRune(m.filter)
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	}
+	m.repoIndex = 0
+	return m, nil
+}
+
+func (m Model) updateMembers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "esc", "backspace", "left", "h":
+		// The members still loading are dropped when they arrive
+		m.screen, m.repo, m.status, m.loading = reposScreen, "", "", false
+	case "up", "k":
+		m.memberIndex = max(m.memberIndex-1, 0)
+	case "down", "j":
+		m.memberIndex = min(m.memberIndex+1, max(len(m.members)-1, 0))
+	case "r":
+		m.loading, m.status = true, ""
+		return m, m.loadMembers(m.repo)
+	case "i":
+		m.dialog, m.input = inviteDialog, ""
+	case "d":
+		if len(m.members) == 0 {
+			return m, nil
+		}
+		selected, repo := m.members[m.memberIndex], m.repo
+		m.dialog = confirmDialog
+		if selected.invitation != nil {
+			invitation := *selected.invitation
+			m.prompt = fmt.Sprintf("Cancel the invitation of %s to %s?", selected.login, repo)
+			m.confirm = func() tea.Cmd {
+				return m.run(repo, func() error { return m.service.CancelInvitationToRepo(repo, invitation) })
+			}
+		} else {
+			m.prompt = fmt.Sprintf("Remove %s from %s?", selected.login, repo)
+			m.confirm = func() tea.Cmd {
+				return m.run(repo, func() error { return m.service.RemoveCollaboratorFromRepo(repo, selected.login) })
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateInvite(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.dialog = noDialog
+	case tea.KeyBackspace:
+		m.input = dropLastRune(m.input)
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	case tea.KeyEnter:
+		user, repo := strings.TrimSpace(m.input), m.repo
+		if user == "" {
+			return m, nil
+		}
+		m.dialog = confirmDialog
+		m.prompt = fmt.Sprintf("Invite %s to %s?", user, repo)
+		m.confirm = func() tea.Cmd {
+			return m.run(repo, func() error { return m.service.InviteCollaboratorToRepo(repo, user) })
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		cmd := m.confirm()
+		m.dialog, m.confirm, m.status, m.loading = noDialog, nil, "", true
+		return m, cmd
+	case "n", "N", "esc":
+		m.dialog, m.confirm = noDialog, nil
+	}
+	return m, nil
+}
+
+// filteredRepos returns the repositories containing the filter, ignoring case.
+func (m Model) filteredRepos() []string {
+	if m.filter == "" {
+		return m.repos
+	}
+	var repos []string
+	for _, repo := range m.repos {
+		if strings.Contains(strings.ToLower(repo), strings.ToLower(m.filter)) {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+func (m Model) View() string {
+	var b strings.Builder
+	var help string
+	if m.screen == reposScreen {
+		b.WriteString("Repositories")
+		if m.filter != "" || m.filtering {
+			b.WriteString(fmt.Sprintf("  filter: %s", m.filter))
+			if m.filtering {
+				b.WriteString("_")
+			}
+		}
+		b.WriteString("\n\n")
+		labels := m.filteredRepos()
+		m.writeList(&b, labels, m.repoIndex, "No repositories")
+		help = "↑/↓ move • enter collaborators • / filter • r reload • q quit"
+	} else {
+		b.WriteString(fmt.Sprintf("Collaborators of %s\n\n", m.repo))
+		labels := make([]string, len(m.members))
+		for i, member := range m.members {
+			labels[i] = member.login
+			if member.invitation != nil {
+				labels[i] += " (invitation pending)"
+			}
+		}
+		m.writeList(&b, labels, m.memberIndex, "No collaborators")
+		help = "↑/↓ move • i invite • d remove • r reload • esc back • q quit"
+	}
+	b.WriteString("\n")
+	switch m.dialog {
+	case inviteDialog:
+		b.WriteString(fmt.Sprintf("Invite to %s: %s_\n", m.repo, m.input))
+		help = "enter confirm • esc cancel"
+	case confirmDialog:
+		b.WriteString(m.prompt + " (y/n)\n")
+		help = "y confirm • n cancel"
+	default:
+		if m.loading {
+			b.WriteString("Loading...\n")
+		} else if m.status != "" {
+			b.WriteString(m.status + "\n")
+		}
+	}
+	b.WriteString(help)
+	return b.String()
+}
+
+// writeList writes the labels around the selected one that fit in the window.
+func (m Model) writeList(b *strings.Builder, labels []string, selected int, empty string) {
+	if len(labels) == 0 {
+		if !m.loading {
+			b.WriteString("  " + empty + "\n")
+		}
+		return
+	}
+	// Title, blank lines, status and help take 5 lines
+	visible := len(labels)
+	if m.height > 5 {
+		visible = min(visible, m.height-5)
+	}
+	start := min(max(selected-visible/2, 0), len(labels)-visible)
+	for i := start; i < start+visible; i++ {
+		cursor := "  "
+		if i == selected {
+			cursor = "> "
+		}
+		b.WriteString(cursor + labels[i] + "\n")
+	}
+}
+
+func errorStatus(err error) string {
+	if err == nil {
+		return ""
+	}
+	return "Error: " + err.Error()
+}
+
+func dropLastRune(text string) string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return text
+	}
+	return string(runes[:len(runes)-1])
+}
@@ -0,0 +1,255 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	github2 "github.com/ffumaneri/github-cli/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockGithubService is a mock implementation of the IGithubService
+type MockGithubService struct {
+	mock.Mock
+}
+
+func (m *MockGithubService) ListRepos() error {
+	return m.Called().Error(0)
+}
+
+func (m *MockGithubService) ListCollaboratorsByRepo(repo string) error {
+	return m.Called(repo).Error(0)
+}
+
+func (m *MockGithubService) InviteCollaboratorToRepo(repo, user string) error {
+	return m.Called(repo, user).Error(0)
+}
+
+func (m *MockGithubService) RemoveCollaboratorFromRepo(repo, user string) error {
+	return m.Called(repo, user).Error(0)
+}
+
+func (m *MockGithubService) InvitationsByRepo(repo string) ([]github2.Invitation, error) {
+	args := m.Called(repo)
+	return args.Get(0).([]github2.Invitation), args.Error(1)
+}
+
+func (m *MockGithubService) CancelInvitationToRepo(repo string, invitation github2.Invitation) error {
+	return m.Called(repo, invitation).Error(0)
+}
+
+func (m *MockGithubService) RepoNames() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGithubService) CollaboratorNames(repo string) ([]string, error) {
+	args := m.Called(repo)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGithubService) CreateRepo(repo, description string, private bool) error {
+	args := m.Called(repo, description, private)
+	return args.Error(0)
+}
+
+func (m *MockGithubService) PushFile(repo, path string, file github2.FileChange) error {
+	args := m.Called(repo, path, file)
+	return args.Error(0)
+}
+
+func (m *MockGithubService) SaveLabel(repo string, label github2.Label) error {
+	args := m.Called(repo, label)
+	return args.Error(0)
+}
+
+func keys(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+// send updates the model with msg and the messages of the commands it returns, as the
+// program would.
+func send(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	updated, cmd := m.Update(msg)
+	m = updated.(Model)
+	for cmd != nil {
+		result := cmd()
+		if _, quit := result.(tea.QuitMsg); quit || result == nil {
+			return m
+		}
+		updated, cmd = m.Update(result)
+		m = updated.(Model)
+	}
+	return m
+}
+
+func newTestModel(t *testing.T, service *MockGithubService) Model {
+	messages := NewMessages()
+	m := NewModel(service, messages)
+	return send(t, m, m.Init()())
+}
+
+func TestModel_Repos(t *testing.T) {
+	service := new(MockGithubService)
+	service.On("RepoNames").Return([]string{"api", "web", "web-admin"}, nil)
+	m := newTestModel(t, service)
+
+	assert.Equal(t, []string{"api", "web", "web-admin"}, m.filteredRepos())
+	assert.Contains(t, m.View(), "> api")
+
+	m = send(t, m, keys("/"))
+	m = send(t, m, keys("WEB"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, m.filtering)
+	assert.Equal(t, []string{"web", "web-admin"}, m.filteredRepos())
+
+	m = send(t, m, keys("j"))
+	m = send(t, m, keys("j"))
+	assert.Equal(t, 1, m.repoIndex)
+	assert.Contains(t, m.View(), "> web-admin")
+
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, "", m.filter)
+	assert.Len(t, m.filteredRepos(), 3)
+
+	_, cmd := m.Update(keys("q"))
+	assert.Equal(t, tea.QuitMsg{}, cmd())
+}
+
+func TestModel_ReposError(t *testing.T) {
+	service := new(MockGithubService)
+	service.On("RepoNames").Return([]string(nil), errors.New("boom"))
+	m := newTestModel(t, service)
+
+	assert.Contains(t, m.View(), "Error: boom")
+}
+
+func TestModel_Members(t *testing.T) {
+	invitation := github2.Invitation{Id: 7, Login: "carol"}
+	service := new(MockGithubService)
+	service.On("RepoNames").Return([]string{"api"}, nil)
+	service.On("CollaboratorNames", "api").Return([]string{"alice", "bob"}, nil)
+	service.On("InvitationsByRepo", "api").Return([]github2.Invitation{invitation}, nil)
+	service.On("RemoveCollaboratorFromRepo", "api", "bob").Return(nil)
+	service.On("CancelInvitationToRepo", "api", invitation).Return(nil)
+	service.On("InviteCollaboratorToRepo", "api", "dave").Return(nil)
+	m := newTestModel(t, service)
+
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, membersScreen, m.screen)
+	assert.Equal(t, "api", m.repo)
+	view := m.View()
+	assert.Contains(t, view, "> alice")
+	assert.Contains(t, view, "carol (invitation pending)")
+
+	// Declining the confirmation does nothing
+	m = send(t, m, keys("j"))
+	m = send(t, m, keys("d"))
+	assert.Contains(t, m.View(), "Remove bob from api? (y/n)")
+	m = send(t, m, keys("n"))
+	service.AssertNotCalled(t, "RemoveCollaboratorFromRepo", "api", "bob")
+
+	m = send(t, m, keys("d"))
+	m = send(t, m, keys("y"))
+	service.AssertCalled(t, "RemoveCollaboratorFromRepo", "api", "bob")
+
+	m = send(t, m, keys("j"))
+	m = send(t, m, keys("d"))
+	assert.Contains(t, m.View(), "Cancel the invitation of carol to api? (y/n)")
+	m = send(t, m, keys("y"))
+	service.AssertCalled(t, "CancelInvitationToRepo", "api", invitation)
+
+	m = send(t, m, keys("i"))
+	m = send(t, m, keys("dave"))
+	assert.Contains(t, m.View(), "Invite to api: dave_")
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, m.View(), "Invite dave to api? (y/n)")
+	m = send(t, m, keys("y"))
+	service.AssertCalled(t, "InviteCollaboratorToRepo", "api", "dave")
+
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, reposScreen, m.screen)
+}
+
+func TestModel_BackWhileLoading(t *testing.T) {
+	service := new(MockGithubService)
+	service.On("RepoNames").Return([]string{"api"}, nil)
+	service.On("CollaboratorNames", "api").Return([]string{"alice"}, nil)
+	service.On("InvitationsByRepo", "api").Return([]github2.Invitation{}, nil)
+	m := newTestModel(t, service)
+
+	// The members are not delivered before going back
+	updated, load := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Contains(t, m.View(), "Loading...")
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m = send(t, m, load())
+
+	assert.Equal(t, reposScreen, m.screen)
+	assert.NotContains(t, m.View(), "Loading...")
+	assert.Contains(t, m.View(), "> api")
+}
+
+func TestModel_ActionStatus(t *testing.T) {
+	service := new(MockGithubService)
+	service.On("RepoNames").Return([]string{"api"}, nil)
+	service.On("CollaboratorNames", "api").Return([]string{"alice"}, nil)
+	service.On("InvitationsByRepo", "api").Return([]github2.Invitation{}, nil)
+	m := newTestModel(t, service)
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	m.messages.Add("Collaborator alice removed from api\n")
+	m = send(t, m, m.run("api", func() error { return nil })())
+	assert.Contains(t, m.View(), "Collaborator alice removed from api")
+
+	m = send(t, m, m.run("api", func() error { return errors.New("forbidden") })())
+	assert.Contains(t, m.View(), "Error: forbidden")
+}
+
+func TestModel_ActionDoneAfterLeaving(t *testing.T) {
+	service := new(MockGithubService)
+	service.On("RepoNames").Return([]string{"api", "web"}, nil)
+	service.On("CollaboratorNames", "api").Return([]string{"alice", "bob"}, nil)
+	service.On("InvitationsByRepo", "api").Return([]github2.Invitation{}, nil)
+	service.On("CollaboratorNames", "web").Return([]string{"carol"}, nil)
+	service.On("InvitationsByRepo", "web").Return([]github2.Invitation{}, nil)
+	service.On("RemoveCollaboratorFromRepo", "api", "bob").Return(nil)
+	m := newTestModel(t, service)
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// The removal finishes once the user is back in the repository list
+	m = send(t, m, keys("j"))
+	m = send(t, m, keys("d"))
+	updated, remove := m.Update(keys("y"))
+	m = updated.(Model)
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m.messages.Add("Collaborator bob removed from api")
+	done := remove()
+	m = send(t, m, done)
+	assert.Equal(t, reposScreen, m.screen)
+	assert.NotContains(t, m.View(), "Loading...")
+	assert.Contains(t, m.View(), "Collaborator bob removed from api")
+	service.AssertNotCalled(t, "CollaboratorNames", "")
+
+	// Or in another repository, whose members are not replaced
+	m = send(t, m, keys("j"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "web", m.repo)
+	m = send(t, m, done)
+	assert.Contains(t, m.View(), "> carol")
+	assert.NotContains(t, m.View(), "alice")
+	assert.NotContains(t, m.View(), "Loading...")
+	service.AssertNumberOfCalls(t, "CollaboratorNames", 2)
+}
+
+func TestMessages(t *testing.T) {
+	messages := NewMessages()
+	messages.Add("first\n")
+	messages.Add("second")
+
+	assert.Equal(t, "first second", messages.Take())
+	assert.Equal(t, "", messages.Take())
+}
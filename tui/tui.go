@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"github.com/ffumaneri/github-cli/common"
+	"github.com/ffumaneri/github-cli/services"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Messages collects what the services print while the UI owns the terminal, the UI
+// shows them in its status line.
+type Messages struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func NewMessages() *Messages {
+	return &Messages{}
+}
+
+// Add is the consumer given to the services.
+func (m *Messages) Add(data string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lines = append(m.lines, strings.TrimSpace(data))
+}
+
+// Take returns the messages added since the last call.
+func (m *Messages) Take() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	text := strings.Join(m.lines, " ")
+	m.lines = nil
+	return text
+}
+
+// Run shows the repositories of the owner until the user quits. Logs would draw over the
+// UI, so they go to TuiLogFileName in the cache directory meanwhile, or nowhere when it
+// cannot be written.
+func Run(service services.IGithubService, messages *Messages) error {
+	logs := io.Discard
+	if dir, err := common.CacheDir(); err == nil && os.MkdirAll(dir, 0700) == nil {
+		if file, err := os.OpenFile(filepath.Join(dir, common.TuiLogFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600); err == nil {
+			defer file.Close()
+			logs = file
+		}
+	}
+	restore, err := common.RedirectLogging(logs, "tui")
+	if err != nil {
+		return err
+	}
+	defer restore()
+	_, err = tea.NewProgram(NewModel(service, messages), tea.WithAltScreen()).Run()
+	return err
+}